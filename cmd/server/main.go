@@ -20,14 +20,19 @@ package main
 // @name Authorization
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
+	"time"
 
 	"ims/internal/config"
+	"ims/internal/domain"
 	"ims/internal/repository"
 	"ims/internal/repository/postgres"
 	redisRepo "ims/internal/repository/redis"
@@ -50,6 +55,9 @@ var (
 func main() {
 	// Parse command line flags
 	var showVersion = flag.Bool("version", false, "Show version information")
+	var verifyAudit = flag.Bool("verify-audit", false, "Verify the audit log hash chain and exit")
+	var verifyFrom = flag.String("verify-from", "", "Verify audit entries created from this time (RFC3339)")
+	var verifyTo = flag.String("verify-to", "", "Verify audit entries created up to this time (RFC3339)")
 	flag.Parse()
 
 	if *showVersion {
@@ -66,7 +74,7 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	log.Printf("Starting IMS (Insider Message Sender) v%s on port %s", version, cfg.Server.Port)
+	log.Printf("Starting IMS (Insider Message Sender) v%s on port %s as instance %s", version, cfg.Server.Port, cfg.Server.InstanceID)
 
 	// Initialize database
 	sqlDB, err := postgres.NewDB(cfg.Database.URL, cfg.Database.MaxConnections, cfg.Database.MaxIdleConnections)
@@ -99,13 +107,67 @@ func main() {
 	// Initialize repositories
 	messageRepo := postgres.NewMessageRepository(sqlDB)
 	auditRepo := postgres.NewAuditRepository(db)
+	if cfg.Audit.HashChain {
+		if cfg.Audit.CheckpointKey == "" {
+			log.Fatal("AUDIT_CHECKPOINT_KEY is required when AUDIT_HASH_CHAIN is enabled")
+		}
+		auditRepo = postgres.NewHashChainedAuditRepository(db, []byte(cfg.Audit.CheckpointKey))
+	}
+
+	retentionPolicy, err := domain.ParseAuditRetentionPolicy(cfg.Audit.Retention)
+	if err != nil {
+		log.Fatalf("Invalid AUDIT_RETENTION: %v", err)
+	}
+
+	if *verifyAudit {
+		code := runAuditVerification(postgres.NewHashChainedAuditRepository(db, []byte(cfg.Audit.CheckpointKey)), *verifyFrom, *verifyTo)
+		_ = sqlDB.Close()
+		os.Exit(code)
+	}
+
 	var cacheRepo repository.CacheRepository
 	if redisClient != nil {
 		cacheRepo = redisRepo.NewCacheRepository(redisClient)
 	}
 
-	// Initialize audit service
-	auditService := service.NewAuditService(auditRepo)
+	// Spool audit entries to disk while Postgres cannot take them
+	var auditSpool *service.AuditSpool
+	if cfg.Audit.SpoolPath != "" {
+		auditSpool, err = service.NewAuditSpool(auditRepo, cfg.Audit.SpoolPath, cfg.Audit.SpoolReplayInterval)
+		if err != nil {
+			log.Printf("Failed to open audit spool, continuing without it: %v", err)
+		} else {
+			auditSpool.Start()
+			auditRepo = auditSpool
+		}
+	}
+
+	// Copy audit entries to the enabled sinks; behind the spool so replays are not sent twice
+	auditSinks, err := newAuditSinks(auditRepo, &cfg.Audit)
+	if err != nil {
+		log.Fatalf("Failed to open audit sinks: %v", err)
+	}
+	if auditSinks != nil {
+		auditSinks.Start()
+		auditRepo = auditSinks
+	}
+
+	// Initialize audit service, writing through a buffered queue unless disabled
+	var auditService service.AuditService
+	var auditWriter *service.AuditWriter
+	if cfg.Audit.Async {
+		auditWriter = service.NewAuditWriter(auditRepo, service.AuditWriterConfig{
+			QueueSize:      cfg.Audit.QueueSize,
+			FlushSize:      cfg.Audit.FlushSize,
+			FlushInterval:  cfg.Audit.FlushInterval,
+			OverflowPolicy: cfg.Audit.OverflowPolicy,
+			EnqueueTimeout: cfg.Audit.EnqueueTimeout,
+		})
+		auditWriter.Start()
+		auditService = service.NewAuditService(auditWriter)
+	} else {
+		auditService = service.NewAuditService(auditRepo)
+	}
 
 	// Initialize webhook client
 	webhookClient := service.NewWebhookClient(
@@ -114,6 +176,7 @@ func main() {
 		cfg.Webhook.Timeout,
 		cfg.Webhook.MaxRetries,
 	)
+	webhookClient.SetAuditService(auditService)
 
 	// Initialize message service
 	messageService := service.NewMessageService(
@@ -122,28 +185,128 @@ func main() {
 		webhookClient,
 		cfg.Message.MaxLength,
 	)
+	messageService.SetInstanceID(cfg.Server.InstanceID)
+	messageService.SetAuditService(auditService)
+	messageService.SetIdempotencyWindow(cfg.Message.IdempotencyWindow)
+	messageService.SetConcurrency(cfg.Message.SendConcurrency)
+	messageService.SetRetryPolicy(service.RetryPolicy{
+		MaxAttempts: cfg.Message.MaxAttempts,
+		BaseDelay:   cfg.Message.RetryBaseDelay,
+		MaxDelay:    cfg.Message.RetryMaxDelay,
+	})
 
 	// Initialize scheduler with audit service
-	scheduler := scheduler.NewScheduler(
+	messageScheduler := scheduler.NewScheduler(
 		messageService,
 		auditService,
 		cfg.Scheduler.Interval,
 		cfg.Scheduler.BatchSize,
 	)
+	messageScheduler.SetSendingLease(cfg.Scheduler.SendingLease)
+	messageScheduler.SetInstanceID(cfg.Server.InstanceID)
+	messageScheduler.SetBatchResultRepository(postgres.NewBatchResultRepository(sqlDB))
+	if err := messageScheduler.ValidateSettings(cfg.Scheduler.Interval, cfg.Scheduler.BatchSize); err != nil {
+		log.Fatalf("Invalid scheduler settings: %v", err)
+	}
+
+	// Elect a single scheduler leader across replicas
+	var leaseRepo repository.LeaseRepository
+	if cfg.Scheduler.LeaderElection {
+		if cfg.Scheduler.LeaderBackend == "redis" && redisClient != nil {
+			leaseRepo = redisRepo.NewLeaseRepository(redisClient)
+			log.Println("Using Redis for scheduler leader election")
+		} else {
+			if cfg.Scheduler.LeaderBackend == "redis" {
+				log.Println("Redis is not available, falling back to PostgreSQL for scheduler leader election")
+			}
+			leaseRepo = postgres.NewLeaseRepository(sqlDB)
+		}
+		messageScheduler.SetLeaderElector(scheduler.NewLeaderElector(
+			leaseRepo,
+			cfg.Server.InstanceID,
+			cfg.Scheduler.LeaderLeaseTTL,
+		))
+	}
+
+	// Restore the persisted scheduler state and keep this replica converged on it
+	stateSync := scheduler.NewStateSync(
+		messageScheduler,
+		postgres.NewSchedulerStateRepository(sqlDB),
+		cfg.Scheduler.StateSyncInterval,
+	)
+	messageScheduler.SetStateSync(stateSync)
+	if err := stateSync.Restore(context.Background(), cfg.Scheduler.Autostart, "autostart@"+cfg.Server.InstanceID); err != nil {
+		log.Printf("Failed to restore scheduler state: %v", err)
+	}
+	stateSync.Start()
+
+	// Initialize reaper for messages stuck in the sending state
+	reaper := scheduler.NewReaper(
+		messageService,
+		auditService,
+		cfg.Scheduler.SendingLease,
+		cfg.Scheduler.ReaperInterval,
+	)
+	reaper.Start()
+
+	// Create monthly audit log partitions ahead of time
+	partitionManager := scheduler.NewPartitionManager(
+		postgres.NewAuditPartitionRepository(db),
+		cfg.Audit.PartitionsAhead,
+		cfg.Audit.PartitionInterval,
+	)
+	partitionManager.Start()
+
+	// Enforce the audit retention policy, if one is configured
+	var retentionPruner *scheduler.RetentionPruner
+	if retentionPolicy.Enabled() {
+		retentionPruner = scheduler.NewRetentionPruner(
+			auditService,
+			retentionPolicy,
+			cfg.Audit.RetentionInterval,
+			cfg.Audit.RetentionBatchSize,
+		)
+		// Replicas take turns rather than pruning the same batches at once
+		if leaseRepo != nil {
+			retentionPruner.SetLeaderElector(scheduler.NewNamedLeaderElector(
+				leaseRepo,
+				scheduler.RetentionLeaseName,
+				cfg.Server.InstanceID,
+				cfg.Scheduler.LeaderLeaseTTL,
+			))
+		}
+		retentionPruner.Start()
+	}
+
+	// Push audit logs stored by any replica to live stream subscribers
+	var auditStream *service.AuditStream
+	if listener, err := postgres.NewAuditLogListener(cfg.Database.URL); err != nil {
+		log.Printf("Failed to listen for audit logs (continuing without live audit stream): %v", err)
+	} else {
+		auditStream = service.NewAuditStream(auditRepo, listener)
+		auditStream.Start()
+	}
 
 	// Initialize server with audit service
-	srv := server.NewServer(cfg, sqlDB, redisClient, messageService, scheduler, auditService)
+	srv := server.NewServer(cfg, sqlDB, redisClient, messageService, messageScheduler, auditService, auditWriter, auditSpool, auditSinks, auditStream)
 
-	// Graceful shutdown handling
+	// Graceful shutdown handling; main returns only once shutdown has drained the audit
+	// queue and sinks, since the server stops listening as soon as shutdown begins
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	shutdownDone := make(chan struct{})
 	go func() {
-		<-c
-		log.Println("Received interrupt signal, shutting down gracefully...")
+		defer close(shutdownDone)
+		sig := <-c
+		log.Printf("Received %v, shutting down gracefully...", sig)
+		reaper.Stop()
+		if retentionPruner != nil {
+			retentionPruner.Stop()
+		}
+		partitionManager.Stop()
 		if err := srv.Shutdown(); err != nil {
 			log.Printf("Error during shutdown: %v", err)
 		}
-		os.Exit(0)
 	}()
 
 	// Start server
@@ -152,4 +315,93 @@ func main() {
 		log.Printf("Server failed to start: %v", err)
 		os.Exit(1)
 	}
+
+	<-shutdownDone
+	log.Println("Shutdown complete")
+}
+
+// newAuditSinks wraps auditRepo with the sinks enabled in cfg, or returns nil when none are
+func newAuditSinks(auditRepo repository.AuditRepository, cfg *config.AuditConfig) (*service.AuditSinks, error) {
+	sinks := service.NewAuditSinks(auditRepo)
+
+	if cfg.SinkFileEnabled {
+		sink, err := service.NewFileAuditSink(cfg.SinkFilePath, int64(cfg.SinkFileMaxSizeMB)<<20, cfg.SinkFileMaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		sinks.Add(sink, auditSinkConfig(cfg.SinkQueueSize, cfg.SinkFileEventTypes))
+	}
+
+	if cfg.SinkSyslogEnabled {
+		sink, err := service.NewSyslogAuditSink(cfg.SinkSyslogNetwork, cfg.SinkSyslogAddress, cfg.SinkSyslogTag)
+		if err != nil {
+			return nil, err
+		}
+		sinks.Add(sink, auditSinkConfig(cfg.SinkQueueSize, cfg.SinkSyslogEventTypes))
+	}
+
+	if cfg.SinkHTTPEnabled {
+		if cfg.SinkHTTPURL == "" {
+			return nil, fmt.Errorf("AUDIT_SINK_HTTP_URL is required when AUDIT_SINK_HTTP_ENABLED is set")
+		}
+		sink := service.NewHTTPAuditSink(cfg.SinkHTTPURL, cfg.SinkHTTPAuthorization, cfg.SinkHTTPTimeout)
+		sinks.Add(sink, auditSinkConfig(cfg.SinkQueueSize, cfg.SinkHTTPEventTypes))
+	}
+
+	if sinks.Len() == 0 {
+		return nil, nil
+	}
+	return sinks, nil
+}
+
+func auditSinkConfig(queueSize int, eventTypes []string) service.AuditSinkConfig {
+	sinkConfig := service.AuditSinkConfig{QueueSize: queueSize}
+	for _, eventType := range eventTypes {
+		sinkConfig.EventTypes = append(sinkConfig.EventTypes, domain.AuditEventType(eventType))
+	}
+	return sinkConfig
+}
+
+// runAuditVerification verifies the audit hash chain over the given range, prints the
+// result as JSON and returns the process exit code
+func runAuditVerification(auditRepo repository.AuditRepository, fromStr, toStr string) int {
+	from, err := parseVerifyBound(fromStr)
+	if err != nil {
+		log.Printf("Invalid -verify-from: %v", err)
+		return 2
+	}
+	to, err := parseVerifyBound(toStr)
+	if err != nil {
+		log.Printf("Invalid -verify-to: %v", err)
+		return 2
+	}
+
+	verification, err := service.NewAuditService(auditRepo).VerifyAuditChain(context.Background(), from, to)
+	if err != nil {
+		log.Printf("Failed to verify audit chain: %v", err)
+		return 2
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(verification); err != nil {
+		log.Printf("Failed to print verification result: %v", err)
+		return 2
+	}
+
+	if !verification.Valid {
+		return 1
+	}
+	return 0
+}
+
+func parseVerifyBound(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
 }
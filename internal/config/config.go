@@ -3,6 +3,8 @@
 package config
 
 import (
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
@@ -16,9 +18,12 @@ type Config struct {
 	Scheduler SchedulerConfig
 	Log       LogConfig
 	Message   MessageConfig
+	Audit     AuditConfig
 }
 
 type ServerConfig struct {
+	// InstanceID identifies this replica; it defaults to the hostname and process ID
+	InstanceID   string        `envconfig:"IMS_INSTANCE_ID"`
 	Port         string        `envconfig:"SERVER_PORT" default:"8080"`
 	ReadTimeout  time.Duration `envconfig:"SERVER_READ_TIMEOUT" default:"15s"`
 	WriteTimeout time.Duration `envconfig:"SERVER_WRITE_TIMEOUT" default:"15s"`
@@ -43,8 +48,20 @@ type WebhookConfig struct {
 }
 
 type SchedulerConfig struct {
-	Interval  time.Duration `envconfig:"SCHEDULER_INTERVAL" default:"2m"`
-	BatchSize int           `envconfig:"SCHEDULER_BATCH_SIZE" default:"2"`
+	Interval       time.Duration `envconfig:"SCHEDULER_INTERVAL" default:"2m"`
+	BatchSize      int           `envconfig:"SCHEDULER_BATCH_SIZE" default:"2"`
+	SendingLease   time.Duration `envconfig:"SCHEDULER_SENDING_LEASE" default:"5m"`
+	ReaperInterval time.Duration `envconfig:"SCHEDULER_REAPER_INTERVAL" default:"1m"`
+
+	// Autostart decides whether the scheduler runs on first boot, before any start or stop
+	// has been stored; afterwards every replica follows the stored state
+	Autostart         bool          `envconfig:"IMS_SCHEDULER_AUTOSTART" default:"false"`
+	StateSyncInterval time.Duration `envconfig:"SCHEDULER_STATE_SYNC_INTERVAL" default:"15s"`
+
+	// Leader election across replicas; the backend is "postgres" or "redis" (requires REDIS_URL)
+	LeaderElection bool          `envconfig:"SCHEDULER_LEADER_ELECTION" default:"true"`
+	LeaderBackend  string        `envconfig:"SCHEDULER_LEADER_BACKEND" default:"postgres"`
+	LeaderLeaseTTL time.Duration `envconfig:"SCHEDULER_LEADER_LEASE_TTL" default:"30s"`
 }
 
 type LogConfig struct {
@@ -53,11 +70,104 @@ type LogConfig struct {
 }
 
 type MessageConfig struct {
-	MaxLength int `envconfig:"MESSAGE_MAX_LENGTH" default:"160"`
+	MaxLength         int           `envconfig:"MESSAGE_MAX_LENGTH" default:"160"`
+	IdempotencyWindow time.Duration `envconfig:"MESSAGE_IDEMPOTENCY_WINDOW" default:"24h"`
+	SendConcurrency   int           `envconfig:"MESSAGE_SEND_CONCURRENCY" default:"4"`
+	MaxAttempts       int           `envconfig:"MESSAGE_MAX_ATTEMPTS" default:"5"`
+	RetryBaseDelay    time.Duration `envconfig:"MESSAGE_RETRY_BASE_DELAY" default:"30s"`
+	RetryMaxDelay     time.Duration `envconfig:"MESSAGE_RETRY_MAX_DELAY" default:"30m"`
+}
+
+type AuditConfig struct {
+	// Async queues audit entries in memory and writes them in batches
+	Async          bool          `envconfig:"AUDIT_ASYNC" default:"true"`
+	QueueSize      int           `envconfig:"AUDIT_QUEUE_SIZE" default:"10000"`
+	FlushSize      int           `envconfig:"AUDIT_FLUSH_SIZE" default:"100"`
+	FlushInterval  time.Duration `envconfig:"AUDIT_FLUSH_INTERVAL" default:"1s"`
+	OverflowPolicy string        `envconfig:"AUDIT_OVERFLOW_POLICY" default:"block"`
+	EnqueueTimeout time.Duration `envconfig:"AUDIT_ENQUEUE_TIMEOUT" default:"100ms"`
+
+	// SpoolPath is where entries that could not be written are kept until the database
+	// is back; the spool is off unless it is set
+	SpoolPath           string        `envconfig:"AUDIT_SPOOL_PATH"`
+	SpoolReplayInterval time.Duration `envconfig:"AUDIT_SPOOL_REPLAY_INTERVAL" default:"30s"`
+
+	// HashChain links every stored entry to the previous one; CheckpointKey signs the
+	// checkpoints cleanup records and is required when the chain is enabled
+	HashChain     bool   `envconfig:"AUDIT_HASH_CHAIN" default:"false"`
+	CheckpointKey string `envconfig:"AUDIT_CHECKPOINT_KEY"`
+
+	// Retention lists how long each event type is kept, e.g. "api_request=7d,*=90d";
+	// empty keeps every entry
+	Retention          string        `envconfig:"AUDIT_RETENTION"`
+	RetentionInterval  time.Duration `envconfig:"AUDIT_RETENTION_INTERVAL" default:"1h"`
+	RetentionBatchSize int           `envconfig:"AUDIT_RETENTION_BATCH_SIZE" default:"5000"`
+
+	// PartitionsAhead is how many months of partitions are kept created past the current one
+	PartitionsAhead   int           `envconfig:"AUDIT_PARTITIONS_AHEAD" default:"3"`
+	PartitionInterval time.Duration `envconfig:"AUDIT_PARTITION_INTERVAL" default:"6h"`
+
+	// StreamKeepalive is how long the live audit stream stays idle before a keepalive is sent
+	StreamKeepalive time.Duration `envconfig:"AUDIT_STREAM_KEEPALIVE" default:"15s"`
+
+	// Sinks copy entries somewhere besides the database; each is enabled on its own and
+	// its event types, when set, limit what it receives
+	SinkQueueSize int `envconfig:"AUDIT_SINK_QUEUE_SIZE" default:"10000"`
+
+	SinkFileEnabled    bool     `envconfig:"AUDIT_SINK_FILE_ENABLED" default:"false"`
+	SinkFilePath       string   `envconfig:"AUDIT_SINK_FILE_PATH" default:"data/audit.jsonl"`
+	SinkFileMaxSizeMB  int      `envconfig:"AUDIT_SINK_FILE_MAX_SIZE_MB" default:"100"`
+	SinkFileMaxBackups int      `envconfig:"AUDIT_SINK_FILE_MAX_BACKUPS" default:"5"`
+	SinkFileEventTypes []string `envconfig:"AUDIT_SINK_FILE_EVENT_TYPES"`
+
+	SinkSyslogEnabled    bool     `envconfig:"AUDIT_SINK_SYSLOG_ENABLED" default:"false"`
+	SinkSyslogNetwork    string   `envconfig:"AUDIT_SINK_SYSLOG_NETWORK" default:"udp"`
+	SinkSyslogAddress    string   `envconfig:"AUDIT_SINK_SYSLOG_ADDRESS" default:"localhost:514"`
+	SinkSyslogTag        string   `envconfig:"AUDIT_SINK_SYSLOG_TAG" default:"ims"`
+	SinkSyslogEventTypes []string `envconfig:"AUDIT_SINK_SYSLOG_EVENT_TYPES"`
+
+	SinkHTTPEnabled       bool          `envconfig:"AUDIT_SINK_HTTP_ENABLED" default:"false"`
+	SinkHTTPURL           string        `envconfig:"AUDIT_SINK_HTTP_URL"`
+	SinkHTTPAuthorization string        `envconfig:"AUDIT_SINK_HTTP_AUTHORIZATION"`
+	SinkHTTPTimeout       time.Duration `envconfig:"AUDIT_SINK_HTTP_TIMEOUT" default:"10s"`
+	SinkHTTPEventTypes    []string      `envconfig:"AUDIT_SINK_HTTP_EVENT_TYPES"`
 }
 
 func Load() (*Config, error) {
 	var cfg Config
 	err := envconfig.Process("", &cfg)
-	return &cfg, err
+	if err != nil {
+		return &cfg, err
+	}
+	if cfg.Server.InstanceID == "" {
+		cfg.Server.InstanceID = defaultInstanceID()
+	}
+	return &cfg, cfg.Validate()
+}
+
+// Validate rejects settings that would make a background job spin or panic
+func (c *Config) Validate() error {
+	if c.Audit.SpoolPath != "" && c.Audit.SpoolReplayInterval <= 0 {
+		return fmt.Errorf("AUDIT_SPOOL_REPLAY_INTERVAL must be positive, got %v", c.Audit.SpoolReplayInterval)
+	}
+	if c.Audit.Retention != "" {
+		if c.Audit.RetentionInterval <= 0 {
+			return fmt.Errorf("AUDIT_RETENTION_INTERVAL must be positive, got %v", c.Audit.RetentionInterval)
+		}
+		if c.Audit.RetentionBatchSize <= 0 {
+			return fmt.Errorf("AUDIT_RETENTION_BATCH_SIZE must be positive, got %d", c.Audit.RetentionBatchSize)
+		}
+	}
+	if c.Audit.PartitionInterval <= 0 {
+		return fmt.Errorf("AUDIT_PARTITION_INTERVAL must be positive, got %v", c.Audit.PartitionInterval)
+	}
+	return nil
+}
+
+func defaultInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "ims"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
 }
@@ -3,7 +3,13 @@
 package domain
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,6 +28,14 @@ const (
 	EventAPIRequest       AuditEventType = "api_request"
 	EventWebhookRequest   AuditEventType = "webhook_request"
 	EventWebhookResponse  AuditEventType = "webhook_response"
+	EventMessageRecovered AuditEventType = "message_recovered"
+
+	EventSchedulerReconfigured AuditEventType = "scheduler_reconfigured"
+
+	EventMessageCreated AuditEventType = "message_created"
+	EventMessageClaimed AuditEventType = "message_claimed"
+
+	EventAuditPruned AuditEventType = "audit_pruned"
 )
 
 type AuditLog struct {
@@ -49,6 +63,11 @@ type AuditLog struct {
 	// Additional data (JSON)
 	Metadata  map[string]interface{} `json:"metadata,omitempty" db:"metadata"`
 	CreatedAt time.Time              `json:"created_at" db:"created_at"`
+
+	// Hash chain, set when the entry is stored with the hash chain enabled
+	ChainSeq *int64  `json:"chain_seq,omitempty" db:"chain_seq"`
+	PrevHash *string `json:"prev_hash,omitempty" db:"prev_hash"`
+	Hash     *string `json:"hash,omitempty" db:"hash"`
 }
 
 // AuditLogStats represents statistics about audit logs
@@ -59,6 +78,59 @@ type AuditLogStats struct {
 	AverageRequestDuration *float64                 `json:"average_request_duration,omitempty"`
 }
 
+// AuditStatsInterval is the width of a time-series bucket
+type AuditStatsInterval string
+
+const (
+	AuditStatsMinute AuditStatsInterval = "minute"
+	AuditStatsHour   AuditStatsInterval = "hour"
+	AuditStatsDay    AuditStatsInterval = "day"
+)
+
+// Duration returns the bucket width, or zero for an unknown interval
+func (i AuditStatsInterval) Duration() time.Duration {
+	switch i {
+	case AuditStatsMinute:
+		return time.Minute
+	case AuditStatsHour:
+		return time.Hour
+	case AuditStatsDay:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// AuditStatsTimeSeries holds audit statistics in consecutive UTC buckets covering a window
+type AuditStatsTimeSeries struct {
+	Interval AuditStatsInterval  `json:"interval" example:"hour"`
+	From     time.Time           `json:"from"`
+	To       time.Time           `json:"to"`
+	Buckets  []*AuditStatsBucket `json:"buckets"`
+}
+
+// AuditStatsBucket holds the statistics of the entries created in [Start, Start+interval)
+type AuditStatsBucket struct {
+	Start           time.Time                `json:"start"`
+	TotalCount      int64                    `json:"total_count" example:"120"`
+	EventTypeCounts map[AuditEventType]int64 `json:"event_type_counts"`
+	// SuccessCount and FailureCount are the message_sent and message_failed entries
+	SuccessCount int64 `json:"success_count" example:"48"`
+	FailureCount int64 `json:"failure_count" example:"2"`
+	// SuccessRate is SuccessCount over both counts, absent when there were neither
+	SuccessRate *float64 `json:"success_rate,omitempty" example:"0.96"`
+	// Durations holds duration_ms percentiles per event type, for types that record one
+	Durations map[AuditEventType]*AuditDurationPercentiles `json:"durations,omitempty"`
+}
+
+// AuditDurationPercentiles are interpolated duration_ms percentiles
+type AuditDurationPercentiles struct {
+	Count int64   `json:"count" example:"50"`
+	P50   float64 `json:"p50" example:"120"`
+	P95   float64 `json:"p95" example:"480"`
+	P99   float64 `json:"p99" example:"910"`
+}
+
 // AuditLogBuilder helps build audit log entries
 type AuditLogBuilder struct {
 	log *AuditLog
@@ -141,8 +213,37 @@ type AuditLogFilter struct {
 	Endpoint   *string          `json:"endpoint,omitempty"`
 	FromDate   *time.Time       `json:"from_date,omitempty"`
 	ToDate     *time.Time       `json:"to_date,omitempty"`
-	Limit      int              `json:"limit,omitempty"`
-	Offset     int              `json:"offset,omitempty"`
+	// Metadata matches entries whose metadata contains every key with the given value;
+	// nested objects match when they contain the given fields
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// Search matches entries with all of its words in the event name or description
+	Search *string `json:"search,omitempty"`
+	Limit  int     `json:"limit,omitempty"`
+	Offset int     `json:"offset,omitempty"`
+
+	// Cursor continues a newest-first listing after the entry it marks, in place of Offset
+	Cursor *PageCursor `json:"-"`
+}
+
+// Matches reports whether an entry meets the filter's event type, batch, message, request
+// and endpoint criteria. The other criteria are left to the database.
+func (f *AuditLogFilter) Matches(auditLog *AuditLog) bool {
+	if len(f.EventTypes) > 0 && !slices.Contains(f.EventTypes, auditLog.EventType) {
+		return false
+	}
+	if f.BatchID != nil && (auditLog.BatchID == nil || *auditLog.BatchID != *f.BatchID) {
+		return false
+	}
+	if f.MessageID != nil && (auditLog.MessageID == nil || *auditLog.MessageID != *f.MessageID) {
+		return false
+	}
+	if f.RequestID != nil && (auditLog.RequestID == nil || *auditLog.RequestID != *f.RequestID) {
+		return false
+	}
+	if f.Endpoint != nil && (auditLog.Endpoint == nil || *auditLog.Endpoint != *f.Endpoint) {
+		return false
+	}
+	return true
 }
 
 // MarshalJSON implements custom JSON marshaling for the AuditLog metadata field
@@ -165,3 +266,191 @@ func (a *AuditLog) MarshalJSON() ([]byte, error) {
 
 	return json.Marshal(aux)
 }
+
+// AuditChainGenesisHash is the previous hash of the first entry in the hash chain
+var AuditChainGenesisHash = strings.Repeat("0", 64)
+
+// auditChainPayload is the canonical form of an entry that is hashed into the chain
+type auditChainPayload struct {
+	ChainSeq     int64          `json:"chain_seq"`
+	PrevHash     string         `json:"prev_hash"`
+	ID           uuid.UUID      `json:"id"`
+	EventType    AuditEventType `json:"event_type"`
+	EventName    string         `json:"event_name"`
+	Description  *string        `json:"description"`
+	BatchID      *uuid.UUID     `json:"batch_id"`
+	MessageID    *uuid.UUID     `json:"message_id"`
+	RequestID    *string        `json:"request_id"`
+	HTTPMethod   *string        `json:"http_method"`
+	Endpoint     *string        `json:"endpoint"`
+	StatusCode   *int           `json:"status_code"`
+	DurationMs   *int           `json:"duration_ms"`
+	MessageCount *int           `json:"message_count"`
+	SuccessCount *int           `json:"success_count"`
+	FailureCount *int           `json:"failure_count"`
+	Metadata     interface{}    `json:"metadata"`
+	CreatedAt    string         `json:"created_at"`
+}
+
+// ChainHash returns the hex HMAC-SHA256, keyed with key, of the entry's canonical JSON at
+// position seq, linked to prevHash; without a key it is a plain SHA-256, as entries were
+// linked before the chain was keyed. Metadata is normalised the way it reads back from the
+// database (sorted keys, JSON numbers) and CreatedAt is taken at the microsecond precision the
+// database stores, so an entry read back hashes the same as when it was written.
+func (a *AuditLog) ChainHash(seq int64, prevHash string, key []byte) (string, error) {
+	var metadata interface{}
+	if len(a.Metadata) > 0 {
+		raw, err := json.Marshal(a.Metadata)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		if err := json.Unmarshal(raw, &metadata); err != nil {
+			return "", fmt.Errorf("failed to normalise metadata: %w", err)
+		}
+	}
+
+	payload, err := json.Marshal(auditChainPayload{
+		ChainSeq:     seq,
+		PrevHash:     prevHash,
+		ID:           a.ID,
+		EventType:    a.EventType,
+		EventName:    a.EventName,
+		Description:  a.Description,
+		BatchID:      a.BatchID,
+		MessageID:    a.MessageID,
+		RequestID:    a.RequestID,
+		HTTPMethod:   a.HTTPMethod,
+		Endpoint:     a.Endpoint,
+		StatusCode:   a.StatusCode,
+		DurationMs:   a.DurationMs,
+		MessageCount: a.MessageCount,
+		SuccessCount: a.SuccessCount,
+		FailureCount: a.FailureCount,
+		Metadata:     metadata,
+		CreatedAt:    a.CreatedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(key) == 0 {
+		sum := sha256.Sum256(payload)
+		return hex.EncodeToString(sum[:]), nil
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// AuditChainHead records the last chained entry. It is signed like checkpoints, so deleting
+// the newest entries and rewinding the head to match is caught. Entries from KeyedFrom on have
+// keyed link hashes; those before were linked with a plain SHA-256.
+type AuditChainHead struct {
+	ChainSeq  int64  `json:"chain_seq" example:"5120"`
+	Hash      string `json:"hash"`
+	KeyedFrom int64  `json:"keyed_from" example:"1"`
+	Signature string `json:"signature"`
+
+	// SignatureValid is set when the head is read by a repository holding the key
+	SignatureValid bool `json:"signature_valid"`
+}
+
+func (h *AuditChainHead) signingPayload() []byte {
+	return []byte(fmt.Sprintf("head|%d|%s|%d", h.ChainSeq, h.Hash, h.KeyedFrom))
+}
+
+// Sign sets the head's signature using key
+func (h *AuditChainHead) Sign(key []byte) {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(h.signingPayload())
+	h.Signature = hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether the head was signed with key and left unchanged
+func (h *AuditChainHead) VerifySignature(key []byte) bool {
+	if len(key) == 0 {
+		return false
+	}
+	expected, err := hex.DecodeString(h.Signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(h.signingPayload())
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// AuditChainCheckpoint records the last entry removed by retention cleanup, so the entry
+// after it can still be linked once its predecessor is gone. The signature is an HMAC over
+// the checkpoint made with a key the database does not hold.
+type AuditChainCheckpoint struct {
+	ID           int64     `json:"id"`
+	ChainSeq     int64     `json:"chain_seq" example:"1200"`
+	Hash         string    `json:"hash"`
+	PrunedBefore time.Time `json:"pruned_before"`
+	DeletedCount int64     `json:"deleted_count" example:"1200"`
+	Signature    string    `json:"signature"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// SignatureValid is set when the checkpoint is read by a repository holding the key
+	SignatureValid bool `json:"signature_valid"`
+}
+
+func (c *AuditChainCheckpoint) signingPayload() []byte {
+	return []byte(fmt.Sprintf("%d|%s|%s|%d|%s",
+		c.ChainSeq, c.Hash,
+		c.PrunedBefore.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+		c.DeletedCount,
+		c.CreatedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)))
+}
+
+// Sign sets the checkpoint's signature using key
+func (c *AuditChainCheckpoint) Sign(key []byte) {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(c.signingPayload())
+	c.Signature = hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether the checkpoint was signed with key and left unchanged
+func (c *AuditChainCheckpoint) VerifySignature(key []byte) bool {
+	if len(key) == 0 {
+		return false
+	}
+	expected, err := hex.DecodeString(c.Signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(c.signingPayload())
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// Anchors a verified range can start from
+const (
+	AuditChainAnchorGenesis       = "genesis"
+	AuditChainAnchorPreviousEntry = "previous_entry"
+	AuditChainAnchorCheckpoint    = "checkpoint"
+)
+
+// AuditChainVerification is the result of walking the hash chain over a time range
+type AuditChainVerification struct {
+	Valid    bool       `json:"valid" example:"true"`
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+	FirstSeq int64      `json:"first_seq" example:"1"`
+	LastSeq  int64      `json:"last_seq" example:"5120"`
+	HeadSeq  int64      `json:"head_seq" example:"5120"`
+	Checked  int64      `json:"checked" example:"5120"`
+	// Anchor is what the first entry's previous hash was checked against
+	Anchor string           `json:"anchor,omitempty" example:"genesis"`
+	Break  *AuditChainBreak `json:"break,omitempty"`
+}
+
+// AuditChainBreak describes the first broken link found
+type AuditChainBreak struct {
+	ChainSeq   int64      `json:"chain_seq" example:"42"`
+	AuditLogID *uuid.UUID `json:"audit_log_id,omitempty"`
+	Reason     string     `json:"reason" example:"entry contents do not match its hash"`
+	Expected   string     `json:"expected,omitempty"`
+	Actual     string     `json:"actual,omitempty"`
+}
@@ -0,0 +1,180 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuditRetentionDefaultKey sets the default rule in a retention spec
+const AuditRetentionDefaultKey = "*"
+
+// AuditRetentionPolicy says how long audit entries are kept, per event type
+type AuditRetentionPolicy struct {
+	// Default applies to event types without a rule; zero keeps them forever
+	Default time.Duration
+	// Rules keep entries of an event type for the given age; zero keeps them forever
+	Rules map[AuditEventType]time.Duration
+}
+
+// ParseAuditRetentionPolicy reads a comma-separated list of event_type=age rules, such as
+// "api_request=7d,message_sent=730d,*=90d", where * sets the default. Ages are a whole
+// number of days with a d suffix or a Go duration; 0 keeps entries forever.
+func ParseAuditRetentionPolicy(spec string) (AuditRetentionPolicy, error) {
+	policy := AuditRetentionPolicy{Rules: make(map[AuditEventType]time.Duration)}
+
+	for _, rule := range strings.Split(spec, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		name, age, found := strings.Cut(rule, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return AuditRetentionPolicy{}, fmt.Errorf("invalid retention rule %q, use event_type=age", rule)
+		}
+
+		maxAge, err := parseRetentionAge(strings.TrimSpace(age))
+		if err != nil {
+			return AuditRetentionPolicy{}, fmt.Errorf("invalid retention age in %q: %w", rule, err)
+		}
+
+		if name == AuditRetentionDefaultKey {
+			policy.Default = maxAge
+		} else {
+			policy.Rules[AuditEventType(name)] = maxAge
+		}
+	}
+
+	return policy, nil
+}
+
+func parseRetentionAge(age string) (time.Duration, error) {
+	if days, found := strings.CutSuffix(age, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%q is not a whole number of days", age)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	maxAge, err := time.ParseDuration(age)
+	if err != nil {
+		return 0, err
+	}
+	if maxAge < 0 {
+		return 0, fmt.Errorf("%q is negative", age)
+	}
+	return maxAge, nil
+}
+
+func formatRetentionAge(maxAge time.Duration) string {
+	if maxAge > 0 && maxAge%(24*time.Hour) == 0 {
+		return strconv.Itoa(int(maxAge/(24*time.Hour))) + "d"
+	}
+	return maxAge.String()
+}
+
+// Enabled reports whether the policy ever deletes anything
+func (p AuditRetentionPolicy) Enabled() bool {
+	if p.Default > 0 {
+		return true
+	}
+	for _, maxAge := range p.Rules {
+		if maxAge > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Cutoffs resolves the policy's ages against now
+func (p AuditRetentionPolicy) Cutoffs(now time.Time) AuditRetentionCutoffs {
+	cutoffs := AuditRetentionCutoffs{EventTypes: make(map[AuditEventType]time.Time, len(p.Rules))}
+	for eventType, maxAge := range p.Rules {
+		var cutoff time.Time
+		if maxAge > 0 {
+			cutoff = now.Add(-maxAge)
+		}
+		cutoffs.EventTypes[eventType] = cutoff
+	}
+	if p.Default > 0 {
+		cutoff := now.Add(-p.Default)
+		cutoffs.Default = &cutoff
+	}
+	return cutoffs
+}
+
+// String lists the rules in the spec format, sorted by event type
+func (p AuditRetentionPolicy) String() string {
+	rules := make([]string, 0, len(p.Rules)+1)
+	for eventType, maxAge := range p.Rules {
+		rules = append(rules, string(eventType)+"="+formatRetentionAge(maxAge))
+	}
+	sort.Strings(rules)
+	if p.Default > 0 {
+		rules = append(rules, AuditRetentionDefaultKey+"="+formatRetentionAge(p.Default))
+	}
+	return strings.Join(rules, ",")
+}
+
+// AuditRetentionCutoffs are the creation times before which entries have outlived retention
+type AuditRetentionCutoffs struct {
+	// Default applies to event types not in EventTypes; nil keeps them forever
+	Default *time.Time
+	// EventTypes holds a cutoff per event type with a rule; the zero time keeps them forever
+	EventTypes map[AuditEventType]time.Time
+}
+
+// Expired reports whether an entry of eventType created at createdAt has outlived retention
+func (c AuditRetentionCutoffs) Expired(eventType AuditEventType, createdAt time.Time) bool {
+	if cutoff, ok := c.EventTypes[eventType]; ok {
+		return !cutoff.IsZero() && createdAt.Before(cutoff)
+	}
+	return c.Default != nil && createdAt.Before(*c.Default)
+}
+
+// Latest returns the latest cutoff, before which every expired entry was created
+func (c AuditRetentionCutoffs) Latest() time.Time {
+	var latest time.Time
+	if c.Default != nil {
+		latest = *c.Default
+	}
+	for _, cutoff := range c.EventTypes {
+		if cutoff.After(latest) {
+			latest = cutoff
+		}
+	}
+	return latest
+}
+
+// Earliest returns the earliest cutoff that deletes anything, before which every entry of an
+// event type that is not kept forever has expired; false if nothing is ever deleted
+func (c AuditRetentionCutoffs) Earliest() (time.Time, bool) {
+	var earliest time.Time
+	found := false
+	consider := func(cutoff time.Time) {
+		if !cutoff.IsZero() && (!found || cutoff.Before(earliest)) {
+			earliest = cutoff
+			found = true
+		}
+	}
+	if c.Default != nil {
+		consider(*c.Default)
+	}
+	for _, cutoff := range c.EventTypes {
+		consider(cutoff)
+	}
+	return earliest, found
+}
+
+// AuditPruneResult reports one enforcement of the retention policy
+type AuditPruneResult struct {
+	Deleted    map[AuditEventType]int64 `json:"deleted"`
+	Total      int64                    `json:"total" example:"12000"`
+	Batches    int                      `json:"batches" example:"3"`
+	DurationMs int64                    `json:"duration_ms" example:"840"`
+}
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAuditRetentionPolicy(t *testing.T) {
+	policy, err := ParseAuditRetentionPolicy(" api_request=7d, message_sent=730d,scheduler_started=0,*=36h ")
+	if err != nil {
+		t.Fatalf("ParseAuditRetentionPolicy() error = %v", err)
+	}
+
+	if policy.Default != 36*time.Hour {
+		t.Errorf("Expected default 36h, got %v", policy.Default)
+	}
+	if got := policy.Rules[EventAPIRequest]; got != 7*24*time.Hour {
+		t.Errorf("Expected api_request 7d, got %v", got)
+	}
+	if got := policy.Rules[EventMessageSent]; got != 730*24*time.Hour {
+		t.Errorf("Expected message_sent 730d, got %v", got)
+	}
+	if got, ok := policy.Rules[EventSchedulerStarted]; !ok || got != 0 {
+		t.Errorf("Expected scheduler_started to be kept forever, got %v", got)
+	}
+	if !policy.Enabled() {
+		t.Error("Expected policy to be enabled")
+	}
+	if got := policy.String(); got != "api_request=7d,message_sent=730d,scheduler_started=0s,*=36h0m0s" {
+		t.Errorf("Unexpected String() %q", got)
+	}
+}
+
+func TestParseAuditRetentionPolicy_Empty(t *testing.T) {
+	policy, err := ParseAuditRetentionPolicy("")
+	if err != nil {
+		t.Fatalf("ParseAuditRetentionPolicy() error = %v", err)
+	}
+	if policy.Enabled() {
+		t.Error("Expected empty policy to be disabled")
+	}
+
+	policy, err = ParseAuditRetentionPolicy("api_request=0")
+	if err != nil {
+		t.Fatalf("ParseAuditRetentionPolicy() error = %v", err)
+	}
+	if policy.Enabled() {
+		t.Error("Expected keep-forever policy to be disabled")
+	}
+}
+
+func TestParseAuditRetentionPolicy_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"api_request",
+		"=7d",
+		"api_request=seven",
+		"api_request=-1d",
+		"api_request=-1h",
+		"api_request=1.5d",
+	} {
+		if _, err := ParseAuditRetentionPolicy(spec); err == nil {
+			t.Errorf("ParseAuditRetentionPolicy(%q) expected an error", spec)
+		}
+	}
+}
+
+func TestAuditRetentionCutoffs_Expired(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	policy := AuditRetentionPolicy{
+		Default: 30 * 24 * time.Hour,
+		Rules: map[AuditEventType]time.Duration{
+			EventAPIRequest:  7 * 24 * time.Hour,
+			EventMessageSent: 0,
+		},
+	}
+	cutoffs := policy.Cutoffs(now)
+
+	tests := []struct {
+		name      string
+		eventType AuditEventType
+		age       time.Duration
+		expected  bool
+	}{
+		{"rule within retention", EventAPIRequest, 6 * 24 * time.Hour, false},
+		{"rule expired", EventAPIRequest, 8 * 24 * time.Hour, true},
+		{"kept forever", EventMessageSent, 10 * 365 * 24 * time.Hour, false},
+		{"default within retention", EventBatchStarted, 29 * 24 * time.Hour, false},
+		{"default expired", EventBatchStarted, 31 * 24 * time.Hour, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cutoffs.Expired(tt.eventType, now.Add(-tt.age)); got != tt.expected {
+				t.Errorf("Expired() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	if got, want := cutoffs.Latest(), now.Add(-7*24*time.Hour); !got.Equal(want) {
+		t.Errorf("Latest() = %v, want %v", got, want)
+	}
+}
+
+func TestAuditRetentionCutoffs_NoDefault(t *testing.T) {
+	now := time.Now()
+	cutoffs := AuditRetentionPolicy{
+		Rules: map[AuditEventType]time.Duration{EventAPIRequest: time.Hour},
+	}.Cutoffs(now)
+
+	if cutoffs.Default != nil {
+		t.Errorf("Expected no default cutoff, got %v", cutoffs.Default)
+	}
+	if cutoffs.Expired(EventBatchStarted, now.AddDate(-10, 0, 0)) {
+		t.Error("Expected event types without a rule to be kept forever")
+	}
+}
+
+func TestAuditRetentionCutoffs_Earliest(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	policy := AuditRetentionPolicy{
+		Default: 30 * 24 * time.Hour,
+		Rules: map[AuditEventType]time.Duration{
+			EventAPIRequest:  7 * 24 * time.Hour,
+			EventMessageSent: 730 * 24 * time.Hour,
+			EventBatchFailed: 0,
+		},
+	}
+
+	earliest, ok := policy.Cutoffs(now).Earliest()
+	if !ok {
+		t.Fatal("Expected an earliest cutoff")
+	}
+	if want := now.Add(-730 * 24 * time.Hour); !earliest.Equal(want) {
+		t.Errorf("Earliest() = %v, want %v", earliest, want)
+	}
+
+	if _, ok := (AuditRetentionPolicy{Rules: map[AuditEventType]time.Duration{EventAPIRequest: 0}}).Cutoffs(now).Earliest(); ok {
+		t.Error("Expected no earliest cutoff when nothing expires")
+	}
+}
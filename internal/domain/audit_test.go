@@ -24,6 +24,11 @@ func TestAuditEventType_Constants(t *testing.T) {
 		{"EventAPIRequest", EventAPIRequest, "api_request"},
 		{"EventWebhookRequest", EventWebhookRequest, "webhook_request"},
 		{"EventWebhookResponse", EventWebhookResponse, "webhook_response"},
+		{"EventMessageRecovered", EventMessageRecovered, "message_recovered"},
+		{"EventSchedulerReconfigured", EventSchedulerReconfigured, "scheduler_reconfigured"},
+		{"EventMessageCreated", EventMessageCreated, "message_created"},
+		{"EventMessageClaimed", EventMessageClaimed, "message_claimed"},
+		{"EventAuditPruned", EventAuditPruned, "audit_pruned"},
 	}
 
 	for _, tt := range tests {
@@ -242,6 +247,40 @@ func TestAuditLogFilter(t *testing.T) {
 	}
 }
 
+func TestAuditLogFilter_Matches(t *testing.T) {
+	batchID := uuid.New()
+	messageID := uuid.New()
+	auditLog := NewAuditLog(EventMessageSent, "Message Sent").
+		WithBatchID(batchID).
+		WithMessageID(messageID).
+		Build()
+
+	otherID := uuid.New()
+	requestID := "req-123"
+
+	tests := []struct {
+		name     string
+		filter   AuditLogFilter
+		expected bool
+	}{
+		{"empty filter", AuditLogFilter{}, true},
+		{"matching event type", AuditLogFilter{EventTypes: []AuditEventType{EventMessageFailed, EventMessageSent}}, true},
+		{"other event type", AuditLogFilter{EventTypes: []AuditEventType{EventMessageFailed}}, false},
+		{"matching batch and message", AuditLogFilter{BatchID: &batchID, MessageID: &messageID}, true},
+		{"other batch", AuditLogFilter{BatchID: &otherID}, false},
+		{"other message", AuditLogFilter{MessageID: &otherID}, false},
+		{"request ID the entry lacks", AuditLogFilter{RequestID: &requestID}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(auditLog); got != tt.expected {
+				t.Errorf("Matches() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestAuditLogStats(t *testing.T) {
 	lastEventTime := "2023-12-01T10:00:00Z"
 	avgDuration := 150.5
@@ -273,3 +312,108 @@ func TestAuditLogStats(t *testing.T) {
 		t.Errorf("Expected average duration %f, got %f", avgDuration, *stats.AverageRequestDuration)
 	}
 }
+
+func TestAuditLog_ChainHash(t *testing.T) {
+	log := NewAuditLog(EventWebhookResponse, "Webhook Response Received").
+		WithMessageID(uuid.New()).
+		WithMetadata("response_body", json.RawMessage(`{"z":1,"a":"ok"}`)).
+		WithMetadata("attempt", 2).
+		Build()
+
+	hash, err := log.ChainHash(1, AuditChainGenesisHash, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(hash) != 64 {
+		t.Errorf("Expected a hex SHA-256, got %q", hash)
+	}
+
+	// The entry as read back from the database: metadata decoded, timestamp at microseconds
+	stored := *log
+	stored.Metadata = map[string]interface{}{
+		"attempt":       float64(2),
+		"response_body": map[string]interface{}{"a": "ok", "z": float64(1)},
+	}
+	stored.CreatedAt = log.CreatedAt.Truncate(time.Microsecond).In(time.FixedZone("EST", -5*3600))
+
+	if storedHash, _ := stored.ChainHash(1, AuditChainGenesisHash, nil); storedHash != hash {
+		t.Errorf("Expected the stored entry to hash the same, got %s and %s", storedHash, hash)
+	}
+
+	if linked, _ := log.ChainHash(2, hash, nil); linked == hash {
+		t.Error("Expected the hash to depend on the position and previous hash")
+	}
+
+	stored.Metadata["attempt"] = float64(3)
+	if tampered, _ := stored.ChainHash(1, AuditChainGenesisHash, nil); tampered == hash {
+		t.Error("Expected a metadata change to change the hash")
+	}
+}
+
+func TestAuditLog_ChainHashKeyed(t *testing.T) {
+	log := NewAuditLog(EventAPIRequest, "API Request Processed").Build()
+
+	plain, _ := log.ChainHash(1, AuditChainGenesisHash, nil)
+	keyed, err := log.ChainHash(1, AuditChainGenesisHash, []byte("chain-key"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(keyed) != 64 || keyed == plain {
+		t.Errorf("Expected a keyed hash different from the plain one, got %q", keyed)
+	}
+	if other, _ := log.ChainHash(1, AuditChainGenesisHash, []byte("other-key")); other == keyed {
+		t.Error("Expected the hash to depend on the key")
+	}
+}
+
+func TestAuditChainHead_Signature(t *testing.T) {
+	key := []byte("checkpoint-key")
+	head := &AuditChainHead{ChainSeq: 42, Hash: AuditChainGenesisHash, KeyedFrom: 10}
+	head.Sign(key)
+
+	if !head.VerifySignature(key) {
+		t.Error("Expected the signature to verify with the signing key")
+	}
+	if head.VerifySignature(nil) {
+		t.Error("Expected the signature not to verify without a key")
+	}
+
+	// Rewinding the head after deleting the newest entries
+	head.ChainSeq = 40
+	if head.VerifySignature(key) {
+		t.Error("Expected the signature not to verify after the head changed")
+	}
+
+	head.ChainSeq = 42
+	head.KeyedFrom = 50
+	if head.VerifySignature(key) {
+		t.Error("Expected the signature to cover where keyed hashes start")
+	}
+}
+
+func TestAuditChainCheckpoint_Signature(t *testing.T) {
+	key := []byte("checkpoint-key")
+	checkpoint := &AuditChainCheckpoint{
+		ChainSeq:     42,
+		Hash:         AuditChainGenesisHash,
+		PrunedBefore: time.Now().AddDate(0, 0, -30),
+		DeletedCount: 42,
+		CreatedAt:    time.Now(),
+	}
+	checkpoint.Sign(key)
+
+	if !checkpoint.VerifySignature(key) {
+		t.Error("Expected the signature to verify with the signing key")
+	}
+	if checkpoint.VerifySignature([]byte("other-key")) {
+		t.Error("Expected the signature not to verify with another key")
+	}
+	if checkpoint.VerifySignature(nil) {
+		t.Error("Expected the signature not to verify without a key")
+	}
+
+	checkpoint.ChainSeq = 41
+	if checkpoint.VerifySignature(key) {
+		t.Error("Expected the signature not to verify after the checkpoint changed")
+	}
+}
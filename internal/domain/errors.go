@@ -7,7 +7,17 @@ var (
 	ErrSchedulerRunning    = errors.New("scheduler is already running")
 	ErrSchedulerNotRunning = errors.New("scheduler is not running")
 	ErrMessageTooLong      = errors.New("message content exceeds maximum length")
+	ErrEmptyContent        = errors.New("message content is required")
 	ErrInvalidPhoneNumber  = errors.New("invalid phone number format")
 	ErrWebhookFailed       = errors.New("webhook request failed")
 	ErrMaxRetriesExceeded  = errors.New("maximum retry attempts exceeded")
+	ErrInvalidBatch        = errors.New("batch contains invalid messages")
+
+	ErrInvalidSchedulerConfig = errors.New("invalid scheduler configuration")
+
+	ErrIdempotencyKeyConflict  = errors.New("idempotency key was already used with a different request")
+	ErrDuplicateIdempotencyKey = errors.New("idempotency key already exists")
+
+	ErrInvalidCursor      = errors.New("invalid pagination cursor")
+	ErrInvalidStatsWindow = errors.New("invalid statistics window")
 )
@@ -31,6 +31,11 @@ func TestDomainErrors(t *testing.T) {
 			err:      ErrMessageTooLong,
 			expected: "message content exceeds maximum length",
 		},
+		{
+			name:     "ErrEmptyContent",
+			err:      ErrEmptyContent,
+			expected: "message content is required",
+		},
 		{
 			name:     "ErrInvalidPhoneNumber",
 			err:      ErrInvalidPhoneNumber,
@@ -46,6 +51,36 @@ func TestDomainErrors(t *testing.T) {
 			err:      ErrMaxRetriesExceeded,
 			expected: "maximum retry attempts exceeded",
 		},
+		{
+			name:     "ErrInvalidBatch",
+			err:      ErrInvalidBatch,
+			expected: "batch contains invalid messages",
+		},
+		{
+			name:     "ErrIdempotencyKeyConflict",
+			err:      ErrIdempotencyKeyConflict,
+			expected: "idempotency key was already used with a different request",
+		},
+		{
+			name:     "ErrDuplicateIdempotencyKey",
+			err:      ErrDuplicateIdempotencyKey,
+			expected: "idempotency key already exists",
+		},
+		{
+			name:     "ErrInvalidSchedulerConfig",
+			err:      ErrInvalidSchedulerConfig,
+			expected: "invalid scheduler configuration",
+		},
+		{
+			name:     "ErrInvalidCursor",
+			err:      ErrInvalidCursor,
+			expected: "invalid pagination cursor",
+		},
+		{
+			name:     "ErrInvalidStatsWindow",
+			err:      ErrInvalidStatsWindow,
+			expected: "invalid statistics window",
+		},
 	}
 
 	for _, tt := range tests {
@@ -86,9 +121,16 @@ func TestErrorsAreNotNil(t *testing.T) {
 		ErrSchedulerRunning,
 		ErrSchedulerNotRunning,
 		ErrMessageTooLong,
+		ErrEmptyContent,
 		ErrInvalidPhoneNumber,
 		ErrWebhookFailed,
 		ErrMaxRetriesExceeded,
+		ErrInvalidBatch,
+		ErrIdempotencyKeyConflict,
+		ErrDuplicateIdempotencyKey,
+		ErrInvalidSchedulerConfig,
+		ErrInvalidCursor,
+		ErrInvalidStatsWindow,
 	}
 
 	for i, err := range domainErrors {
@@ -27,6 +27,43 @@ type Message struct {
 	CreatedAt   time.Time     `json:"created_at" db:"created_at" example:"2023-12-01T10:00:00Z"`
 	SentAt      *time.Time    `json:"sent_at,omitempty" db:"sent_at" example:"2023-12-01T10:05:00Z"`
 	UpdatedAt   time.Time     `json:"updated_at" db:"updated_at" example:"2023-12-01T10:05:00Z"`
+
+	// Retry scheduling for failed sends
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" db:"next_attempt_at" example:"2023-12-01T10:06:00Z"`
+	LastError     *string    `json:"last_error,omitempty" db:"last_error" example:"unexpected status code: 503"`
+
+	// Claim ownership while a message is being sent
+	ClaimedBy *string    `json:"claimed_by,omitempty" db:"claimed_by" example:"ims-7f9c4d-1"`
+	ClaimedAt *time.Time `json:"claimed_at,omitempty" db:"claimed_at" example:"2023-12-01T10:04:59Z"`
+
+	// Idempotency tracking for producer retries
+	IdempotencyKey  *string `json:"idempotency_key,omitempty" db:"idempotency_key" example:"order-42-sms"`
+	IdempotencyHash string  `json:"-" db:"idempotency_hash"`
+}
+
+// MessageInput represents the caller-supplied fields of a message to enqueue
+type MessageInput struct {
+	PhoneNumber string `json:"phone_number" example:"+1234567890"`
+	Content     string `json:"content" example:"Hello, this is a test message"`
+}
+
+// BulkItemStatus is how a single item of a bulk enqueue request ended
+type BulkItemStatus string
+
+const (
+	BulkItemCreated BulkItemStatus = "created"
+	BulkItemInvalid BulkItemStatus = "invalid"
+	// BulkItemSkipped means the item was valid but not enqueued because another item in
+	// the request was invalid; it can be resent unchanged
+	BulkItemSkipped BulkItemStatus = "skipped"
+)
+
+// BulkMessageResult represents the outcome of a single item in a bulk enqueue request
+type BulkMessageResult struct {
+	Index  int            `json:"index" example:"0"`
+	Status BulkItemStatus `json:"status" example:"created" enums:"created,invalid,skipped"`
+	ID     *uuid.UUID     `json:"id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Error  string         `json:"error,omitempty" example:"invalid phone number format"`
 }
 
 // WebhookRequest represents a request to send a message via webhook
@@ -55,3 +92,100 @@ type SchedulerStatus struct {
 	Running   bool       `json:"running" example:"true"`
 	StartedAt *time.Time `json:"started_at,omitempty" example:"2023-12-01T10:00:00Z"`
 }
+
+// SendOutcome is how a claimed message ended within a batch
+type SendOutcome string
+
+const (
+	OutcomeSent           SendOutcome = "sent"
+	OutcomeFailed         SendOutcome = "failed"
+	OutcomeSkippedTooLong SendOutcome = "skipped_too_long"
+	// OutcomeReleased means the batch deadline passed before the message was attempted;
+	// it was returned to pending for a later batch
+	OutcomeReleased SendOutcome = "released"
+)
+
+// MessageSendResult is the outcome of one claimed message within a batch
+type MessageSendResult struct {
+	MessageID  uuid.UUID   `json:"message_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Outcome    SendOutcome `json:"outcome" example:"sent"`
+	Error      string      `json:"error,omitempty" example:"webhook request failed"`
+	DurationMs int64       `json:"duration_ms" example:"120"`
+}
+
+// BatchResult summarises one batch run: which claimed messages were attempted, how each
+// ended and how long each took
+type BatchResult struct {
+	BatchID        uuid.UUID           `json:"batch_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	InstanceID     string              `json:"instance_id,omitempty" example:"ims-7f9c-1"`
+	StartedAt      time.Time           `json:"started_at" example:"2023-12-01T10:00:00Z"`
+	DurationMs     int64               `json:"duration_ms" example:"840"`
+	Attempted      []uuid.UUID         `json:"attempted"`
+	Succeeded      []uuid.UUID         `json:"succeeded"`
+	Failed         []uuid.UUID         `json:"failed"`
+	SkippedTooLong []uuid.UUID         `json:"skipped_too_long"`
+	Released       []uuid.UUID         `json:"released,omitempty"`
+	Messages       []MessageSendResult `json:"messages"`
+	Error          string              `json:"error,omitempty" example:"failed to claim messages: connection refused"`
+}
+
+// NewBatchResult groups per-message results by outcome. Attempted covers the messages
+// handed to the webhook, i.e. those that were sent or failed.
+func NewBatchResult(startedAt time.Time, messages []MessageSendResult) *BatchResult {
+	result := &BatchResult{
+		StartedAt:      startedAt,
+		Attempted:      []uuid.UUID{},
+		Succeeded:      []uuid.UUID{},
+		Failed:         []uuid.UUID{},
+		SkippedTooLong: []uuid.UUID{},
+		Messages:       messages,
+	}
+	if result.Messages == nil {
+		result.Messages = []MessageSendResult{}
+	}
+
+	for _, msg := range messages {
+		switch msg.Outcome {
+		case OutcomeSent:
+			result.Attempted = append(result.Attempted, msg.MessageID)
+			result.Succeeded = append(result.Succeeded, msg.MessageID)
+		case OutcomeFailed:
+			result.Attempted = append(result.Attempted, msg.MessageID)
+			result.Failed = append(result.Failed, msg.MessageID)
+		case OutcomeSkippedTooLong:
+			result.SkippedTooLong = append(result.SkippedTooLong, msg.MessageID)
+		case OutcomeReleased:
+			result.Released = append(result.Released, msg.MessageID)
+		}
+	}
+
+	return result
+}
+
+// SuccessCount is the number of messages delivered to the webhook
+func (r *BatchResult) SuccessCount() int {
+	return len(r.Succeeded)
+}
+
+// FailureCount is the number of messages that failed or were skipped for being too long;
+// released messages are not counted as they will be retried
+func (r *BatchResult) FailureCount() int {
+	return len(r.Failed) + len(r.SkippedTooLong)
+}
+
+// SchedulerDesiredState is the persisted run state that every replica converges on
+type SchedulerDesiredState struct {
+	Running bool `json:"running" example:"true"`
+	// IntervalMs and BatchSize override the configured defaults when non-zero
+	IntervalMs int64     `json:"interval_ms,omitempty" example:"30000"`
+	BatchSize  int       `json:"batch_size,omitempty" example:"50"`
+	ChangedBy  string    `json:"changed_by" example:"api@10.0.0.5"`
+	ChangedAt  time.Time `json:"changed_at" example:"2023-12-01T10:00:00Z"`
+}
+
+// LeaderLease describes the replica currently holding the scheduler leadership lease
+type LeaderLease struct {
+	Holder     string    `json:"holder" example:"ims-7f9c4d-1"`
+	AcquiredAt time.Time `json:"acquired_at" example:"2023-12-01T10:00:00Z"`
+	ExpiresAt  time.Time `json:"expires_at" example:"2023-12-01T10:00:30Z"`
+}
@@ -284,3 +284,61 @@ func TestSchedulerStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestNewBatchResult(t *testing.T) {
+	sent, failed, tooLong, released := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	startedAt := time.Now()
+
+	result := NewBatchResult(startedAt, []MessageSendResult{
+		{MessageID: sent, Outcome: OutcomeSent, DurationMs: 10},
+		{MessageID: failed, Outcome: OutcomeFailed, Error: "webhook request failed", DurationMs: 20},
+		{MessageID: tooLong, Outcome: OutcomeSkippedTooLong},
+		{MessageID: released, Outcome: OutcomeReleased},
+	})
+
+	if !result.StartedAt.Equal(startedAt) {
+		t.Errorf("Expected started at %v, got %v", startedAt, result.StartedAt)
+	}
+	if len(result.Attempted) != 2 {
+		t.Errorf("Expected 2 attempted, got %d", len(result.Attempted))
+	}
+	if len(result.Succeeded) != 1 || result.Succeeded[0] != sent {
+		t.Errorf("Expected succeeded [%s], got %v", sent, result.Succeeded)
+	}
+	if len(result.Failed) != 1 || result.Failed[0] != failed {
+		t.Errorf("Expected failed [%s], got %v", failed, result.Failed)
+	}
+	if len(result.SkippedTooLong) != 1 || result.SkippedTooLong[0] != tooLong {
+		t.Errorf("Expected skipped_too_long [%s], got %v", tooLong, result.SkippedTooLong)
+	}
+	if len(result.Released) != 1 || result.Released[0] != released {
+		t.Errorf("Expected released [%s], got %v", released, result.Released)
+	}
+	if result.SuccessCount() != 1 {
+		t.Errorf("Expected success count 1, got %d", result.SuccessCount())
+	}
+	if result.FailureCount() != 2 {
+		t.Errorf("Expected failure count 2, got %d", result.FailureCount())
+	}
+}
+
+func TestNewBatchResult_Empty(t *testing.T) {
+	result := NewBatchResult(time.Now(), nil)
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Failed to marshal batch result: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal batch result: %v", err)
+	}
+
+	// Empty lists are encoded as [] rather than null so dashboards can rely on them
+	for _, key := range []string{"attempted", "succeeded", "failed", "skipped_too_long", "messages"} {
+		if _, ok := decoded[key].([]interface{}); !ok {
+			t.Errorf("Expected %s to be an empty array, got %v", key, decoded[key])
+		}
+	}
+}
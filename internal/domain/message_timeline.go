@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MessageTimelineStage names a step in a message's lifecycle
+type MessageTimelineStage string
+
+const (
+	StageCreated         MessageTimelineStage = "created"
+	StageClaimed         MessageTimelineStage = "claimed"
+	StageWebhookRequest  MessageTimelineStage = "webhook_request"
+	StageWebhookResponse MessageTimelineStage = "webhook_response"
+	StageSent            MessageTimelineStage = "sent"
+	StageFailed          MessageTimelineStage = "failed"
+	StageRecovered       MessageTimelineStage = "recovered"
+)
+
+// timelineStages maps the audit events that make up a message's lifecycle to their stage.
+// The order of timelineStageRank breaks ties between entries written in the same instant.
+var timelineStages = map[AuditEventType]MessageTimelineStage{
+	EventMessageCreated:   StageCreated,
+	EventMessageClaimed:   StageClaimed,
+	EventWebhookRequest:   StageWebhookRequest,
+	EventWebhookResponse:  StageWebhookResponse,
+	EventMessageSent:      StageSent,
+	EventMessageFailed:    StageFailed,
+	EventMessageRecovered: StageRecovered,
+}
+
+var timelineStageRank = map[MessageTimelineStage]int{
+	StageCreated:         0,
+	StageClaimed:         1,
+	StageWebhookRequest:  2,
+	StageWebhookResponse: 3,
+	StageSent:            4,
+	StageFailed:          4,
+	StageRecovered:       5,
+}
+
+// MessageTimelineEvent is one step of a message's lifecycle
+type MessageTimelineEvent struct {
+	Stage MessageTimelineStage `json:"stage" example:"webhook_request" enums:"created,claimed,webhook_request,webhook_response,sent,failed,recovered"`
+	At    time.Time            `json:"at" example:"2023-12-01T10:05:00Z"`
+	// Attempt numbers webhook requests and their responses, starting at 1
+	Attempt     int                    `json:"attempt,omitempty" example:"1"`
+	Description string                 `json:"description,omitempty" example:"Sent request to webhook endpoint"`
+	StatusCode  *int                   `json:"status_code,omitempty" example:"202"`
+	DurationMs  *int                   `json:"duration_ms,omitempty" example:"140"`
+	Error       string                 `json:"error,omitempty" example:"unexpected status code: 503"`
+	BatchID     *uuid.UUID             `json:"batch_id,omitempty"`
+	RequestID   *string                `json:"request_id,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	// AuditLogID is the audit entry the step was read from; absent when it was derived from
+	// the message record because no entry was found
+	AuditLogID *uuid.UUID `json:"audit_log_id,omitempty"`
+}
+
+// MessageDetails is a message together with what happened to it
+type MessageDetails struct {
+	Message  *Message                `json:"message"`
+	Timeline []*MessageTimelineEvent `json:"timeline"`
+	// ProviderResponse is the cached webhook response for a sent message, while it is cached
+	ProviderResponse interface{} `json:"provider_response,omitempty"`
+}
+
+// BuildMessageTimeline orders a message's audit entries into its lifecycle, oldest first.
+// Entries unrelated to the lifecycle are skipped. Creation, the latest claim and sending are
+// taken from the message record when no audit entry covers them, as for messages enqueued
+// before those events were audited.
+func BuildMessageTimeline(msg *Message, auditLogs []*AuditLog) []*MessageTimelineEvent {
+	timeline := make([]*MessageTimelineEvent, 0, len(auditLogs)+2)
+	seen := make(map[MessageTimelineStage]bool)
+
+	for _, auditLog := range auditLogs {
+		stage, ok := timelineStages[auditLog.EventType]
+		if !ok {
+			continue
+		}
+		seen[stage] = true
+
+		id := auditLog.ID
+		event := &MessageTimelineEvent{
+			Stage:      stage,
+			At:         auditLog.CreatedAt,
+			StatusCode: auditLog.StatusCode,
+			DurationMs: auditLog.DurationMs,
+			BatchID:    auditLog.BatchID,
+			RequestID:  auditLog.RequestID,
+			Metadata:   auditLog.Metadata,
+			AuditLogID: &id,
+		}
+		if auditLog.Description != nil {
+			event.Description = *auditLog.Description
+		}
+		if errMsg, ok := auditLog.Metadata["error"].(string); ok {
+			event.Error = errMsg
+		}
+		timeline = append(timeline, event)
+	}
+
+	if !seen[StageCreated] {
+		timeline = append(timeline, &MessageTimelineEvent{Stage: StageCreated, At: msg.CreatedAt})
+	}
+	if !seen[StageClaimed] && msg.ClaimedAt != nil {
+		event := &MessageTimelineEvent{Stage: StageClaimed, At: *msg.ClaimedAt}
+		if msg.ClaimedBy != nil {
+			event.Metadata = map[string]interface{}{"claimed_by": *msg.ClaimedBy}
+		}
+		timeline = append(timeline, event)
+	}
+	if !seen[StageSent] && msg.Status == StatusSent && msg.SentAt != nil {
+		timeline = append(timeline, &MessageTimelineEvent{Stage: StageSent, At: *msg.SentAt})
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool {
+		if !timeline[i].At.Equal(timeline[j].At) {
+			return timeline[i].At.Before(timeline[j].At)
+		}
+		return timelineStageRank[timeline[i].Stage] < timelineStageRank[timeline[j].Stage]
+	})
+
+	// Number the webhook attempts; a response belongs to the request before it
+	attempt := 0
+	for _, event := range timeline {
+		switch event.Stage {
+		case StageWebhookRequest:
+			attempt++
+			event.Attempt = attempt
+		case StageWebhookResponse:
+			event.Attempt = attempt
+		}
+	}
+
+	return timeline
+}
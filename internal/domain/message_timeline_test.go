@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBuildMessageTimeline_AttemptsAndOrder(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	msg := &Message{ID: uuid.New(), Status: StatusSent, CreatedAt: start}
+
+	entry := func(eventType AuditEventType, at time.Time) *AuditLog {
+		auditLog := NewAuditLog(eventType, string(eventType)).WithMessageID(msg.ID).Build()
+		auditLog.CreatedAt = at
+		return auditLog
+	}
+	failed := entry(EventMessageFailed, start.Add(2*time.Second))
+	failed.Metadata = map[string]interface{}{"error": "unexpected status code: 503"}
+
+	// Newest first, as the repository returns them, with a request and its response in the
+	// same instant and an unrelated entry mixed in
+	timeline := BuildMessageTimeline(msg, []*AuditLog{
+		entry(EventMessageSent, start.Add(4*time.Second)),
+		entry(EventWebhookResponse, start.Add(3*time.Second)),
+		entry(EventWebhookRequest, start.Add(3*time.Second)),
+		entry(EventMessageClaimed, start.Add(3*time.Second)),
+		failed,
+		entry(EventWebhookResponse, start.Add(time.Second)),
+		entry(EventWebhookRequest, start.Add(time.Second)),
+		entry(EventAPIRequest, start.Add(time.Second)),
+		entry(EventMessageCreated, start),
+	})
+
+	expected := []struct {
+		stage   MessageTimelineStage
+		attempt int
+	}{
+		{StageCreated, 0},
+		{StageWebhookRequest, 1},
+		{StageWebhookResponse, 1},
+		{StageFailed, 0},
+		{StageClaimed, 0},
+		{StageWebhookRequest, 2},
+		{StageWebhookResponse, 2},
+		{StageSent, 0},
+	}
+	if len(timeline) != len(expected) {
+		t.Fatalf("Expected %d events, got %d", len(expected), len(timeline))
+	}
+	for i, want := range expected {
+		if timeline[i].Stage != want.stage || timeline[i].Attempt != want.attempt {
+			t.Errorf("Event %d: expected %s attempt %d, got %s attempt %d",
+				i, want.stage, want.attempt, timeline[i].Stage, timeline[i].Attempt)
+		}
+	}
+	if timeline[3].Error != "unexpected status code: 503" {
+		t.Errorf("Expected the failure's error, got %q", timeline[3].Error)
+	}
+}
+
+func TestBuildMessageTimeline_FallsBackToMessageRecord(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	claimedAt := createdAt.Add(time.Minute)
+	sentAt := claimedAt.Add(time.Second)
+	owner := "ims-1"
+	msg := &Message{
+		ID:        uuid.New(),
+		Status:    StatusSent,
+		CreatedAt: createdAt,
+		ClaimedAt: &claimedAt,
+		ClaimedBy: &owner,
+		SentAt:    &sentAt,
+	}
+
+	timeline := BuildMessageTimeline(msg, nil)
+
+	expected := []MessageTimelineStage{StageCreated, StageClaimed, StageSent}
+	if len(timeline) != len(expected) {
+		t.Fatalf("Expected %d events, got %d", len(expected), len(timeline))
+	}
+	for i, stage := range expected {
+		if timeline[i].Stage != stage {
+			t.Errorf("Event %d: expected %s, got %s", i, stage, timeline[i].Stage)
+		}
+		if timeline[i].AuditLogID != nil {
+			t.Errorf("Event %d: expected no audit entry", i)
+		}
+	}
+	if timeline[1].Metadata["claimed_by"] != owner {
+		t.Errorf("Expected the claim owner, got %v", timeline[1].Metadata)
+	}
+}
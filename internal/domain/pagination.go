@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PageCursor marks the last row of a page in a list ordered newest first by (timestamp, id).
+// The next page starts right after it, so rows written between requests neither shift nor
+// repeat the pages a client has already seen.
+type PageCursor struct {
+	Time time.Time
+	ID   uuid.UUID
+}
+
+// NewPageCursor creates a cursor pointing at the row with the given timestamp and ID
+func NewPageCursor(t time.Time, id uuid.UUID) *PageCursor {
+	return &PageCursor{Time: t, ID: id}
+}
+
+// Encode returns the cursor as an opaque, URL-safe token
+func (c *PageCursor) Encode() string {
+	raw := c.Time.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Follows reports whether a row with the given timestamp and ID belongs after the cursor,
+// matching the database ordering of timestamp DESC, id DESC
+func (c *PageCursor) Follows(t time.Time, id uuid.UUID) bool {
+	if !t.Equal(c.Time) {
+		return t.Before(c.Time)
+	}
+	return bytes.Compare(id[:], c.ID[:]) < 0
+}
+
+// Precedes reports whether the entry at (t, id) comes after the cursor in oldest-first
+// order, the reverse of Follows
+func (c *PageCursor) Precedes(t time.Time, id uuid.UUID) bool {
+	if !t.Equal(c.Time) {
+		return t.After(c.Time)
+	}
+	return bytes.Compare(id[:], c.ID[:]) > 0
+}
+
+// DecodePageCursor parses a token produced by Encode
+func DecodePageCursor(token string) (*PageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	timestamp, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return nil, ErrInvalidCursor
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return NewPageCursor(t, parsedID), nil
+}
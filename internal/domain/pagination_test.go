@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPageCursor_RoundTrip(t *testing.T) {
+	at := time.Date(2024, 3, 1, 9, 30, 0, 123456000, time.FixedZone("UTC+3", 3*60*60))
+	cursor := NewPageCursor(at, uuid.New())
+
+	decoded, err := DecodePageCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("DecodePageCursor() error = %v", err)
+	}
+	if !decoded.Time.Equal(cursor.Time) {
+		t.Errorf("Expected time %v, got %v", cursor.Time, decoded.Time)
+	}
+	if decoded.ID != cursor.ID {
+		t.Errorf("Expected ID %s, got %s", cursor.ID, decoded.ID)
+	}
+}
+
+func TestDecodePageCursor_Invalid(t *testing.T) {
+	for _, token := range []string{
+		"",
+		"not base64!",
+		NewPageCursor(time.Now(), uuid.New()).Encode()[:10],
+		"MjAyNC0wMS0wMVQwMDowMDowMFp8bm90LWEtdXVpZA", // 2024-01-01T00:00:00Z|not-a-uuid
+	} {
+		if _, err := DecodePageCursor(token); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("DecodePageCursor(%q) error = %v, want ErrInvalidCursor", token, err)
+		}
+	}
+}
+
+func TestPageCursor_Follows(t *testing.T) {
+	at := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	low := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	high := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	cursor := NewPageCursor(at, high)
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		id       uuid.UUID
+		expected bool
+	}{
+		{"older", at.Add(-time.Second), high, true},
+		{"newer", at.Add(time.Second), low, false},
+		{"same time, lower ID", at, low, true},
+		{"the cursor row itself", at, high, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cursor.Follows(tt.at, tt.id); got != tt.expected {
+				t.Errorf("Follows() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPageCursor_Precedes(t *testing.T) {
+	at := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	low := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	high := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	cursor := NewPageCursor(at, low)
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		id       uuid.UUID
+		expected bool
+	}{
+		{"newer", at.Add(time.Second), low, true},
+		{"older", at.Add(-time.Second), high, false},
+		{"same time, higher ID", at, high, true},
+		{"the cursor row itself", at, low, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cursor.Precedes(tt.at, tt.id); got != tt.expected {
+				t.Errorf("Precedes() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
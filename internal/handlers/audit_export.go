@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ims/internal/domain"
+)
+
+// Export formats
+const (
+	exportFormatNDJSON = "ndjson"
+	exportFormatCSV    = "csv"
+)
+
+// exportFlushEvery is how many rows are written between flushes to the client
+const exportFlushEvery = 500
+
+// auditExportColumns are the fixed CSV columns; one metadata.<key> column per metadata key
+// follows them
+var auditExportColumns = []string{
+	"id", "event_type", "event_name", "description", "batch_id", "message_id", "request_id",
+	"http_method", "endpoint", "status_code", "duration_ms", "message_count", "success_count",
+	"failure_count", "created_at", "chain_seq", "prev_hash", "hash",
+}
+
+// ExportAuditLogs godoc
+// @Summary Export audit logs
+// @Description Stream every audit log matching the filter, oldest first, as NDJSON or CSV with no limit. CSV flattens metadata into one metadata.<key> column per top-level key, with nested values as JSON.
+// @Tags audit
+// @Produce application/x-ndjson
+// @Produce text/csv
+// @Param format query string false "Export format" Enums(ndjson, csv) default(ndjson)
+// @Param event_types query []string false "Filter by event types"
+// @Param batch_id query string false "Filter by batch ID"
+// @Param message_id query string false "Filter by message ID"
+// @Param request_id query string false "Filter by request ID"
+// @Param endpoint query string false "Filter by endpoint"
+// @Param from_date query string false "Filter from date (RFC3339 format)"
+// @Param to_date query string false "Filter to date (RFC3339 format)"
+// @Param metadata.{key} query string false "Filter by a metadata value, e.g. metadata.error=timeout; JSON values match by type and objects by containment"
+// @Param search query string false "Filter by words in the event name or description"
+// @Success 200 {string} string "NDJSON or CSV stream"
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /audit/export [get]
+func (h *AuditHandler) ExportAuditLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = exportFormatNDJSON
+	}
+	if format != exportFormatNDJSON && format != exportFormatCSV {
+		http.Error(w, "Invalid format, use 'ndjson' or 'csv'", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := parseAuditLogFilter(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// An export can outlast the server's write timeout
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("Could not lift write deadline for audit export: %v", err)
+	}
+
+	filename := fmt.Sprintf("audit-logs-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	var write func(*domain.AuditLog) error
+	var flush func() error
+	var rows int
+	writeRow := func(auditLog *domain.AuditLog) error {
+		if err := write(auditLog); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushEvery == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			if err := controller.Flush(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if format == exportFormatCSV {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		// The CSV header needs every metadata key before the first row is written
+		err = h.auditService.ExportAuditLogs(r.Context(), filter, func(metadataKeys []string) error {
+			write, flush = csvAuditLogWriter(w, metadataKeys)
+			return nil
+		}, writeRow)
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		encoder := json.NewEncoder(w)
+		write = func(auditLog *domain.AuditLog) error { return encoder.Encode(auditLog) }
+		flush = func() error { return nil }
+		err = h.auditService.StreamAuditLogs(r.Context(), filter, writeRow)
+	}
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		log.Printf("Audit export failed after %d rows: %v", rows, err)
+		if rows == 0 {
+			w.Header().Del("Content-Disposition")
+			http.Error(w, "Failed to export audit logs", http.StatusInternalServerError)
+			return
+		}
+		// Part of the stream is out; abort the connection so the client cannot mistake it
+		// for a complete export
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// csvAuditLogWriter returns a row writer that puts the header before the first row, and a
+// function that flushes buffered rows (writing the header if no row came)
+func csvAuditLogWriter(w http.ResponseWriter, metadataKeys []string) (func(*domain.AuditLog) error, func() error) {
+	writer := csv.NewWriter(w)
+	headerWritten := false
+
+	header := make([]string, 0, len(auditExportColumns)+len(metadataKeys))
+	header = append(header, auditExportColumns...)
+	for _, key := range metadataKeys {
+		header = append(header, "metadata."+key)
+	}
+
+	write := func(auditLog *domain.AuditLog) error {
+		if !headerWritten {
+			if err := writer.Write(header); err != nil {
+				return err
+			}
+			headerWritten = true
+		}
+
+		record := make([]string, 0, len(header))
+		record = append(record,
+			auditLog.ID.String(),
+			string(auditLog.EventType),
+			auditLog.EventName,
+			optionalString(auditLog.Description),
+			optionalUUID(auditLog.BatchID),
+			optionalUUID(auditLog.MessageID),
+			optionalString(auditLog.RequestID),
+			optionalString(auditLog.HTTPMethod),
+			optionalString(auditLog.Endpoint),
+			optionalInt(auditLog.StatusCode),
+			optionalInt(auditLog.DurationMs),
+			optionalInt(auditLog.MessageCount),
+			optionalInt(auditLog.SuccessCount),
+			optionalInt(auditLog.FailureCount),
+			auditLog.CreatedAt.UTC().Format(time.RFC3339Nano),
+			optionalInt64(auditLog.ChainSeq),
+			optionalString(auditLog.PrevHash),
+			optionalString(auditLog.Hash),
+		)
+		for _, key := range metadataKeys {
+			value, err := metadataCell(auditLog.Metadata[key])
+			if err != nil {
+				return err
+			}
+			record = append(record, value)
+		}
+
+		return writer.Write(record)
+	}
+
+	flush := func() error {
+		if !headerWritten {
+			if err := writer.Write(header); err != nil {
+				return err
+			}
+			headerWritten = true
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+
+	return write, flush
+}
+
+// metadataCell renders a metadata value for CSV: strings as is, anything else as JSON
+func metadataCell(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	}
+}
+
+func optionalString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func optionalUUID(value *uuid.UUID) string {
+	if value == nil {
+		return ""
+	}
+	return value.String()
+}
+
+func optionalInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+func optionalInt64(value *int64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatInt(*value, 10)
+}
@@ -4,8 +4,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -19,6 +22,9 @@ import (
 // AuditHandler handles audit log related HTTP requests
 type AuditHandler struct {
 	auditService service.AuditService
+
+	auditStream     *service.AuditStream
+	streamKeepalive time.Duration
 }
 
 // NewAuditHandler creates a new AuditHandler
@@ -28,6 +34,23 @@ func NewAuditHandler(auditService service.AuditService) *AuditHandler {
 	}
 }
 
+// SetAuditStream enables the live audit stream, sending a keepalive whenever it has been
+// idle for the given interval
+func (h *AuditHandler) SetAuditStream(auditStream *service.AuditStream, keepalive time.Duration) {
+	if keepalive <= 0 {
+		keepalive = 15 * time.Second
+	}
+	h.auditStream = auditStream
+	h.streamKeepalive = keepalive
+}
+
+// AuditLogsPageResponse is the /api/audit response when the client pages with a cursor
+type AuditLogsPageResponse struct {
+	AuditLogs []*domain.AuditLog `json:"audit_logs"`
+	// NextCursor fetches the following page when passed back as cursor; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
 // Helper function to safely encode JSON responses
 func writeJSONResponse(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -40,7 +63,7 @@ func writeJSONResponse(w http.ResponseWriter, data interface{}) {
 
 // GetAuditLogs godoc
 // @Summary Get audit logs
-// @Description Retrieve audit logs with optional filtering
+// @Description Retrieve audit logs with optional filtering, newest first. The cursor for the next page is returned in the X-Next-Cursor header. Sending cursor (empty for the first page) switches the body to an object carrying audit_logs and next_cursor; unlike offset, a cursor neither skips nor repeats entries written in between.
 // @Tags audit
 // @Accept json
 // @Produce json
@@ -51,19 +74,98 @@ func writeJSONResponse(w http.ResponseWriter, data interface{}) {
 // @Param endpoint query string false "Filter by endpoint"
 // @Param from_date query string false "Filter from date (RFC3339 format)"
 // @Param to_date query string false "Filter to date (RFC3339 format)"
+// @Param metadata.{key} query string false "Filter by a metadata value, e.g. metadata.error=timeout; JSON values match by type and objects by containment"
+// @Param search query string false "Filter by words in the event name or description"
 // @Param limit query int false "Limit number of results"
 // @Param offset query int false "Offset for pagination"
+// @Param cursor query string false "Cursor from a previous response's next_cursor; cannot be combined with offset"
 // @Success 200 {array} domain.AuditLog
+// @Success 200 {object} AuditLogsPageResponse
+// @Header 200 {string} X-Next-Cursor "Cursor for the next page, absent on the last page"
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Security ApiKeyAuth
 // @Router /audit [get]
 func (h *AuditHandler) GetAuditLogs(w http.ResponseWriter, r *http.Request) {
-	filter := &domain.AuditLogFilter{}
-
-	// Parse query parameters
 	query := r.URL.Query()
 
+	filter, err := parseAuditLogFilter(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Limit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	// Offset
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			http.Error(w, "Invalid offset parameter", http.StatusBadRequest)
+			return
+		}
+		filter.Offset = offset
+	}
+
+	// Cursor; its presence, even empty, asks for the paged response body
+	usesCursor := query.Has("cursor")
+	if token := query.Get("cursor"); token != "" {
+		if filter.Offset > 0 {
+			http.Error(w, "Use either cursor or offset, not both", http.StatusBadRequest)
+			return
+		}
+		cursor, err := domain.DecodePageCursor(token)
+		if err != nil {
+			http.Error(w, "Invalid cursor parameter", http.StatusBadRequest)
+			return
+		}
+		filter.Cursor = cursor
+	}
+
+	// Default limit if not specified
+	if filter.Limit == 0 {
+		filter.Limit = 100
+	}
+
+	auditLogs, next, err := h.auditService.GetAuditLogsPage(r.Context(), filter)
+	if err != nil {
+		http.Error(w, "Failed to get audit logs", http.StatusInternalServerError)
+		return
+	}
+
+	var nextCursor string
+	if next != nil {
+		nextCursor = next.Encode()
+		w.Header().Set("X-Next-Cursor", nextCursor)
+	}
+
+	if !usesCursor {
+		writeJSONResponse(w, auditLogs)
+		return
+	}
+
+	if auditLogs == nil {
+		auditLogs = []*domain.AuditLog{}
+	}
+	writeJSONResponse(w, AuditLogsPageResponse{
+		AuditLogs:  auditLogs,
+		NextCursor: nextCursor,
+	})
+}
+
+// parseAuditLogFilter reads the audit log filter criteria shared by the list and export
+// endpoints; the error message is meant for the client
+func parseAuditLogFilter(query url.Values) (*domain.AuditLogFilter, error) {
+	filter := &domain.AuditLogFilter{}
+
 	// Event types
 	if eventTypes := query["event_types"]; len(eventTypes) > 0 {
 		filter.EventTypes = make([]domain.AuditEventType, len(eventTypes))
@@ -76,8 +178,7 @@ func (h *AuditHandler) GetAuditLogs(w http.ResponseWriter, r *http.Request) {
 	if batchIDStr := query.Get("batch_id"); batchIDStr != "" {
 		batchID, err := uuid.Parse(batchIDStr)
 		if err != nil {
-			http.Error(w, "Invalid batch_id format", http.StatusBadRequest)
-			return
+			return nil, errors.New("Invalid batch_id format")
 		}
 		filter.BatchID = &batchID
 	}
@@ -86,8 +187,7 @@ func (h *AuditHandler) GetAuditLogs(w http.ResponseWriter, r *http.Request) {
 	if messageIDStr := query.Get("message_id"); messageIDStr != "" {
 		messageID, err := uuid.Parse(messageIDStr)
 		if err != nil {
-			http.Error(w, "Invalid message_id format", http.StatusBadRequest)
-			return
+			return nil, errors.New("Invalid message_id format")
 		}
 		filter.MessageID = &messageID
 	}
@@ -106,8 +206,7 @@ func (h *AuditHandler) GetAuditLogs(w http.ResponseWriter, r *http.Request) {
 	if fromDateStr := query.Get("from_date"); fromDateStr != "" {
 		fromDate, err := time.Parse(time.RFC3339, fromDateStr)
 		if err != nil {
-			http.Error(w, "Invalid from_date format, use RFC3339", http.StatusBadRequest)
-			return
+			return nil, errors.New("Invalid from_date format, use RFC3339")
 		}
 		filter.FromDate = &fromDate
 	}
@@ -116,44 +215,46 @@ func (h *AuditHandler) GetAuditLogs(w http.ResponseWriter, r *http.Request) {
 	if toDateStr := query.Get("to_date"); toDateStr != "" {
 		toDate, err := time.Parse(time.RFC3339, toDateStr)
 		if err != nil {
-			http.Error(w, "Invalid to_date format, use RFC3339", http.StatusBadRequest)
-			return
+			return nil, errors.New("Invalid to_date format, use RFC3339")
 		}
 		filter.ToDate = &toDate
 	}
 
-	// Limit
-	if limitStr := query.Get("limit"); limitStr != "" {
-		limit, err := strconv.Atoi(limitStr)
-		if err != nil || limit < 0 {
-			http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
-			return
+	// Metadata, one metadata.<key>=<value> parameter per key
+	for param, values := range query {
+		key, found := strings.CutPrefix(param, auditMetadataParamPrefix)
+		if !found {
+			continue
 		}
-		filter.Limit = limit
-	}
-
-	// Offset
-	if offsetStr := query.Get("offset"); offsetStr != "" {
-		offset, err := strconv.Atoi(offsetStr)
-		if err != nil || offset < 0 {
-			http.Error(w, "Invalid offset parameter", http.StatusBadRequest)
-			return
+		if key == "" || len(values) != 1 {
+			return nil, fmt.Errorf("Invalid %s filter, give one value per metadata key", param)
 		}
-		filter.Offset = offset
+		if filter.Metadata == nil {
+			filter.Metadata = make(map[string]interface{})
+		}
+		filter.Metadata[key] = parseMetadataFilterValue(values[0])
 	}
 
-	// Default limit if not specified
-	if filter.Limit == 0 {
-		filter.Limit = 100
+	// Search
+	if search := strings.TrimSpace(query.Get("search")); search != "" {
+		filter.Search = &search
 	}
 
-	auditLogs, err := h.auditService.GetAuditLogs(r.Context(), filter)
-	if err != nil {
-		http.Error(w, "Failed to get audit logs", http.StatusInternalServerError)
-		return
-	}
+	return filter, nil
+}
 
-	writeJSONResponse(w, auditLogs)
+// auditMetadataParamPrefix marks query parameters that filter on a metadata key
+const auditMetadataParamPrefix = "metadata."
+
+// parseMetadataFilterValue reads a metadata filter value as JSON when it is valid JSON, so
+// numbers, booleans and objects match their stored type, and as a plain string otherwise. A
+// quoted value such as "500" always matches a string.
+func parseMetadataFilterValue(raw string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		return raw
+	}
+	return value
 }
 
 // GetBatchAuditLogs godoc
@@ -275,9 +376,117 @@ func (h *AuditHandler) GetAuditLogStats(w http.ResponseWriter, r *http.Request)
 	writeJSONResponse(w, stats)
 }
 
+// GetAuditLogTimeSeries godoc
+// @Summary Get audit log statistics over time
+// @Description Count audit logs per event type in consecutive UTC buckets, with message success and failure counts and duration_ms percentiles per event type. Every bucket in the window is returned, empty ones included. The window defaults to the last hour for minute buckets, day for hour buckets and 30 days for day buckets, and may span at most 2000 buckets.
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Param interval query string false "Bucket width" Enums(minute, hour, day) default(hour)
+// @Param event_types query []string false "Filter by event types"
+// @Param batch_id query string false "Filter by batch ID"
+// @Param message_id query string false "Filter by message ID"
+// @Param request_id query string false "Filter by request ID"
+// @Param endpoint query string false "Filter by endpoint"
+// @Param from_date query string false "Window start (RFC3339 format)"
+// @Param to_date query string false "Window end (RFC3339 format), defaults to now"
+// @Param metadata.{key} query string false "Filter by a metadata value, e.g. metadata.error=timeout; JSON values match by type and objects by containment"
+// @Param search query string false "Filter by words in the event name or description"
+// @Success 200 {object} domain.AuditStatsTimeSeries
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /audit/stats/timeseries [get]
+func (h *AuditHandler) GetAuditLogTimeSeries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+
+	interval := domain.AuditStatsHour
+	if intervalStr := query.Get("interval"); intervalStr != "" {
+		interval = domain.AuditStatsInterval(intervalStr)
+	}
+	if interval.Duration() == 0 {
+		http.Error(w, "Invalid interval, use 'minute', 'hour' or 'day'", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := parseAuditLogFilter(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	series, err := h.auditService.GetAuditLogTimeSeries(r.Context(), filter, interval)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidStatsWindow) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error getting audit log time series: %v", err)
+		http.Error(w, "Failed to get audit log time series", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSONResponse(w, series)
+}
+
+// VerifyAuditChain godoc
+// @Summary Verify the audit hash chain
+// @Description Walk the audit log hash chain over entries created in the range and report the first broken link. Entries removed by cleanup are covered by signed checkpoints.
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Param from_date query string false "Verify from date (RFC3339 format)"
+// @Param to_date query string false "Verify to date (RFC3339 format)"
+// @Success 200 {object} domain.AuditChainVerification
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /audit/verify [get]
+func (h *AuditHandler) VerifyAuditChain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	var from, to *time.Time
+
+	if fromDateStr := query.Get("from_date"); fromDateStr != "" {
+		fromDate, err := time.Parse(time.RFC3339, fromDateStr)
+		if err != nil {
+			http.Error(w, "Invalid from_date format, use RFC3339", http.StatusBadRequest)
+			return
+		}
+		from = &fromDate
+	}
+
+	if toDateStr := query.Get("to_date"); toDateStr != "" {
+		toDate, err := time.Parse(time.RFC3339, toDateStr)
+		if err != nil {
+			http.Error(w, "Invalid to_date format, use RFC3339", http.StatusBadRequest)
+			return
+		}
+		to = &toDate
+	}
+
+	verification, err := h.auditService.VerifyAuditChain(r.Context(), from, to)
+	if err != nil {
+		log.Printf("Error verifying audit chain: %v", err)
+		http.Error(w, "Failed to verify audit chain", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSONResponse(w, verification)
+}
+
 // CleanupOldAuditLogs godoc
 // @Summary Cleanup old audit logs
-// @Description Delete audit logs older than specified days
+// @Description Delete audit logs older than specified days. With the hash chain enabled, chained entries are deleted up to the first one still within retention and a signed checkpoint is recorded for the last one deleted.
 // @Tags audit
 // @Accept json
 // @Produce json
@@ -0,0 +1,472 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+	"ims/internal/service"
+)
+
+func newTestAuditHandler(repo *repository.MockAuditRepository) *AuditHandler {
+	return NewAuditHandler(service.NewAuditService(repo))
+}
+
+func addExportTestLogs(repo *repository.MockAuditRepository) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	sent := domain.NewAuditLog(domain.EventMessageSent, "Message Sent Successfully").
+		WithMetadata("webhook_url", "https://example.com/hook").
+		Build()
+	sent.CreatedAt = start
+
+	response := domain.NewAuditLog(domain.EventWebhookResponse, "Webhook Response Received").
+		WithHTTPDetails("POST", "https://example.com/hook", 202).
+		WithMetadata("response_body", map[string]interface{}{"messageId": "abc"}).
+		Build()
+	response.CreatedAt = start.Add(time.Minute)
+
+	request := domain.NewAuditLog(domain.EventAPIRequest, "API Request Processed").Build()
+	request.Metadata = nil
+	request.CreatedAt = start.Add(2 * time.Minute)
+
+	// Added out of order to check the export is oldest first
+	repo.AddLog(request)
+	repo.AddLog(sent)
+	repo.AddLog(response)
+}
+
+func TestAuditHandler_GetAuditLogs_Cursor(t *testing.T) {
+	repo := repository.NewMockAuditRepository()
+	addExportTestLogs(repo)
+	handler := newTestAuditHandler(repo)
+
+	// Without cursor the body stays an array and the next cursor comes in a header
+	req := httptest.NewRequest(http.MethodGet, "/api/audit?limit=2", nil)
+	rr := httptest.NewRecorder()
+	handler.GetAuditLogs(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	var legacy []domain.AuditLog
+	if err := json.Unmarshal(rr.Body.Bytes(), &legacy); err != nil {
+		t.Fatalf("Expected an array body: %v", err)
+	}
+	if len(legacy) != 2 || legacy[0].EventType != domain.EventAPIRequest {
+		t.Fatalf("Expected the 2 newest entries, got %d", len(legacy))
+	}
+	if rr.Header().Get("X-Next-Cursor") == "" {
+		t.Fatal("Expected an X-Next-Cursor header")
+	}
+
+	// With cursor the pages come in an envelope until next_cursor runs out
+	var eventTypes []domain.AuditEventType
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("Cursor pagination did not end")
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/api/audit?limit=2&cursor="+cursor, nil)
+		rr := httptest.NewRecorder()
+		handler.GetAuditLogs(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		var page struct {
+			AuditLogs  []domain.AuditLog `json:"audit_logs"`
+			NextCursor string            `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
+			t.Fatalf("Expected a paged body: %v", err)
+		}
+		for _, entry := range page.AuditLogs {
+			eventTypes = append(eventTypes, entry.EventType)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	expected := []domain.AuditEventType{domain.EventAPIRequest, domain.EventWebhookResponse, domain.EventMessageSent}
+	if len(eventTypes) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, eventTypes)
+	}
+	for i := range expected {
+		if eventTypes[i] != expected[i] {
+			t.Errorf("Expected %v newest first, got %v", expected, eventTypes)
+			break
+		}
+	}
+}
+
+func TestAuditHandler_GetAuditLogs_BadCursor(t *testing.T) {
+	handler := newTestAuditHandler(repository.NewMockAuditRepository())
+
+	for _, target := range []string{
+		"/api/audit?cursor=not-a-cursor",
+		"/api/audit?offset=10&cursor=" + domain.NewPageCursor(time.Now(), uuid.New()).Encode(),
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rr := httptest.NewRecorder()
+
+		handler.GetAuditLogs(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusBadRequest, target, rr.Code)
+		}
+	}
+}
+
+func TestAuditHandler_GetAuditLogs_MetadataAndSearch(t *testing.T) {
+	repo := repository.NewMockAuditRepository()
+	addExportTestLogs(repo)
+	repo.AddLog(domain.NewAuditLog(domain.EventMessageFailed, "Message Send Failed").
+		WithDescription("Webhook timed out").
+		WithMetadata("error", "context deadline exceeded").
+		WithMetadata("attempt", 3).
+		Build())
+	handler := newTestAuditHandler(repo)
+
+	tests := []struct {
+		query    string
+		expected []domain.AuditEventType
+	}{
+		{"metadata.webhook_url=https://example.com/hook", []domain.AuditEventType{domain.EventMessageSent}},
+		{"metadata.error=context+deadline+exceeded", []domain.AuditEventType{domain.EventMessageFailed}},
+		{"metadata.attempt=3", []domain.AuditEventType{domain.EventMessageFailed}},
+		{`metadata.attempt="3"`, nil},
+		{`metadata.response_body={"messageId":"abc"}`, []domain.AuditEventType{domain.EventWebhookResponse}},
+		{"search=webhook+received", []domain.AuditEventType{domain.EventWebhookResponse}},
+		{"search=timed&metadata.error=context+deadline+exceeded", []domain.AuditEventType{domain.EventMessageFailed}},
+		{"metadata.error=unknown", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/audit?"+strings.ReplaceAll(tt.query, `"`, "%22"), nil)
+			rr := httptest.NewRecorder()
+
+			handler.GetAuditLogs(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+			}
+			var entries []domain.AuditLog
+			if err := json.Unmarshal(rr.Body.Bytes(), &entries); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if len(entries) != len(tt.expected) {
+				t.Fatalf("Expected %d entries, got %d", len(tt.expected), len(entries))
+			}
+			for i, entry := range entries {
+				if entry.EventType != tt.expected[i] {
+					t.Errorf("Expected %s, got %s", tt.expected[i], entry.EventType)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditHandler_GetAuditLogs_BadMetadataFilter(t *testing.T) {
+	handler := newTestAuditHandler(repository.NewMockAuditRepository())
+
+	for _, target := range []string{
+		"/api/audit?metadata.=value",
+		"/api/audit?metadata.error=a&metadata.error=b",
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rr := httptest.NewRecorder()
+
+		handler.GetAuditLogs(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusBadRequest, target, rr.Code)
+		}
+	}
+}
+
+func TestAuditHandler_GetAuditLogTimeSeries_BadRequest(t *testing.T) {
+	handler := newTestAuditHandler(repository.NewMockAuditRepository())
+
+	for _, target := range []string{
+		"/api/audit/stats/timeseries?interval=week",
+		"/api/audit/stats/timeseries?interval=minute&from_date=2024-01-01T00:00:00Z&to_date=2024-02-01T00:00:00Z",
+		"/api/audit/stats/timeseries?from_date=2024-02-01T00:00:00Z&to_date=2024-01-01T00:00:00Z",
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rr := httptest.NewRecorder()
+
+		handler.GetAuditLogTimeSeries(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusBadRequest, target, rr.Code)
+		}
+	}
+}
+
+func TestAuditHandler_ExportAuditLogs_CSV(t *testing.T) {
+	repo := repository.NewMockAuditRepository()
+	addExportTestLogs(repo)
+	handler := newTestAuditHandler(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/audit/export?format=csv", nil)
+	rr := httptest.NewRecorder()
+
+	handler.ExportAuditLogs(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if contentType := rr.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/csv") {
+		t.Errorf("Expected CSV content type, got %s", contentType)
+	}
+
+	records, err := csv.NewReader(rr.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("Expected a header and 3 rows, got %d records", len(records))
+	}
+
+	header := records[0]
+	column := make(map[string]int, len(header))
+	for i, name := range header {
+		column[name] = i
+	}
+	for _, name := range []string{"event_type", "status_code", "metadata.response_body", "metadata.webhook_url"} {
+		if _, ok := column[name]; !ok {
+			t.Fatalf("Expected column %s in header %v", name, header)
+		}
+	}
+
+	if records[1][column["event_type"]] != string(domain.EventMessageSent) {
+		t.Errorf("Expected the oldest entry first, got %s", records[1][column["event_type"]])
+	}
+	if records[1][column["metadata.webhook_url"]] != "https://example.com/hook" {
+		t.Errorf("Expected the webhook URL as is, got %q", records[1][column["metadata.webhook_url"]])
+	}
+	if records[2][column["metadata.response_body"]] != `{"messageId":"abc"}` {
+		t.Errorf("Expected nested metadata as JSON, got %q", records[2][column["metadata.response_body"]])
+	}
+	if records[2][column["status_code"]] != "202" {
+		t.Errorf("Expected status code 202, got %q", records[2][column["status_code"]])
+	}
+	if records[3][column["metadata.webhook_url"]] != "" {
+		t.Errorf("Expected an empty cell for missing metadata, got %q", records[3][column["metadata.webhook_url"]])
+	}
+}
+
+func TestAuditHandler_ExportAuditLogs_NDJSON(t *testing.T) {
+	repo := repository.NewMockAuditRepository()
+	addExportTestLogs(repo)
+	handler := newTestAuditHandler(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/audit/export?event_types=webhook_response&event_types=api_request", nil)
+	rr := httptest.NewRecorder()
+
+	handler.ExportAuditLogs(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if contentType := rr.Header().Get("Content-Type"); contentType != "application/x-ndjson" {
+		t.Errorf("Expected NDJSON content type, got %s", contentType)
+	}
+
+	var eventTypes []domain.AuditEventType
+	scanner := bufio.NewScanner(rr.Body)
+	for scanner.Scan() {
+		var entry domain.AuditLog
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Failed to parse line %q: %v", scanner.Text(), err)
+		}
+		eventTypes = append(eventTypes, entry.EventType)
+	}
+
+	if len(eventTypes) != 2 || eventTypes[0] != domain.EventWebhookResponse || eventTypes[1] != domain.EventAPIRequest {
+		t.Errorf("Expected the filtered entries oldest first, got %v", eventTypes)
+	}
+}
+
+func TestAuditHandler_ExportAuditLogs_BadRequest(t *testing.T) {
+	handler := newTestAuditHandler(repository.NewMockAuditRepository())
+
+	for _, target := range []string{
+		"/api/audit/export?format=xml",
+		"/api/audit/export?from_date=yesterday",
+		"/api/audit/export?batch_id=not-a-uuid",
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rr := httptest.NewRecorder()
+
+		handler.ExportAuditLogs(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusBadRequest, target, rr.Code)
+		}
+	}
+}
+
+func TestAuditHandler_ExportAuditLogs_CSVError(t *testing.T) {
+	repo := repository.NewMockAuditRepository()
+	repo.ExportAuditLogsFunc = func(ctx context.Context, filter *domain.AuditLogFilter, header func([]string) error, fn func(*domain.AuditLog) error) error {
+		return errors.New("database unavailable")
+	}
+
+	rr := httptest.NewRecorder()
+	newTestAuditHandler(repo).ExportAuditLogs(rr, httptest.NewRequest(http.MethodGet, "/api/audit/export?format=csv", nil))
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+	if rr.Header().Get("Content-Disposition") != "" {
+		t.Error("Expected no attachment for a failed export")
+	}
+}
+
+func TestAuditHandler_StreamAuditLogs(t *testing.T) {
+	repo := repository.NewMockAuditRepository()
+	addExportTestLogs(repo)
+	listener := repository.NewMockAuditLogListener()
+	stream := service.NewAuditStream(repo, listener)
+	stream.Start()
+	defer stream.Stop()
+
+	handler := newTestAuditHandler(repo)
+	handler.SetAuditStream(stream, 50*time.Millisecond)
+	server := httptest.NewServer(http.HandlerFunc(handler.StreamAuditLogs))
+	defer server.Close()
+
+	// Resume after the oldest entry: the other two are replayed in creation order
+	oldest, _ := repo.GetAuditLogs(context.Background(), &domain.AuditLogFilter{EventTypes: []domain.AuditEventType{domain.EventMessageSent}})
+	resumeFrom := domain.NewPageCursor(oldest[0].CreatedAt, oldest[0].ID).Encode()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	req.Header.Set("Last-Event-ID", resumeFrom)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected text/event-stream, got %s", ct)
+	}
+
+	events := make(chan []string)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		var event []string
+		for scanner.Scan() {
+			if scanner.Text() != "" {
+				event = append(event, scanner.Text())
+				continue
+			}
+			events <- event
+			event = nil
+		}
+	}()
+	next := func() []string {
+		t.Helper()
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatal("Stream ended early")
+			}
+			return event
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for an event")
+			return nil
+		}
+	}
+	eventType := func(event []string) domain.AuditEventType {
+		t.Helper()
+		if len(event) != 2 || !strings.HasPrefix(event[0], "id: ") || !strings.HasPrefix(event[1], "data: ") {
+			t.Fatalf("Expected an id and data event, got %q", event)
+		}
+		var auditLog domain.AuditLog
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(event[1], "data: ")), &auditLog); err != nil {
+			t.Fatalf("Failed to decode event data: %v", err)
+		}
+		if _, err := domain.DecodePageCursor(strings.TrimPrefix(event[0], "id: ")); err != nil {
+			t.Errorf("Expected the event ID to be a cursor, got %v", err)
+		}
+		return auditLog.EventType
+	}
+
+	if got := eventType(next()); got != domain.EventWebhookResponse {
+		t.Errorf("Expected webhook_response replayed first, got %s", got)
+	}
+	if got := eventType(next()); got != domain.EventAPIRequest {
+		t.Errorf("Expected api_request replayed second, got %s", got)
+	}
+
+	// Then the idle stream keeps alive until a live entry arrives
+	if event := next(); len(event) != 1 || event[0] != ": keepalive" {
+		t.Errorf("Expected a keepalive, got %q", event)
+	}
+
+	live := domain.NewAuditLog(domain.EventBatchStarted, "Batch Started").Build()
+	repo.AddLog(live)
+	listener.Notify(live)
+
+	for {
+		event := next()
+		if len(event) == 1 {
+			continue
+		}
+		if got := eventType(event); got != domain.EventBatchStarted {
+			t.Errorf("Expected the live batch_started entry, got %s", got)
+		}
+		break
+	}
+}
+
+func TestAuditHandler_StreamAuditLogs_BadRequest(t *testing.T) {
+	repo := repository.NewMockAuditRepository()
+
+	// Not configured
+	rr := httptest.NewRecorder()
+	newTestAuditHandler(repo).StreamAuditLogs(rr, httptest.NewRequest(http.MethodGet, "/api/audit/stream", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d without a stream, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+
+	handler := newTestAuditHandler(repo)
+	handler.SetAuditStream(service.NewAuditStream(repo, repository.NewMockAuditLogListener()), time.Second)
+
+	for _, target := range []string{
+		"/api/audit/stream?search=timeout",
+		"/api/audit/stream?from_date=2024-01-01T00:00:00Z",
+		"/api/audit/stream?batch_id=not-a-uuid",
+		"/api/audit/stream?last_event_id=bogus",
+	} {
+		rr := httptest.NewRecorder()
+		handler.StreamAuditLogs(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", target, http.StatusBadRequest, rr.Code)
+		}
+	}
+}
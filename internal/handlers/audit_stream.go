@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ims/internal/domain"
+)
+
+// StreamAuditLogs godoc
+// @Summary Stream audit logs live
+// @Description Push audit logs as Server-Sent Events as soon as any replica stores them. Each event carries one audit log as JSON. Send an event's ID back in the Last-Event-ID header, or in last_event_id where headers cannot be set, to resume after it; entries stored since then are replayed first, in creation order. A keepalive comment is sent while nothing else is. Only the event type, batch, message, request and endpoint filters apply. A client that falls too far behind is disconnected and should resume.
+// @Tags audit
+// @Produce text/event-stream
+// @Param event_types query []string false "Filter by event types"
+// @Param batch_id query string false "Filter by batch ID"
+// @Param message_id query string false "Filter by message ID"
+// @Param request_id query string false "Filter by request ID"
+// @Param endpoint query string false "Filter by endpoint"
+// @Param Last-Event-ID header string false "ID of the last event received, to resume after it"
+// @Param last_event_id query string false "Same as the Last-Event-ID header"
+// @Success 200 {string} string "Server-Sent Events stream"
+// @Failure 400 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /audit/stream [get]
+func (h *AuditHandler) StreamAuditLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.auditStream == nil {
+		http.Error(w, "Live audit stream is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := r.URL.Query()
+
+	filter, err := parseAuditLogFilter(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.FromDate != nil || filter.ToDate != nil || filter.Metadata != nil || filter.Search != nil {
+		http.Error(w, "Only event_types, batch_id, message_id, request_id and endpoint filter the live stream", http.StatusBadRequest)
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = query.Get("last_event_id")
+	}
+	var resumeAfter *domain.PageCursor
+	if lastEventID != "" {
+		resumeAfter, err = domain.DecodePageCursor(lastEventID)
+		if err != nil {
+			http.Error(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Subscribe before replaying so nothing stored in between is missed
+	subscription, err := h.auditStream.Subscribe(filter)
+	if err != nil {
+		http.Error(w, "Live audit stream is not available", http.StatusServiceUnavailable)
+		return
+	}
+	defer h.auditStream.Unsubscribe(subscription)
+
+	// The stream outlasts the server's write timeout
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("Could not lift write deadline for audit stream: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Entries replayed may also arrive live; they are sent once
+	replayed := make(map[uuid.UUID]bool)
+	if resumeAfter != nil {
+		replayFilter := *filter
+		replayFilter.FromDate = &resumeAfter.Time
+		err := h.auditService.StreamAuditLogs(r.Context(), &replayFilter, func(auditLog *domain.AuditLog) error {
+			if !resumeAfter.Precedes(auditLog.CreatedAt, auditLog.ID) {
+				return nil
+			}
+			replayed[auditLog.ID] = true
+			return writeAuditLogEvent(w, auditLog)
+		})
+		if err != nil {
+			log.Printf("Audit stream replay failed: %v", err)
+			return
+		}
+	}
+	if err := controller.Flush(); err != nil {
+		return
+	}
+
+	keepalive := time.NewTicker(h.streamKeepalive)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case auditLog, ok := <-subscription.Events():
+			// Dropped for falling behind, or shutting down; the client resumes on reconnect
+			if !ok {
+				return
+			}
+			if replayed[auditLog.ID] {
+				continue
+			}
+			if err := writeAuditLogEvent(w, auditLog); err != nil {
+				return
+			}
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+		keepalive.Reset(h.streamKeepalive)
+	}
+}
+
+// writeAuditLogEvent writes an entry as a Server-Sent Event whose ID resumes the stream after it
+func writeAuditLogEvent(w http.ResponseWriter, auditLog *domain.AuditLog) error {
+	data, err := json.Marshal(auditLog)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\ndata: %s\n\n", domain.NewPageCursor(auditLog.CreatedAt, auditLog.ID).Encode(), data)
+	return err
+}
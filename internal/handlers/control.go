@@ -5,9 +5,12 @@ package handlers
 import (
 	"encoding/json"
 	"log"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 
+	"ims/internal/domain"
 	"ims/internal/scheduler"
 )
 
@@ -21,7 +24,12 @@ func NewControlHandler(scheduler *scheduler.Scheduler) *ControlHandler {
 
 // ControlRequest represents a scheduler control request
 type ControlRequest struct {
-	Action string `json:"action" example:"start" enums:"start,stop"` // "start" or "stop"
+	Action      string `json:"action" example:"start" enums:"start,stop,configure"` // "start", "stop" or "configure"
+	RequestedBy string `json:"requested_by,omitempty" example:"ops@example.com"`    // recorded as who changed the state
+
+	// Settings for the "configure" action; omitted values keep their current setting
+	Interval  string `json:"interval,omitempty" example:"30s"`
+	BatchSize int    `json:"batch_size,omitempty" example:"50"`
 }
 
 // ControlResponse represents a scheduler control response
@@ -31,23 +39,28 @@ type ControlResponse struct {
 	Status  struct {
 		Running   bool       `json:"running" example:"true"`
 		StartedAt *time.Time `json:"started_at,omitempty" example:"2023-12-01T10:00:00Z"`
+		Interval  string     `json:"interval" example:"2m0s"`
+		BatchSize int        `json:"batch_size" example:"2"`
+		// Desired is the persisted state all replicas converge on
+		Desired *domain.SchedulerDesiredState `json:"desired,omitempty"`
 	} `json:"status"`
 }
 
 // ErrorResponse represents an error response
 type ErrorResponse struct {
-	Error string `json:"error" example:"Invalid action. Use 'start' or 'stop'"`
+	Error string `json:"error" example:"Invalid action. Use 'start', 'stop' or 'configure'"`
 }
 
 // Handle handles scheduler control requests
 // @Summary      Control Scheduler
-// @Description  Start or stop the message scheduler
+// @Description  Start or stop the message scheduler, or change its interval and batch size at runtime with the "configure" action. The desired state is persisted, survives restarts and is applied by every replica.
 // @Tags         scheduler
 // @Accept       json
 // @Produce      json
 // @Param        request   body      ControlRequest  true  "Control action"
 // @Success      200       {object}  ControlResponse
 // @Failure      400       {object}  ErrorResponse
+// @Failure      500       {string}  string  "Failed to persist scheduler state"
 // @Security     ApiKeyAuth
 // @Router       /control [post]
 func (h *ControlHandler) Handle(w http.ResponseWriter, r *http.Request) {
@@ -62,8 +75,54 @@ func (h *ControlHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	interval, batchSize := h.scheduler.Settings()
+
+	switch req.Action {
+	case "start", "stop":
+	case "configure":
+		if req.Interval == "" && req.BatchSize == 0 {
+			http.Error(w, "Provide an interval, a batch_size or both", http.StatusBadRequest)
+			return
+		}
+		if req.Interval != "" {
+			parsed, err := time.ParseDuration(req.Interval)
+			if err != nil {
+				http.Error(w, "Invalid interval: use a duration such as '30s' or '2m'", http.StatusBadRequest)
+				return
+			}
+			interval = parsed
+		}
+		if req.BatchSize != 0 {
+			batchSize = req.BatchSize
+		}
+		if err := h.scheduler.ValidateSettings(interval, batchSize); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "Invalid action. Use 'start', 'stop' or 'configure'", http.StatusBadRequest)
+		return
+	}
+
 	var resp ControlResponse
 
+	// Persist the desired state first so the other replicas and later restarts follow it
+	if stateSync := h.scheduler.StateSync(); stateSync != nil {
+		var desired *domain.SchedulerDesiredState
+		var err error
+		if req.Action == "configure" {
+			desired, err = stateSync.SetSettings(r.Context(), interval, batchSize, changedBy(r, req.RequestedBy))
+		} else {
+			desired, err = stateSync.SetDesired(r.Context(), req.Action == "start", changedBy(r, req.RequestedBy))
+		}
+		if err != nil {
+			log.Printf("Error persisting scheduler state: %v", err)
+			http.Error(w, "Failed to persist scheduler state", http.StatusInternalServerError)
+			return
+		}
+		resp.Status.Desired = desired
+	}
+
 	switch req.Action {
 	case "start":
 		if err := h.scheduler.Start(r.Context()); err != nil {
@@ -74,21 +133,29 @@ func (h *ControlHandler) Handle(w http.ResponseWriter, r *http.Request) {
 			resp.Message = "Scheduler started successfully"
 		}
 	case "stop":
-		if err := h.scheduler.Stop(); err != nil {
+		if err := h.scheduler.Stop(r.Context()); err != nil {
 			resp.Success = false
 			resp.Message = err.Error()
 		} else {
 			resp.Success = true
 			resp.Message = "Scheduler stopped successfully"
 		}
-	default:
-		http.Error(w, "Invalid action. Use 'start' or 'stop'", http.StatusBadRequest)
-		return
+	case "configure":
+		if err := h.scheduler.Reconfigure(r.Context(), interval, batchSize); err != nil {
+			resp.Success = false
+			resp.Message = err.Error()
+		} else {
+			resp.Success = true
+			resp.Message = "Scheduler reconfigured successfully"
+		}
 	}
 
 	running, startedAt := h.scheduler.GetStatus()
 	resp.Status.Running = running
 	resp.Status.StartedAt = startedAt
+	currentInterval, currentBatchSize := h.scheduler.Settings()
+	resp.Status.Interval = currentInterval.String()
+	resp.Status.BatchSize = currentBatchSize
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
@@ -97,3 +164,57 @@ func (h *ControlHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// BatchHistoryResponse represents the latest batch results
+type BatchHistoryResponse struct {
+	Batches []*domain.BatchResult `json:"batches"`
+	Count   int                   `json:"count" example:"20"`
+}
+
+// GetRecentBatches returns the latest batch results of all replicas
+// @Summary      Recent Batches
+// @Description  Retrieve the latest batch results, newest first, with per-message outcomes and timings. Results are stored, so every replica reports the batches run by the leader and the history survives restarts and leadership changes.
+// @Tags         scheduler
+// @Accept       json
+// @Produce      json
+// @Param        limit     query     int  false  "Number of batches (default: 20, max: 100)"  minimum(1)  maximum(100)
+// @Success      200       {object}  BatchHistoryResponse
+// @Failure      500       {string}  string  "Failed to retrieve recent batches"
+// @Security     ApiKeyAuth
+// @Router       /batches [get]
+func (h *ControlHandler) GetRecentBatches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > scheduler.BatchHistorySize {
+		limit = 20
+	}
+
+	batches, err := h.scheduler.RecentBatches(r.Context(), limit)
+	if err != nil {
+		log.Printf("Error retrieving recent batches: %v", err)
+		http.Error(w, "Failed to retrieve recent batches", http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, BatchHistoryResponse{
+		Batches: batches,
+		Count:   len(batches),
+	})
+}
+
+// changedBy identifies who issued a control request: the caller-supplied name if any,
+// otherwise the client address
+func changedBy(r *http.Request, requestedBy string) string {
+	if requestedBy != "" {
+		return requestedBy
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "api@" + host
+}
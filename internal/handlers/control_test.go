@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+	"ims/internal/scheduler"
+)
+
+// newTestControlHandler returns a handler over a stopped scheduler running every minute
+// with batches of 10 and a 5m sending lease
+func newTestControlHandler(stateRepo *repository.MockSchedulerStateRepository) (*ControlHandler, *scheduler.Scheduler) {
+	sched := scheduler.NewScheduler(nil, nil, time.Minute, 10)
+	sched.SetSendingLease(5 * time.Minute)
+	sched.SetStateSync(scheduler.NewStateSync(sched, stateRepo, time.Second))
+	return NewControlHandler(sched), sched
+}
+
+func TestControlHandler_Configure_Success(t *testing.T) {
+	stateRepo := repository.NewMockSchedulerStateRepository()
+	handler, sched := newTestControlHandler(stateRepo)
+
+	body := `{"action": "configure", "interval": "2m", "batch_size": 50, "requested_by": "ops@example.com"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/control", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	handler.Handle(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var resp ControlResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if !resp.Success || resp.Status.Interval != "2m0s" || resp.Status.BatchSize != 50 {
+		t.Errorf("Expected a successful change to 2m0s and 50, got %+v", resp)
+	}
+	if interval, batchSize := sched.Settings(); interval != 2*time.Minute || batchSize != 50 {
+		t.Errorf("Expected the scheduler to use 2m and 50, got %v and %d", interval, batchSize)
+	}
+
+	stored, _ := stateRepo.GetDesiredState(context.Background())
+	if stored == nil || stored.IntervalMs != (2*time.Minute).Milliseconds() || stored.BatchSize != 50 || stored.ChangedBy != "ops@example.com" {
+		t.Errorf("Expected the settings persisted by ops@example.com, got %+v", stored)
+	}
+	if stored != nil && stored.Running {
+		t.Error("Expected configure not to record the scheduler as running")
+	}
+}
+
+func TestControlHandler_Configure_KeepsOmittedSetting(t *testing.T) {
+	handler, sched := newTestControlHandler(repository.NewMockSchedulerStateRepository())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/control", strings.NewReader(`{"action": "configure", "batch_size": 25}`))
+	rr := httptest.NewRecorder()
+
+	handler.Handle(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if interval, batchSize := sched.Settings(); interval != time.Minute || batchSize != 25 {
+		t.Errorf("Expected 1m and 25, got %v and %d", interval, batchSize)
+	}
+}
+
+func TestControlHandler_Configure_BadRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"no settings", `{"action": "configure"}`},
+		{"bad duration", `{"action": "configure", "interval": "soon"}`},
+		{"interval below minimum", `{"action": "configure", "interval": "500ms"}`},
+		{"interval above maximum", `{"action": "configure", "interval": "25h"}`},
+		{"batch size above maximum", `{"action": "configure", "batch_size": 1001}`},
+		{"negative batch size", `{"action": "configure", "batch_size": -1}`},
+		// Batches of 5m would run as long as the sending lease
+		{"batch timeout at the sending lease", `{"action": "configure", "interval": "10m"}`},
+		{"batch timeout above the sending lease", `{"action": "configure", "interval": "1h"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stateRepo := repository.NewMockSchedulerStateRepository()
+			handler, sched := newTestControlHandler(stateRepo)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/control", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			handler.Handle(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rr.Code)
+			}
+			if interval, batchSize := sched.Settings(); interval != time.Minute || batchSize != 10 {
+				t.Errorf("Expected the settings to stay at 1m and 10, got %v and %d", interval, batchSize)
+			}
+			if stored, _ := stateRepo.GetDesiredState(context.Background()); stored != nil {
+				t.Errorf("Expected nothing persisted, got %+v", stored)
+			}
+		})
+	}
+}
+
+func TestControlHandler_GetRecentBatches(t *testing.T) {
+	batchResults := repository.NewMockBatchResultRepository()
+	for i := 0; i < 3; i++ {
+		batchResults.SaveBatchResult(context.Background(), domain.NewBatchResult(time.Now(), nil), scheduler.BatchHistorySize)
+	}
+
+	// This replica ran none of the batches itself
+	handler, sched := newTestControlHandler(repository.NewMockSchedulerStateRepository())
+	sched.SetBatchResultRepository(batchResults)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/batches?limit=2", nil)
+	rr := httptest.NewRecorder()
+
+	handler.GetRecentBatches(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var resp BatchHistoryResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if resp.Count != 2 || len(resp.Batches) != 2 {
+		t.Errorf("Expected 2 stored batches, got %d", resp.Count)
+	}
+}
+
+func TestControlHandler_GetRecentBatches_StoreError(t *testing.T) {
+	batchResults := repository.NewMockBatchResultRepository()
+	batchResults.GetRecentBatchResultsFunc = func(ctx context.Context, limit int) ([]*domain.BatchResult, error) {
+		return nil, errors.New("database unavailable")
+	}
+	handler, sched := newTestControlHandler(repository.NewMockSchedulerStateRepository())
+	sched.SetBatchResultRepository(batchResults)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/batches", nil)
+	rr := httptest.NewRecorder()
+
+	handler.GetRecentBatches(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+}
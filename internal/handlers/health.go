@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"ims/internal/scheduler"
+	"ims/internal/service"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -26,9 +27,12 @@ const (
 )
 
 type HealthHandler struct {
-	db        *sql.DB
-	redis     *redis.Client
-	scheduler *scheduler.Scheduler
+	db          *sql.DB
+	redis       *redis.Client
+	scheduler   *scheduler.Scheduler
+	auditWriter *service.AuditWriter
+	auditSpool  *service.AuditSpool
+	auditSinks  *service.AuditSinks
 }
 
 func NewHealthHandler(db *sql.DB, redis *redis.Client, scheduler *scheduler.Scheduler) *HealthHandler {
@@ -39,19 +43,37 @@ func NewHealthHandler(db *sql.DB, redis *redis.Client, scheduler *scheduler.Sche
 	}
 }
 
+// SetAuditWriter adds the audit queue state to health responses
+func (h *HealthHandler) SetAuditWriter(auditWriter *service.AuditWriter) {
+	h.auditWriter = auditWriter
+}
+
+// SetAuditSpool adds the number of spooled audit entries to health responses
+func (h *HealthHandler) SetAuditSpool(auditSpool *service.AuditSpool) {
+	h.auditSpool = auditSpool
+}
+
+// SetAuditSinks adds the delivery state of each audit sink to health responses
+func (h *HealthHandler) SetAuditSinks(auditSinks *service.AuditSinks) {
+	h.auditSinks = auditSinks
+}
+
 // HealthResponse represents the health check response
 type HealthResponse struct {
-	Status    string                 `json:"status" example:"healthy"`
-	Timestamp time.Time              `json:"timestamp" example:"2023-12-01T10:00:00Z"`
-	Scheduler map[string]interface{} `json:"scheduler"`
-	Database  string                 `json:"database" example:"connected"`
-	Redis     string                 `json:"redis" example:"connected"`
-	Errors    []string               `json:"errors,omitempty"`
+	Status     string                    `json:"status" example:"healthy"`
+	Timestamp  time.Time                 `json:"timestamp" example:"2023-12-01T10:00:00Z"`
+	Scheduler  map[string]interface{}    `json:"scheduler"`
+	Database   string                    `json:"database" example:"connected"`
+	Redis      string                    `json:"redis" example:"connected"`
+	Audit      *service.AuditWriterStats `json:"audit,omitempty"`
+	AuditSpool *service.AuditSpoolStats  `json:"audit_spool,omitempty"`
+	AuditSinks []service.AuditSinkStats  `json:"audit_sinks,omitempty"`
+	Errors     []string                  `json:"errors,omitempty"`
 }
 
 // Handle handles health check requests
 // @Summary      Health Check
-// @Description  Check the health status of the service including database, Redis, and scheduler
+// @Description  Check the health status of the service including database, Redis, scheduler (with the current scheduler leader) and the audit queue
 // @Tags         health
 // @Accept       json
 // @Produce      json
@@ -72,12 +94,27 @@ func (h *HealthHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	// Check scheduler status
 	if h.scheduler != nil {
 		running, startedAt := h.scheduler.GetStatus()
+		interval, batchSize := h.scheduler.Settings()
 		response.Scheduler = map[string]interface{}{
-			"running": running,
+			"running":    running,
+			"interval":   interval.String(),
+			"batch_size": batchSize,
 		}
 		if startedAt != nil {
 			response.Scheduler["started_at"] = startedAt
 		}
+
+		// Report which replica holds scheduler leadership
+		if elector := h.scheduler.LeaderElector(); elector != nil {
+			response.Scheduler["instance_id"] = elector.InstanceID()
+			response.Scheduler["is_leader"] = elector.IsLeader()
+			lease, err := elector.Leader(r.Context())
+			if err != nil {
+				log.Printf("Failed to get scheduler leader: %v", err)
+			} else if lease != nil {
+				response.Scheduler["leader"] = lease
+			}
+		}
 	} else {
 		response.Scheduler = map[string]interface{}{
 			"running": false,
@@ -110,6 +147,20 @@ func (h *HealthHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		response.Redis = HealthStatusNotConfigured
 	}
 
+	if h.auditWriter != nil {
+		stats := h.auditWriter.Stats()
+		response.Audit = &stats
+	}
+
+	if h.auditSpool != nil {
+		stats := h.auditSpool.Stats()
+		response.AuditSpool = &stats
+	}
+
+	if h.auditSinks != nil {
+		response.AuditSinks = h.auditSinks.Stats()
+	}
+
 	statusCode := http.StatusOK
 	if response.Status == HealthStatusUnhealthy {
 		statusCode = http.StatusServiceUnavailable
@@ -4,9 +4,16 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
 
 	"ims/internal/domain"
 	"ims/internal/service"
@@ -25,17 +32,294 @@ type SentMessagesResponse struct {
 	Messages []*domain.SentMessageResponse `json:"messages"`
 	Page     int                           `json:"page" example:"1"`
 	PageSize int                           `json:"page_size" example:"20"`
+	// NextCursor fetches the following page when passed back as cursor; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty" example:"MjAyMy0xMi0wMVQxMDowNTowMFp8MTIzZTQ1NjctZTg5Yi0xMmQzLWE0NTYtNDI2NjE0MTc0MDAw"`
+}
+
+// CreateMessageRequest represents a request to enqueue a new message
+type CreateMessageRequest struct {
+	PhoneNumber    string `json:"phone_number" example:"+1234567890"`
+	Content        string `json:"content" example:"Hello, this is a test message"`
+	IdempotencyKey string `json:"idempotency_key,omitempty" example:"order-42-sms"`
+}
+
+// IdempotencyKeyHeader carries the producer-supplied idempotency key for message creation
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength matches the width of the messages.idempotency_key column
+const maxIdempotencyKeyLength = 255
+
+// CreateMessage enqueues a new message for sending
+// @Summary      Create Message
+// @Description  Validate and enqueue a new message; it is sent by the scheduler on a later batch.
+// @Description  Retries carrying the same idempotency key return the original message instead of creating a duplicate.
+// @Tags         messages
+// @Accept       json
+// @Produce      json
+// @Param        Idempotency-Key  header    string                false  "Idempotency key (overrides the idempotency_key field)"
+// @Param        request          body      CreateMessageRequest  true   "Message to enqueue"
+// @Success      200       {object}  domain.Message  "Replayed: the key was already used with the same body"
+// @Success      201       {object}  domain.Message
+// @Failure      400       {object}  ErrorResponse
+// @Failure      409       {object}  ErrorResponse  "The key was already used with a different body"
+// @Failure      500       {object}  ErrorResponse
+// @Security     ApiKeyAuth
+// @Router       /messages [post]
+func (h *MessageHandler) CreateMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req CreateMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	idempotencyKey := r.Header.Get(IdempotencyKeyHeader)
+	if idempotencyKey == "" {
+		idempotencyKey = req.IdempotencyKey
+	}
+	if utf8.RuneCountInString(idempotencyKey) > maxIdempotencyKeyLength {
+		http.Error(w, fmt.Sprintf("Idempotency key must be at most %d characters", maxIdempotencyKeyLength), http.StatusBadRequest)
+		return
+	}
+
+	msg, replayed, err := h.service.CreateMessageWithKey(r.Context(), idempotencyKey, req.PhoneNumber, req.Content)
+	if err != nil {
+		if isValidationError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, domain.ErrIdempotencyKeyConflict) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Printf("Failed to create message: %v", err)
+		http.Error(w, "Failed to create message", http.StatusInternalServerError)
+		return
+	}
+
+	statusCode := http.StatusCreated
+	if replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+		statusCode = http.StatusOK
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(msg); err != nil {
+		log.Printf("Error encoding JSON response: %v", err)
+	}
+}
+
+// maxBulkMessages caps the number of items accepted by a single bulk request
+const maxBulkMessages = 10000
+
+// BulkCreateMessagesResponse reports the per-item outcome of a bulk enqueue request
+type BulkCreateMessagesResponse struct {
+	Created int `json:"created" example:"2"`
+	// Failed counts the invalid items; Skipped counts valid items left out because the
+	// request was rejected for an invalid one
+	Failed  int                        `json:"failed" example:"1"`
+	Skipped int                        `json:"skipped" example:"0"`
+	Results []domain.BulkMessageResult `json:"results"`
+}
+
+// CreateMessagesBulk enqueues many messages in a single request
+// @Summary      Bulk Create Messages
+// @Description  Enqueue many messages at once from a JSON array or, with Content-Type application/x-ndjson, one JSON object per line.
+// @Description  By default any invalid item rejects the whole request, and the valid items are reported as skipped; with partial=true valid items are committed and invalid ones reported.
+// @Tags         messages
+// @Accept       json
+// @Accept       application/x-ndjson
+// @Produce      json
+// @Param        partial   query     bool                   false  "Commit valid items even if some are invalid"
+// @Param        request   body      []domain.MessageInput  true   "Messages to enqueue"
+// @Success      201       {object}  BulkCreateMessagesResponse
+// @Success      207       {object}  BulkCreateMessagesResponse
+// @Failure      400       {object}  BulkCreateMessagesResponse
+// @Failure      500       {object}  ErrorResponse
+// @Security     ApiKeyAuth
+// @Router       /messages/bulk [post]
+func (h *MessageHandler) CreateMessagesBulk(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var inputs []domain.MessageInput
+	var err error
+	if isNDJSON(r.Header.Get("Content-Type")) {
+		inputs, err = decodeNDJSONMessages(r.Body)
+	} else {
+		inputs, err = decodeJSONArrayMessages(r.Body)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(inputs) == 0 {
+		http.Error(w, "At least one message is required", http.StatusBadRequest)
+		return
+	}
+
+	partial, _ := strconv.ParseBool(r.URL.Query().Get("partial"))
+
+	results, err := h.service.CreateMessages(r.Context(), inputs, partial)
+	if err != nil && !errors.Is(err, domain.ErrInvalidBatch) {
+		log.Printf("Failed to create messages: %v", err)
+		http.Error(w, "Failed to create messages", http.StatusInternalServerError)
+		return
+	}
+
+	resp := BulkCreateMessagesResponse{Results: results}
+	for _, result := range results {
+		switch result.Status {
+		case domain.BulkItemCreated:
+			resp.Created++
+		case domain.BulkItemSkipped:
+			resp.Skipped++
+		default:
+			resp.Failed++
+		}
+	}
+
+	statusCode := http.StatusCreated
+	if errors.Is(err, domain.ErrInvalidBatch) {
+		statusCode = http.StatusBadRequest
+	} else if resp.Failed > 0 {
+		statusCode = http.StatusMultiStatus
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("Error encoding JSON response: %v", err)
+	}
+}
+
+func isNDJSON(contentType string) bool {
+	return strings.HasPrefix(contentType, "application/x-ndjson") ||
+		strings.HasPrefix(contentType, "application/jsonl")
+}
+
+// decodeJSONArrayMessages streams a JSON array of messages without buffering the raw body
+func decodeJSONArrayMessages(body io.Reader) ([]domain.MessageInput, error) {
+	decoder := json.NewDecoder(body)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("invalid request body")
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("request body must be a JSON array")
+	}
+
+	var inputs []domain.MessageInput
+	for decoder.More() {
+		if len(inputs) >= maxBulkMessages {
+			return nil, fmt.Errorf("too many messages, maximum is %d", maxBulkMessages)
+		}
+		var input domain.MessageInput
+		if err := decoder.Decode(&input); err != nil {
+			return nil, fmt.Errorf("invalid message at index %d", len(inputs))
+		}
+		inputs = append(inputs, input)
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("invalid request body")
+	}
+
+	return inputs, nil
+}
+
+// decodeNDJSONMessages reads one JSON message object per line
+func decodeNDJSONMessages(body io.Reader) ([]domain.MessageInput, error) {
+	decoder := json.NewDecoder(body)
+
+	var inputs []domain.MessageInput
+	for decoder.More() {
+		if len(inputs) >= maxBulkMessages {
+			return nil, fmt.Errorf("too many messages, maximum is %d", maxBulkMessages)
+		}
+		var input domain.MessageInput
+		if err := decoder.Decode(&input); err != nil {
+			return nil, fmt.Errorf("invalid message at line %d", len(inputs)+1)
+		}
+		inputs = append(inputs, input)
+	}
+
+	return inputs, nil
+}
+
+// isValidationError reports whether err was caused by invalid message input
+func isValidationError(err error) bool {
+	return errors.Is(err, domain.ErrInvalidPhoneNumber) ||
+		errors.Is(err, domain.ErrEmptyContent) ||
+		errors.Is(err, domain.ErrMessageTooLong)
+}
+
+// GetMessage returns a message with its lifecycle
+// @Summary      Get Message
+// @Description  Retrieve a message record with its lifecycle timeline (created, claimed, each webhook attempt, sent or failed, recovered) built from its audit entries, and the cached provider response once it was sent
+// @Tags         messages
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Message ID"
+// @Success      200  {object}  domain.MessageDetails
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Security     ApiKeyAuth
+// @Router       /messages/{id} [get]
+func (h *MessageHandler) GetMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract message ID from URL path
+	path := strings.TrimPrefix(r.URL.Path, "/api/messages/")
+	id, err := uuid.Parse(strings.TrimSuffix(path, "/"))
+	if err != nil {
+		http.Error(w, "Invalid message ID", http.StatusBadRequest)
+		return
+	}
+
+	details, err := h.service.GetMessageDetails(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrMessageNotFound) {
+			http.Error(w, "Message not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Error getting message %s: %v", id, err)
+		http.Error(w, "Failed to retrieve message", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(details); err != nil {
+		log.Printf("Error encoding JSON response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
 }
 
 // GetSentMessages retrieves sent messages with pagination
 // @Summary      Get Sent Messages
-// @Description  Retrieve a paginated list of successfully sent messages
+// @Description  Retrieve a paginated list of successfully sent messages, newest first. Pass next_cursor back as cursor to fetch the following page; unlike page, a cursor neither skips nor repeats messages sent in between.
 // @Tags         messages
 // @Accept       json
 // @Produce      json
 // @Param        page      query     int  false  "Page number (default: 1)"  minimum(1)
 // @Param        page_size query     int  false  "Page size (default: 20, max: 100)"  minimum(1)  maximum(100)
+// @Param        cursor    query     string  false  "Cursor from a previous response's next_cursor; takes precedence over page"
 // @Success      200       {object}  SentMessagesResponse
+// @Failure      400       {object}  ErrorResponse
 // @Failure      500       {object}  ErrorResponse
 // @Security     ApiKeyAuth
 // @Router       /messages/sent [get]
@@ -56,7 +340,17 @@ func (h *MessageHandler) GetSentMessages(w http.ResponseWriter, r *http.Request)
 		pageSize = 20
 	}
 
-	messages, err := h.service.GetSentMessages(r.Context(), page, pageSize)
+	var cursor *domain.PageCursor
+	if token := r.URL.Query().Get("cursor"); token != "" {
+		var err error
+		cursor, err = domain.DecodePageCursor(token)
+		if err != nil {
+			http.Error(w, "Invalid cursor parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	messages, next, err := h.service.GetSentMessagesPage(r.Context(), cursor, page, pageSize)
 	if err != nil {
 		http.Error(w, "Failed to retrieve messages", http.StatusInternalServerError)
 		return
@@ -81,6 +375,9 @@ func (h *MessageHandler) GetSentMessages(w http.ResponseWriter, r *http.Request)
 		Page:     page,
 		PageSize: pageSize,
 	}
+	if next != nil {
+		resp.NextCursor = next.Encode()
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
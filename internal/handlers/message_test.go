@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+	"ims/internal/service"
+)
+
+func newTestMessageHandler(repo *repository.MockMessageRepository) *MessageHandler {
+	webhook := service.NewWebhookClient("http://example.com", "test-key", 30*time.Second, 3)
+	messageService := service.NewMessageService(repo, repository.NewMockCacheRepository(), webhook, 160)
+	return NewMessageHandler(messageService)
+}
+
+func TestMessageHandler_CreateMessage_Success(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	handler := newTestMessageHandler(repo)
+
+	body := `{"phone_number": "+1234567890", "content": "Hello"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/messages", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	handler.CreateMessage(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+
+	var msg domain.Message
+	if err := json.Unmarshal(rr.Body.Bytes(), &msg); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if msg.PhoneNumber != "+1234567890" {
+		t.Errorf("Expected phone number +1234567890, got %s", msg.PhoneNumber)
+	}
+
+	if msg.Status != domain.StatusPending {
+		t.Errorf("Expected status %s, got %s", domain.StatusPending, msg.Status)
+	}
+
+	if repo.Count() != 1 {
+		t.Errorf("Expected 1 message in repository, got %d", repo.Count())
+	}
+}
+
+func TestMessageHandler_CreateMessage_BadRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"Malformed JSON", `{"phone_number":`},
+		{"Invalid phone number", `{"phone_number": "abc", "content": "Hello"}`},
+		{"Empty content", `{"phone_number": "+1234567890", "content": ""}`},
+		{"Content too long", `{"phone_number": "+1234567890", "content": "` + strings.Repeat("a", 161) + `"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewMockMessageRepository()
+			handler := newTestMessageHandler(repo)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/messages", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			handler.CreateMessage(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rr.Code)
+			}
+
+			if repo.Count() != 0 {
+				t.Errorf("Expected 0 messages in repository, got %d", repo.Count())
+			}
+		})
+	}
+}
+
+func TestMessageHandler_CreateMessage_IdempotencyKey(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	handler := newTestMessageHandler(repo)
+
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/messages", strings.NewReader(body))
+		req.Header.Set(IdempotencyKeyHeader, "retry-key")
+		rr := httptest.NewRecorder()
+		handler.CreateMessage(rr, req)
+		return rr
+	}
+
+	if rr := send(`{"phone_number": "+1234567890", "content": "Hello"}`); rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+
+	rr := send(`{"phone_number": "+1234567890", "content": "Hello"}`)
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected replay status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if rr.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("Expected Idempotent-Replayed header on replay")
+	}
+
+	if rr := send(`{"phone_number": "+1234567890", "content": "Changed"}`); rr.Code != http.StatusConflict {
+		t.Errorf("Expected status %d, got %d", http.StatusConflict, rr.Code)
+	}
+
+	if repo.Count() != 1 {
+		t.Errorf("Expected 1 message in repository, got %d", repo.Count())
+	}
+}
+
+func TestMessageHandler_CreateMessage_IdempotencyKeyTooLong(t *testing.T) {
+	tooLong := strings.Repeat("k", 256)
+	tests := []struct {
+		name   string
+		header string
+		body   string
+	}{
+		{"header", tooLong, `{"phone_number": "+1234567890", "content": "Hello"}`},
+		{"field", "", `{"phone_number": "+1234567890", "content": "Hello", "idempotency_key": "` + tooLong + `"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewMockMessageRepository()
+			handler := newTestMessageHandler(repo)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/messages", strings.NewReader(tt.body))
+			if tt.header != "" {
+				req.Header.Set(IdempotencyKeyHeader, tt.header)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.CreateMessage(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rr.Code)
+			}
+			if repo.Count() != 0 {
+				t.Errorf("Expected 0 messages in repository, got %d", repo.Count())
+			}
+		})
+	}
+
+	// 255 characters fit the column, even when they take more bytes
+	repo := repository.NewMockMessageRepository()
+	handler := newTestMessageHandler(repo)
+	req := httptest.NewRequest(http.MethodPost, "/api/messages", strings.NewReader(`{"phone_number": "+1234567890", "content": "Hello"}`))
+	req.Header.Set(IdempotencyKeyHeader, strings.Repeat("é", 255))
+	rr := httptest.NewRecorder()
+
+	handler.CreateMessage(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Errorf("Expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+}
+
+func TestMessageHandler_CreateMessage_RepositoryError(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	repo.CreateMessageFunc = func(ctx context.Context, message *domain.Message) error {
+		return errors.New("database error")
+	}
+	handler := newTestMessageHandler(repo)
+
+	body := `{"phone_number": "+1234567890", "content": "Hello"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/messages", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	handler.CreateMessage(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+}
+
+func TestMessageHandler_CreateMessage_MethodNotAllowed(t *testing.T) {
+	handler := newTestMessageHandler(repository.NewMockMessageRepository())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/messages", http.NoBody)
+	rr := httptest.NewRecorder()
+
+	handler.CreateMessage(rr, req)
+
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, rr.Code)
+	}
+}
+
+func TestMessageHandler_CreateMessagesBulk_JSONArray(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	handler := newTestMessageHandler(repo)
+
+	body := `[{"phone_number": "+1234567890", "content": "One"}, {"phone_number": "+1234567891", "content": "Two"}]`
+	req := httptest.NewRequest(http.MethodPost, "/api/messages/bulk", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	handler.CreateMessagesBulk(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+
+	var resp BulkCreateMessagesResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if resp.Created != 2 || resp.Failed != 0 {
+		t.Errorf("Expected 2 created and 0 failed, got %d and %d", resp.Created, resp.Failed)
+	}
+
+	if repo.Count() != 2 {
+		t.Errorf("Expected 2 messages in repository, got %d", repo.Count())
+	}
+}
+
+func TestMessageHandler_CreateMessagesBulk_NDJSONPartial(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	handler := newTestMessageHandler(repo)
+
+	body := "{\"phone_number\": \"+1234567890\", \"content\": \"One\"}\n{\"phone_number\": \"x\", \"content\": \"Two\"}\n"
+	req := httptest.NewRequest(http.MethodPost, "/api/messages/bulk?partial=true", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rr := httptest.NewRecorder()
+
+	handler.CreateMessagesBulk(rr, req)
+
+	if rr.Code != http.StatusMultiStatus {
+		t.Fatalf("Expected status %d, got %d", http.StatusMultiStatus, rr.Code)
+	}
+
+	var resp BulkCreateMessagesResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if resp.Created != 1 || resp.Failed != 1 {
+		t.Errorf("Expected 1 created and 1 failed, got %d and %d", resp.Created, resp.Failed)
+	}
+
+	if resp.Results[1].Error != domain.ErrInvalidPhoneNumber.Error() {
+		t.Errorf("Expected phone number error, got %q", resp.Results[1].Error)
+	}
+
+	if repo.Count() != 1 {
+		t.Errorf("Expected 1 message in repository, got %d", repo.Count())
+	}
+}
+
+func TestMessageHandler_CreateMessagesBulk_InvalidRejectsAll(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	handler := newTestMessageHandler(repo)
+
+	body := `[{"phone_number": "+1234567890", "content": "One"}, {"phone_number": "+1234567891", "content": ""}]`
+	req := httptest.NewRequest(http.MethodPost, "/api/messages/bulk", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	handler.CreateMessagesBulk(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	var resp BulkCreateMessagesResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	// Only the invalid item failed; the valid one was skipped and can be resent as is
+	if resp.Created != 0 || resp.Failed != 1 || resp.Skipped != 1 {
+		t.Errorf("Expected 0 created, 1 failed and 1 skipped, got %d, %d and %d", resp.Created, resp.Failed, resp.Skipped)
+	}
+
+	if resp.Results[0].Status != domain.BulkItemSkipped || resp.Results[0].Error != "" {
+		t.Errorf("Expected item 0 skipped without an error, got %+v", resp.Results[0])
+	}
+
+	if resp.Results[1].Status != domain.BulkItemInvalid || resp.Results[1].Error != domain.ErrEmptyContent.Error() {
+		t.Errorf("Expected item 1 invalid for empty content, got %+v", resp.Results[1])
+	}
+
+	if repo.Count() != 0 {
+		t.Errorf("Expected 0 messages in repository, got %d", repo.Count())
+	}
+}
+
+func TestMessageHandler_GetMessage(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	msg := &domain.Message{
+		ID:          uuid.New(),
+		PhoneNumber: "+1234567890",
+		Content:     "Hello",
+		Status:      domain.StatusPending,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	repo.AddMessage(msg)
+	handler := newTestMessageHandler(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/messages/"+msg.ID.String(), nil)
+	rr := httptest.NewRecorder()
+	handler.GetMessage(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	var details domain.MessageDetails
+	if err := json.Unmarshal(rr.Body.Bytes(), &details); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if details.Message == nil || details.Message.ID != msg.ID {
+		t.Errorf("Expected message %s, got %+v", msg.ID, details.Message)
+	}
+	if len(details.Timeline) != 1 || details.Timeline[0].Stage != domain.StageCreated {
+		t.Errorf("Expected a timeline with the creation only, got %+v", details.Timeline)
+	}
+}
+
+func TestMessageHandler_GetMessage_Errors(t *testing.T) {
+	handler := newTestMessageHandler(repository.NewMockMessageRepository())
+
+	tests := []struct {
+		target   string
+		expected int
+	}{
+		{"/api/messages/not-a-uuid", http.StatusBadRequest},
+		{"/api/messages/" + uuid.New().String(), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		rr := httptest.NewRecorder()
+
+		handler.GetMessage(rr, req)
+
+		if rr.Code != tt.expected {
+			t.Errorf("Expected status %d for %s, got %d", tt.expected, tt.target, rr.Code)
+		}
+	}
+}
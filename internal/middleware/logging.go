@@ -4,6 +4,8 @@ import (
 	"log"
 	"net/http"
 	"time"
+
+	"ims/internal/service"
 )
 
 func LoggingMiddleware(next http.Handler) http.Handler {
@@ -16,12 +18,14 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(wrapped, r)
 
 		// Log request details
+		requestID, _ := service.RequestIDFromContext(r.Context())
 		log.Printf(
-			"%s %s %d %s",
+			"%s %s %d %s request_id=%s",
 			r.Method,
 			r.RequestURI,
 			wrapped.statusCode,
 			time.Since(start),
+			requestID,
 		)
 	})
 }
@@ -35,3 +39,9 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap lets http.ResponseController reach the underlying writer to flush streamed
+// responses and extend their write deadline
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ims/internal/service"
+)
+
+// RequestIDHeader carries the request ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds caller-supplied IDs so they fit the audit log column
+const maxRequestIDLength = 100
+
+// RequestIDMiddleware takes the request ID from X-Request-ID or generates one, puts it on
+// the request context and the response header, and records an api_request audit entry.
+// Audit entries written while handling the request carry the same ID. Requests whose path
+// starts with one of skipAudit get an ID but no api_request entry, e.g. health probes.
+func RequestIDMiddleware(auditService service.AuditService, skipAudit ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestID := r.Header.Get(RequestIDHeader)
+			if !isValidRequestID(requestID) {
+				requestID = uuid.New().String()
+			}
+
+			w.Header().Set(RequestIDHeader, requestID)
+			ctx := service.WithRequestID(r.Context(), requestID)
+
+			wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+			// Deferred so that streams aborted with http.ErrAbortHandler are audited too
+			if auditService != nil && !hasAnyPrefix(r.URL.Path, skipAudit) {
+				defer func() {
+					duration := time.Since(start)
+					if err := auditService.LogAPIRequest(context.WithoutCancel(ctx), requestID, r.Method, r.URL.Path, wrapped.statusCode, duration, r.UserAgent()); err != nil {
+						log.Printf("Failed to log API request event: %v", err)
+					}
+				}()
+			}
+
+			next.ServeHTTP(wrapped, r.WithContext(ctx))
+		})
+	}
+}
+
+// isValidRequestID accepts non-empty printable ASCII IDs of bounded length so that callers
+// cannot inject control characters into logs and headers
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func hasAnyPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
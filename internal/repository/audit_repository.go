@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 
 	"ims/internal/domain"
 )
@@ -13,12 +16,27 @@ type AuditRepository interface {
 	// LogBatch creates multiple audit log entries in a single transaction
 	LogBatch(ctx context.Context, auditLogs []*domain.AuditLog) error
 
-	// GetAuditLogs retrieves audit logs based on filter criteria
+	// GetAuditLogs retrieves audit logs based on filter criteria, newest first. With a
+	// cursor it returns only entries after the one the cursor marks.
 	GetAuditLogs(ctx context.Context, filter *domain.AuditLogFilter) ([]*domain.AuditLog, error)
 
+	// StreamAuditLogs calls fn for every audit log matching the filter's criteria, oldest
+	// first, reading them through a database cursor; Limit, Offset and Cursor are ignored. It stops
+	// at the first error fn returns.
+	StreamAuditLogs(ctx context.Context, filter *domain.AuditLogFilter, fn func(*domain.AuditLog) error) error
+
+	// ExportAuditLogs streams like StreamAuditLogs, first calling header with the sorted
+	// top-level metadata keys used by the matching audit logs. The keys and the entries are
+	// read from the same snapshot.
+	ExportAuditLogs(ctx context.Context, filter *domain.AuditLogFilter, header func(metadataKeys []string) error, fn func(*domain.AuditLog) error) error
+
 	// GetAuditLogByID retrieves a specific audit log by ID
 	GetAuditLogByID(ctx context.Context, id string) (*domain.AuditLog, error)
 
+	// GetAuditLogByRef retrieves an audit log by ID and creation time, reading only the
+	// partition that holds it
+	GetAuditLogByRef(ctx context.Context, ref AuditLogRef) (*domain.AuditLog, error)
+
 	// GetBatchAuditLogs retrieves all audit logs for a specific batch
 	GetBatchAuditLogs(ctx context.Context, batchID string) ([]*domain.AuditLog, error)
 
@@ -28,8 +46,56 @@ type AuditRepository interface {
 	// GetAuditLogStats returns statistics about audit logs
 	GetAuditLogStats(ctx context.Context, filter *domain.AuditLogFilter) (*domain.AuditLogStats, error)
 
-	// DeleteOldAuditLogs removes audit logs older than specified days
+	// PruneAuditLogs deletes up to limit audit logs that have outlived the retention
+	// cutoffs and returns how many it deleted per event type. Monthly partitions holding only
+	// expired entries are dropped whole on top of the limit. With the hash chain, chained
+	// entries are deleted only up to the first one still within retention, and the last
+	// one deleted is recorded in a signed checkpoint.
+	PruneAuditLogs(ctx context.Context, cutoffs domain.AuditRetentionCutoffs, limit int) (map[domain.AuditEventType]int64, error)
+
+	// GetAuditLogTimeSeries groups audit logs matching the filter's criteria into UTC buckets
+	// of the interval and returns the buckets holding any, oldest first, with their counts per
+	// event type and duration percentiles
+	GetAuditLogTimeSeries(ctx context.Context, filter *domain.AuditLogFilter, interval domain.AuditStatsInterval) ([]*domain.AuditStatsBucket, error)
+
+	// DeleteOldAuditLogs removes audit logs older than specified days. With the hash chain
+	// enabled it records a signed checkpoint for the last chained entry removed.
 	DeleteOldAuditLogs(ctx context.Context, days int) (int64, error)
+
+	// GetAuditChainBounds returns the lowest and highest chain sequence of entries created
+	// within the range, or zeros if there are none; nil bounds are open
+	GetAuditChainBounds(ctx context.Context, from, to *time.Time) (firstSeq, lastSeq int64, err error)
+
+	// GetAuditChainEntries returns up to limit chained entries with a sequence between
+	// fromSeq and toSeq, in chain order
+	GetAuditChainEntries(ctx context.Context, fromSeq, toSeq int64, limit int) ([]*domain.AuditLog, error)
+
+	// GetAuditChainCheckpoint returns the checkpoint recorded for the entry at chainSeq, or
+	// nil if there is none
+	GetAuditChainCheckpoint(ctx context.Context, chainSeq int64) (*domain.AuditChainCheckpoint, error)
+
+	// GetAuditChainHead returns the record of the last chained entry, its signature checked
+	GetAuditChainHead(ctx context.Context) (*domain.AuditChainHead, error)
+
+	// AuditChainHash returns the link hash of an entry at seq after prevHash: keyed with the
+	// repository's key when keyed is set, a plain SHA-256 otherwise
+	AuditChainHash(auditLog *domain.AuditLog, seq int64, prevHash string, keyed bool) (string, error)
+}
+
+// AuditLogRef identifies a stored audit log by its ID and creation time
+type AuditLogRef struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+}
+
+// AuditLogListener reports the audit logs stored by any replica as they are stored
+type AuditLogListener interface {
+	// AuditLogRefs delivers a reference to each audit log stored while the listener is
+	// connected; it is closed once the listener is closed
+	AuditLogRefs() <-chan AuditLogRef
+
+	// Close stops listening
+	Close() error
 }
 
 // AuditLogStats represents statistics about audit logs
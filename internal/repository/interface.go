@@ -12,14 +12,66 @@ import (
 )
 
 type MessageRepository interface {
-	GetUnsentMessages(ctx context.Context, limit int) ([]*domain.Message, error)
+	// ClaimMessages atomically marks up to limit due pending messages as sending on behalf
+	// of owner and returns them; concurrent callers never receive the same message
+	ClaimMessages(ctx context.Context, owner string, limit int) ([]*domain.Message, error)
 	UpdateMessageStatus(ctx context.Context, id uuid.UUID, status domain.MessageStatus, messageID *string) error
+	RecordFailedAttempt(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt *time.Time) error
+	RecoverStuckMessages(ctx context.Context, stuckBefore time.Time) ([]*domain.Message, error)
+	// ReleaseClaims returns messages claimed by owner but never attempted back to pending
+	ReleaseClaims(ctx context.Context, owner string, ids []uuid.UUID) error
 	GetSentMessages(ctx context.Context, offset, limit int) ([]*domain.Message, error)
+	// GetSentMessagesAfter returns up to limit sent messages, newest first, that come after
+	// cursor; a nil cursor starts from the newest
+	GetSentMessagesAfter(ctx context.Context, cursor *domain.PageCursor, limit int) ([]*domain.Message, error)
 	GetMessage(ctx context.Context, id uuid.UUID) (*domain.Message, error)
 	CreateMessage(ctx context.Context, message *domain.Message) error
+	CreateMessages(ctx context.Context, messages []*domain.Message) error
+	GetMessageByIdempotencyKey(ctx context.Context, key string) (*domain.Message, error)
+	ReleaseIdempotencyKey(ctx context.Context, key string, createdBefore time.Time) error
 }
 
 type CacheRepository interface {
 	SetMessageCache(ctx context.Context, messageID string, data interface{}, ttl time.Duration) error
 	GetMessageCache(ctx context.Context, messageID string) (interface{}, error)
 }
+
+// LeaseRepository stores named, expiring leases used to elect a single leader among replicas
+type LeaseRepository interface {
+	// TryAcquireLease acquires the lease for holder, or renews it if holder already owns it.
+	// It reports false when another holder owns an unexpired lease.
+	TryAcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	// ReleaseLease gives up the lease if holder owns it
+	ReleaseLease(ctx context.Context, name, holder string) error
+	// GetLease returns the current unexpired lease, or nil if nobody holds it
+	GetLease(ctx context.Context, name string) (*domain.LeaderLease, error)
+}
+
+// AuditPartitionRepository creates the monthly partitions of the audit log table ahead of time
+type AuditPartitionRepository interface {
+	// EnsureAuditPartitions creates the missing monthly partitions from the current month
+	// through the month of through and returns the names of those it created. A month that
+	// fails does not stop the rest; its error is returned with the names created.
+	EnsureAuditPartitions(ctx context.Context, through time.Time) ([]string, error)
+}
+
+// SchedulerStateRepository persists the scheduler's desired run state across restarts and replicas
+type SchedulerStateRepository interface {
+	// GetDesiredState returns the stored state, or nil if none has been recorded yet
+	GetDesiredState(ctx context.Context) (*domain.SchedulerDesiredState, error)
+	// SetDesiredRunning stores the run state alone, so a concurrent settings change on
+	// another replica is kept; it returns the stored state
+	SetDesiredRunning(ctx context.Context, running bool, changedBy string) (*domain.SchedulerDesiredState, error)
+	// SetDesiredSettings stores the interval and batch size alone, keeping the stored run
+	// state; initialRunning is recorded only when no state exists yet
+	SetDesiredSettings(ctx context.Context, intervalMs int64, batchSize int, initialRunning bool, changedBy string) (*domain.SchedulerDesiredState, error)
+}
+
+// BatchResultRepository keeps the latest batch results so every replica can report the
+// batches run by whichever one is leader
+type BatchResultRepository interface {
+	// SaveBatchResult stores result and drops all but the newest keep results
+	SaveBatchResult(ctx context.Context, result *domain.BatchResult, keep int) error
+	// GetRecentBatchResults returns up to limit of the newest results, newest first
+	GetRecentBatchResults(ctx context.Context, limit int) ([]*domain.BatchResult, error)
+}
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,11 +19,19 @@ type MockMessageRepository struct {
 	messages map[uuid.UUID]*domain.Message
 
 	// Control mock behavior
-	GetUnsentMessagesFunc   func(ctx context.Context, limit int) ([]*domain.Message, error)
-	UpdateMessageStatusFunc func(ctx context.Context, id uuid.UUID, status domain.MessageStatus, messageID *string) error
-	GetSentMessagesFunc     func(ctx context.Context, offset, limit int) ([]*domain.Message, error)
-	GetMessageFunc          func(ctx context.Context, id uuid.UUID) (*domain.Message, error)
-	CreateMessageFunc       func(ctx context.Context, message *domain.Message) error
+	ClaimMessagesFunc        func(ctx context.Context, owner string, limit int) ([]*domain.Message, error)
+	UpdateMessageStatusFunc  func(ctx context.Context, id uuid.UUID, status domain.MessageStatus, messageID *string) error
+	RecordFailedAttemptFunc  func(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt *time.Time) error
+	RecoverStuckMessagesFunc func(ctx context.Context, stuckBefore time.Time) ([]*domain.Message, error)
+	ReleaseClaimsFunc        func(ctx context.Context, owner string, ids []uuid.UUID) error
+	GetSentMessagesFunc      func(ctx context.Context, offset, limit int) ([]*domain.Message, error)
+	GetSentMessagesAfterFunc func(ctx context.Context, cursor *domain.PageCursor, limit int) ([]*domain.Message, error)
+	GetMessageFunc           func(ctx context.Context, id uuid.UUID) (*domain.Message, error)
+	CreateMessageFunc        func(ctx context.Context, message *domain.Message) error
+	CreateMessagesFunc       func(ctx context.Context, messages []*domain.Message) error
+
+	GetMessageByIdempotencyKeyFunc func(ctx context.Context, key string) (*domain.Message, error)
+	ReleaseIdempotencyKeyFunc      func(ctx context.Context, key string, createdBefore time.Time) error
 }
 
 func NewMockMessageRepository() *MockMessageRepository {
@@ -29,21 +40,58 @@ func NewMockMessageRepository() *MockMessageRepository {
 	}
 }
 
-func (m *MockMessageRepository) GetUnsentMessages(ctx context.Context, limit int) ([]*domain.Message, error) {
-	if m.GetUnsentMessagesFunc != nil {
-		return m.GetUnsentMessagesFunc(ctx, limit)
+func (m *MockMessageRepository) ClaimMessages(ctx context.Context, owner string, limit int) ([]*domain.Message, error) {
+	if m.ClaimMessagesFunc != nil {
+		return m.ClaimMessagesFunc(ctx, owner, limit)
 	}
 
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	var unsent []*domain.Message
+	now := time.Now()
+	var claimed []*domain.Message
 	for _, msg := range m.messages {
-		if msg.Status == domain.StatusPending && len(unsent) < limit {
-			unsent = append(unsent, msg)
+		if len(claimed) >= limit {
+			break
+		}
+		if msg.Status != domain.StatusPending || (msg.NextAttemptAt != nil && msg.NextAttemptAt.After(now)) {
+			continue
 		}
+		claimedAt := now
+		claimedBy := owner
+		msg.Status = domain.StatusSending
+		msg.ClaimedBy = &claimedBy
+		msg.ClaimedAt = &claimedAt
+		msg.UpdatedAt = now
+		claimed = append(claimed, msg)
+	}
+	return claimed, nil
+}
+
+func (m *MockMessageRepository) RecordFailedAttempt(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt *time.Time) error {
+	if m.RecordFailedAttemptFunc != nil {
+		return m.RecordFailedAttemptFunc(ctx, id, lastError, nextAttemptAt)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	msg, exists := m.messages[id]
+	if !exists {
+		return domain.ErrMessageNotFound
+	}
+
+	msg.RetryCount++
+	msg.LastError = &lastError
+	msg.NextAttemptAt = nextAttemptAt
+	if nextAttemptAt != nil {
+		msg.Status = domain.StatusPending
+	} else {
+		msg.Status = domain.StatusFailed
 	}
-	return unsent, nil
+	msg.UpdatedAt = time.Now()
+
+	return nil
 }
 
 func (m *MockMessageRepository) UpdateMessageStatus(ctx context.Context, id uuid.UUID, status domain.MessageStatus, messageID *string) error {
@@ -70,6 +118,46 @@ func (m *MockMessageRepository) UpdateMessageStatus(ctx context.Context, id uuid
 	return nil
 }
 
+func (m *MockMessageRepository) RecoverStuckMessages(ctx context.Context, stuckBefore time.Time) ([]*domain.Message, error) {
+	if m.RecoverStuckMessagesFunc != nil {
+		return m.RecoverStuckMessagesFunc(ctx, stuckBefore)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var recovered []*domain.Message
+	for _, msg := range m.messages {
+		if msg.Status == domain.StatusSending && msg.UpdatedAt.Before(stuckBefore) {
+			msg.Status = domain.StatusPending
+			msg.UpdatedAt = time.Now()
+			recovered = append(recovered, msg)
+		}
+	}
+	return recovered, nil
+}
+
+func (m *MockMessageRepository) ReleaseClaims(ctx context.Context, owner string, ids []uuid.UUID) error {
+	if m.ReleaseClaimsFunc != nil {
+		return m.ReleaseClaimsFunc(ctx, owner, ids)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, id := range ids {
+		msg, exists := m.messages[id]
+		if !exists || msg.Status != domain.StatusSending || msg.ClaimedBy == nil || *msg.ClaimedBy != owner {
+			continue
+		}
+		msg.Status = domain.StatusPending
+		msg.ClaimedBy = nil
+		msg.ClaimedAt = nil
+		msg.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
 func (m *MockMessageRepository) GetSentMessages(ctx context.Context, offset, limit int) ([]*domain.Message, error) {
 	if m.GetSentMessagesFunc != nil {
 		return m.GetSentMessagesFunc(ctx, offset, limit)
@@ -84,6 +172,7 @@ func (m *MockMessageRepository) GetSentMessages(ctx context.Context, offset, lim
 			sent = append(sent, msg)
 		}
 	}
+	sortNewestSentFirst(sent)
 
 	// Simple pagination
 	start := offset
@@ -98,6 +187,44 @@ func (m *MockMessageRepository) GetSentMessages(ctx context.Context, offset, lim
 	return sent[start:end], nil
 }
 
+func (m *MockMessageRepository) GetSentMessagesAfter(ctx context.Context, cursor *domain.PageCursor, limit int) ([]*domain.Message, error) {
+	if m.GetSentMessagesAfterFunc != nil {
+		return m.GetSentMessagesAfterFunc(ctx, cursor, limit)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var sent []*domain.Message
+	for _, msg := range m.messages {
+		if msg.Status != domain.StatusSent || msg.SentAt == nil {
+			continue
+		}
+		if cursor != nil && !cursor.Follows(*msg.SentAt, msg.ID) {
+			continue
+		}
+		sent = append(sent, msg)
+	}
+
+	sortNewestSentFirst(sent)
+	if len(sent) > limit {
+		sent = sent[:limit]
+	}
+
+	return sent, nil
+}
+
+// sortNewestSentFirst orders messages like the database does, by sent_at DESC, id DESC
+func sortNewestSentFirst(messages []*domain.Message) {
+	sort.Slice(messages, func(i, j int) bool {
+		a, b := messages[i], messages[j]
+		if a.SentAt == nil || b.SentAt == nil {
+			return a.SentAt != nil && b.SentAt == nil
+		}
+		return domain.NewPageCursor(*a.SentAt, a.ID).Follows(*b.SentAt, b.ID)
+	})
+}
+
 func (m *MockMessageRepository) GetMessage(ctx context.Context, id uuid.UUID) (*domain.Message, error) {
 	if m.GetMessageFunc != nil {
 		return m.GetMessageFunc(ctx, id)
@@ -122,10 +249,65 @@ func (m *MockMessageRepository) CreateMessage(ctx context.Context, message *doma
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if message.IdempotencyKey != nil {
+		for _, msg := range m.messages {
+			if msg.IdempotencyKey != nil && *msg.IdempotencyKey == *message.IdempotencyKey {
+				return domain.ErrDuplicateIdempotencyKey
+			}
+		}
+	}
+
 	m.messages[message.ID] = message
 	return nil
 }
 
+func (m *MockMessageRepository) CreateMessages(ctx context.Context, messages []*domain.Message) error {
+	if m.CreateMessagesFunc != nil {
+		return m.CreateMessagesFunc(ctx, messages)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, message := range messages {
+		m.messages[message.ID] = message
+	}
+	return nil
+}
+
+func (m *MockMessageRepository) GetMessageByIdempotencyKey(ctx context.Context, key string) (*domain.Message, error) {
+	if m.GetMessageByIdempotencyKeyFunc != nil {
+		return m.GetMessageByIdempotencyKeyFunc(ctx, key)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, msg := range m.messages {
+		if msg.IdempotencyKey != nil && *msg.IdempotencyKey == key {
+			return msg, nil
+		}
+	}
+	return nil, domain.ErrMessageNotFound
+}
+
+func (m *MockMessageRepository) ReleaseIdempotencyKey(ctx context.Context, key string, createdBefore time.Time) error {
+	if m.ReleaseIdempotencyKeyFunc != nil {
+		return m.ReleaseIdempotencyKeyFunc(ctx, key, createdBefore)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, msg := range m.messages {
+		if msg.IdempotencyKey != nil && *msg.IdempotencyKey == key && msg.CreatedAt.Before(createdBefore) {
+			msg.IdempotencyKey = nil
+			msg.IdempotencyHash = ""
+		}
+	}
+	return nil
+}
+
 // Helper methods for testing
 func (m *MockMessageRepository) AddMessage(message *domain.Message) {
 	m.mu.Lock()
@@ -200,25 +382,231 @@ func (m *MockCacheRepository) Count() int {
 	return len(m.cache)
 }
 
+// MockLeaseRepository is a mock implementation of LeaseRepository for testing; Expire
+// stands in for a lease running out
+type MockLeaseRepository struct {
+	mu     sync.Mutex
+	leases map[string]*domain.LeaderLease
+
+	// Control mock behavior
+	TryAcquireLeaseFunc func(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	ReleaseLeaseFunc    func(ctx context.Context, name, holder string) error
+	GetLeaseFunc        func(ctx context.Context, name string) (*domain.LeaderLease, error)
+}
+
+func NewMockLeaseRepository() *MockLeaseRepository {
+	return &MockLeaseRepository{
+		leases: make(map[string]*domain.LeaderLease),
+	}
+}
+
+func (m *MockLeaseRepository) TryAcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	if m.TryAcquireLeaseFunc != nil {
+		return m.TryAcquireLeaseFunc(ctx, name, holder, ttl)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	lease, exists := m.leases[name]
+	if exists && lease.Holder != holder && lease.ExpiresAt.After(now) {
+		return false, nil
+	}
+	if !exists || lease.Holder != holder {
+		lease = &domain.LeaderLease{Holder: holder, AcquiredAt: now}
+		m.leases[name] = lease
+	}
+	lease.ExpiresAt = now.Add(ttl)
+	return true, nil
+}
+
+func (m *MockLeaseRepository) ReleaseLease(ctx context.Context, name, holder string) error {
+	if m.ReleaseLeaseFunc != nil {
+		return m.ReleaseLeaseFunc(ctx, name, holder)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if lease, exists := m.leases[name]; exists && lease.Holder == holder {
+		delete(m.leases, name)
+	}
+	return nil
+}
+
+func (m *MockLeaseRepository) GetLease(ctx context.Context, name string) (*domain.LeaderLease, error) {
+	if m.GetLeaseFunc != nil {
+		return m.GetLeaseFunc(ctx, name)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lease, exists := m.leases[name]
+	if !exists || !lease.ExpiresAt.After(time.Now()) {
+		return nil, nil
+	}
+	leaseCopy := *lease
+	return &leaseCopy, nil
+}
+
+// Expire makes the named lease run out, as if its holder had stopped renewing it
+func (m *MockLeaseRepository) Expire(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if lease, exists := m.leases[name]; exists {
+		lease.ExpiresAt = time.Now().Add(-time.Second)
+	}
+}
+
+// MockSchedulerStateRepository is a mock implementation of SchedulerStateRepository for
+// testing; replicas sharing one see each other's changes
+type MockSchedulerStateRepository struct {
+	mu    sync.Mutex
+	state *domain.SchedulerDesiredState
+
+	// Control mock behavior
+	GetDesiredStateFunc    func(ctx context.Context) (*domain.SchedulerDesiredState, error)
+	SetDesiredRunningFunc  func(ctx context.Context, running bool, changedBy string) (*domain.SchedulerDesiredState, error)
+	SetDesiredSettingsFunc func(ctx context.Context, intervalMs int64, batchSize int, initialRunning bool, changedBy string) (*domain.SchedulerDesiredState, error)
+}
+
+func NewMockSchedulerStateRepository() *MockSchedulerStateRepository {
+	return &MockSchedulerStateRepository{}
+}
+
+func (m *MockSchedulerStateRepository) GetDesiredState(ctx context.Context) (*domain.SchedulerDesiredState, error) {
+	if m.GetDesiredStateFunc != nil {
+		return m.GetDesiredStateFunc(ctx)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.state == nil {
+		return nil, nil
+	}
+	stateCopy := *m.state
+	return &stateCopy, nil
+}
+
+func (m *MockSchedulerStateRepository) SetDesiredRunning(ctx context.Context, running bool, changedBy string) (*domain.SchedulerDesiredState, error) {
+	if m.SetDesiredRunningFunc != nil {
+		return m.SetDesiredRunningFunc(ctx, running, changedBy)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.state == nil {
+		m.state = &domain.SchedulerDesiredState{}
+	}
+	m.state.Running = running
+	m.state.ChangedBy = changedBy
+	m.state.ChangedAt = time.Now()
+
+	stateCopy := *m.state
+	return &stateCopy, nil
+}
+
+func (m *MockSchedulerStateRepository) SetDesiredSettings(ctx context.Context, intervalMs int64, batchSize int, initialRunning bool, changedBy string) (*domain.SchedulerDesiredState, error) {
+	if m.SetDesiredSettingsFunc != nil {
+		return m.SetDesiredSettingsFunc(ctx, intervalMs, batchSize, initialRunning, changedBy)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.state == nil {
+		m.state = &domain.SchedulerDesiredState{Running: initialRunning}
+	}
+	m.state.IntervalMs = intervalMs
+	m.state.BatchSize = batchSize
+	m.state.ChangedBy = changedBy
+	m.state.ChangedAt = time.Now()
+
+	stateCopy := *m.state
+	return &stateCopy, nil
+}
+
+// MockBatchResultRepository is a mock implementation of BatchResultRepository for testing;
+// replicas sharing one see each other's batches
+type MockBatchResultRepository struct {
+	mu      sync.Mutex
+	results []*domain.BatchResult
+
+	// Control mock behavior
+	SaveBatchResultFunc       func(ctx context.Context, result *domain.BatchResult, keep int) error
+	GetRecentBatchResultsFunc func(ctx context.Context, limit int) ([]*domain.BatchResult, error)
+}
+
+func NewMockBatchResultRepository() *MockBatchResultRepository {
+	return &MockBatchResultRepository{}
+}
+
+func (m *MockBatchResultRepository) SaveBatchResult(ctx context.Context, result *domain.BatchResult, keep int) error {
+	if m.SaveBatchResultFunc != nil {
+		return m.SaveBatchResultFunc(ctx, result, keep)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.results = append(m.results, result)
+	if len(m.results) > keep {
+		m.results = m.results[len(m.results)-keep:]
+	}
+	return nil
+}
+
+func (m *MockBatchResultRepository) GetRecentBatchResults(ctx context.Context, limit int) ([]*domain.BatchResult, error) {
+	if m.GetRecentBatchResultsFunc != nil {
+		return m.GetRecentBatchResultsFunc(ctx, limit)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	results := []*domain.BatchResult{}
+	for i := len(m.results) - 1; i >= 0 && len(results) < limit; i-- {
+		results = append(results, m.results[i])
+	}
+	return results, nil
+}
+
 // MockAuditRepository is a mock implementation of AuditRepository for testing
 type MockAuditRepository struct {
-	mu   sync.RWMutex
-	logs []*domain.AuditLog
+	mu          sync.RWMutex
+	logs        []*domain.AuditLog
+	checkpoints map[int64]*domain.AuditChainCheckpoint
 
 	// Control mock behavior
 	LogFunc                 func(ctx context.Context, auditLog *domain.AuditLog) error
 	LogBatchFunc            func(ctx context.Context, auditLogs []*domain.AuditLog) error
 	GetAuditLogsFunc        func(ctx context.Context, filter *domain.AuditLogFilter) ([]*domain.AuditLog, error)
 	GetAuditLogByIDFunc     func(ctx context.Context, id string) (*domain.AuditLog, error)
+	GetAuditLogByRefFunc    func(ctx context.Context, ref AuditLogRef) (*domain.AuditLog, error)
 	GetBatchAuditLogsFunc   func(ctx context.Context, batchID string) ([]*domain.AuditLog, error)
 	GetMessageAuditLogsFunc func(ctx context.Context, messageID string) ([]*domain.AuditLog, error)
 	GetAuditLogStatsFunc    func(ctx context.Context, filter *domain.AuditLogFilter) (*domain.AuditLogStats, error)
 	DeleteOldAuditLogsFunc  func(ctx context.Context, days int) (int64, error)
+
+	StreamAuditLogsFunc         func(ctx context.Context, filter *domain.AuditLogFilter, fn func(*domain.AuditLog) error) error
+	ExportAuditLogsFunc         func(ctx context.Context, filter *domain.AuditLogFilter, header func([]string) error, fn func(*domain.AuditLog) error) error
+	GetAuditChainBoundsFunc     func(ctx context.Context, from, to *time.Time) (int64, int64, error)
+	GetAuditChainEntriesFunc    func(ctx context.Context, fromSeq, toSeq int64, limit int) ([]*domain.AuditLog, error)
+	GetAuditChainCheckpointFunc func(ctx context.Context, chainSeq int64) (*domain.AuditChainCheckpoint, error)
+	GetAuditChainHeadFunc       func(ctx context.Context) (*domain.AuditChainHead, error)
+	GetAuditLogTimeSeriesFunc   func(ctx context.Context, filter *domain.AuditLogFilter, interval domain.AuditStatsInterval) ([]*domain.AuditStatsBucket, error)
+	PruneAuditLogsFunc          func(ctx context.Context, cutoffs domain.AuditRetentionCutoffs, limit int) (map[domain.AuditEventType]int64, error)
 }
 
 func NewMockAuditRepository() *MockAuditRepository {
 	return &MockAuditRepository{
-		logs: make([]*domain.AuditLog, 0),
+		logs:        make([]*domain.AuditLog, 0),
+		checkpoints: make(map[int64]*domain.AuditChainCheckpoint),
 	}
 }
 
@@ -229,7 +617,7 @@ func (m *MockAuditRepository) Log(ctx context.Context, auditLog *domain.AuditLog
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.logs = append(m.logs, auditLog)
+	m.appendLog(auditLog)
 	return nil
 }
 
@@ -240,10 +628,22 @@ func (m *MockAuditRepository) LogBatch(ctx context.Context, auditLogs []*domain.
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.logs = append(m.logs, auditLogs...)
+	for _, auditLog := range auditLogs {
+		m.appendLog(auditLog)
+	}
 	return nil
 }
 
+// appendLog stores an entry unless its ID is already stored, like the database does
+func (m *MockAuditRepository) appendLog(auditLog *domain.AuditLog) {
+	for _, existing := range m.logs {
+		if existing.ID == auditLog.ID {
+			return
+		}
+	}
+	m.logs = append(m.logs, auditLog)
+}
+
 func (m *MockAuditRepository) GetAuditLogs(ctx context.Context, filter *domain.AuditLogFilter) ([]*domain.AuditLog, error) {
 	if m.GetAuditLogsFunc != nil {
 		return m.GetAuditLogsFunc(ctx, filter)
@@ -260,6 +660,21 @@ func (m *MockAuditRepository) GetAuditLogs(ctx context.Context, filter *domain.A
 		}
 	}
 
+	// Newest first, like the database
+	sort.Slice(filtered, func(i, j int) bool {
+		return domain.NewPageCursor(filtered[i].CreatedAt, filtered[i].ID).Follows(filtered[j].CreatedAt, filtered[j].ID)
+	})
+
+	if filter != nil && filter.Cursor != nil {
+		var after []*domain.AuditLog
+		for _, log := range filtered {
+			if filter.Cursor.Follows(log.CreatedAt, log.ID) {
+				after = append(after, log)
+			}
+		}
+		filtered = after
+	}
+
 	// Apply pagination
 	if filter != nil {
 		start := filter.Offset
@@ -278,6 +693,65 @@ func (m *MockAuditRepository) GetAuditLogs(ctx context.Context, filter *domain.A
 	return filtered, nil
 }
 
+func (m *MockAuditRepository) StreamAuditLogs(ctx context.Context, filter *domain.AuditLogFilter, fn func(*domain.AuditLog) error) error {
+	if m.StreamAuditLogsFunc != nil {
+		return m.StreamAuditLogsFunc(ctx, filter, fn)
+	}
+
+	m.mu.RLock()
+	var matched []*domain.AuditLog
+	for _, log := range m.logs {
+		if m.matchesFilter(log, filter) {
+			matched = append(matched, log)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.Before(matched[j].CreatedAt)
+	})
+	for _, log := range matched {
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *MockAuditRepository) ExportAuditLogs(ctx context.Context, filter *domain.AuditLogFilter, header func([]string) error, fn func(*domain.AuditLog) error) error {
+	if m.ExportAuditLogsFunc != nil {
+		return m.ExportAuditLogsFunc(ctx, filter, header, fn)
+	}
+
+	if err := header(m.metadataKeys(filter)); err != nil {
+		return err
+	}
+	return m.StreamAuditLogs(ctx, filter, fn)
+}
+
+func (m *MockAuditRepository) metadataKeys(filter *domain.AuditLogFilter) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var keys []string
+	for _, log := range m.logs {
+		if !m.matchesFilter(log, filter) {
+			continue
+		}
+		for key := range log.Metadata {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
 func (m *MockAuditRepository) GetAuditLogByID(ctx context.Context, id string) (*domain.AuditLog, error) {
 	if m.GetAuditLogByIDFunc != nil {
 		return m.GetAuditLogByIDFunc(ctx, id)
@@ -295,6 +769,23 @@ func (m *MockAuditRepository) GetAuditLogByID(ctx context.Context, id string) (*
 	return nil, domain.ErrMessageNotFound
 }
 
+func (m *MockAuditRepository) GetAuditLogByRef(ctx context.Context, ref AuditLogRef) (*domain.AuditLog, error) {
+	if m.GetAuditLogByRefFunc != nil {
+		return m.GetAuditLogByRefFunc(ctx, ref)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, log := range m.logs {
+		if log.ID == ref.ID && log.CreatedAt.Equal(ref.CreatedAt) {
+			return log, nil
+		}
+	}
+
+	return nil, domain.ErrMessageNotFound
+}
+
 func (m *MockAuditRepository) GetBatchAuditLogs(ctx context.Context, batchID string) ([]*domain.AuditLog, error) {
 	if m.GetBatchAuditLogsFunc != nil {
 		return m.GetBatchAuditLogsFunc(ctx, batchID)
@@ -351,6 +842,73 @@ func (m *MockAuditRepository) GetAuditLogStats(ctx context.Context, filter *doma
 	return stats, nil
 }
 
+func (m *MockAuditRepository) GetAuditLogTimeSeries(ctx context.Context, filter *domain.AuditLogFilter, interval domain.AuditStatsInterval) ([]*domain.AuditStatsBucket, error) {
+	if m.GetAuditLogTimeSeriesFunc != nil {
+		return m.GetAuditLogTimeSeriesFunc(ctx, filter, interval)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	buckets := make(map[time.Time]*domain.AuditStatsBucket)
+	durations := make(map[time.Time]map[domain.AuditEventType][]float64)
+	for _, log := range m.logs {
+		if !m.matchesFilter(log, filter) {
+			continue
+		}
+
+		start := log.CreatedAt.UTC().Truncate(interval.Duration())
+		bucket, exists := buckets[start]
+		if !exists {
+			bucket = &domain.AuditStatsBucket{
+				Start:           start,
+				EventTypeCounts: make(map[domain.AuditEventType]int64),
+			}
+			buckets[start] = bucket
+			durations[start] = make(map[domain.AuditEventType][]float64)
+		}
+
+		bucket.TotalCount++
+		bucket.EventTypeCounts[log.EventType]++
+		if log.DurationMs != nil {
+			durations[start][log.EventType] = append(durations[start][log.EventType], float64(*log.DurationMs))
+		}
+	}
+
+	result := make([]*domain.AuditStatsBucket, 0, len(buckets))
+	for start, bucket := range buckets {
+		for eventType, values := range durations[start] {
+			if bucket.Durations == nil {
+				bucket.Durations = make(map[domain.AuditEventType]*domain.AuditDurationPercentiles)
+			}
+			sort.Float64s(values)
+			bucket.Durations[eventType] = &domain.AuditDurationPercentiles{
+				Count: int64(len(values)),
+				P50:   percentileCont(values, 0.50),
+				P95:   percentileCont(values, 0.95),
+				P99:   percentileCont(values, 0.99),
+			}
+		}
+		result = append(result, bucket)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+
+	return result, nil
+}
+
+// percentileCont interpolates the percentile of sorted values like PostgreSQL's
+// percentile_cont
+func percentileCont(sorted []float64, fraction float64) float64 {
+	position := fraction * float64(len(sorted)-1)
+	lower := int(position)
+	if lower+1 >= len(sorted) {
+		return sorted[lower]
+	}
+	return sorted[lower] + (position-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
+
 func (m *MockAuditRepository) DeleteOldAuditLogs(ctx context.Context, days int) (int64, error) {
 	if m.DeleteOldAuditLogsFunc != nil {
 		return m.DeleteOldAuditLogsFunc(ctx, days)
@@ -375,7 +933,132 @@ func (m *MockAuditRepository) DeleteOldAuditLogs(ctx context.Context, days int)
 	return deleted, nil
 }
 
+func (m *MockAuditRepository) PruneAuditLogs(ctx context.Context, cutoffs domain.AuditRetentionCutoffs, limit int) (map[domain.AuditEventType]int64, error) {
+	if m.PruneAuditLogsFunc != nil {
+		return m.PruneAuditLogsFunc(ctx, cutoffs, limit)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := make(map[domain.AuditEventType]int64)
+	var kept []*domain.AuditLog
+	var count int
+
+	for _, log := range m.logs {
+		if count < limit && cutoffs.Expired(log.EventType, log.CreatedAt) {
+			deleted[log.EventType]++
+			count++
+		} else {
+			kept = append(kept, log)
+		}
+	}
+
+	m.logs = kept
+	return deleted, nil
+}
+
+func (m *MockAuditRepository) GetAuditChainBounds(ctx context.Context, from, to *time.Time) (int64, int64, error) {
+	if m.GetAuditChainBoundsFunc != nil {
+		return m.GetAuditChainBoundsFunc(ctx, from, to)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var firstSeq, lastSeq int64
+	for _, log := range m.logs {
+		if log.ChainSeq == nil {
+			continue
+		}
+		if from != nil && log.CreatedAt.Before(*from) {
+			continue
+		}
+		if to != nil && log.CreatedAt.After(*to) {
+			continue
+		}
+		if firstSeq == 0 || *log.ChainSeq < firstSeq {
+			firstSeq = *log.ChainSeq
+		}
+		if *log.ChainSeq > lastSeq {
+			lastSeq = *log.ChainSeq
+		}
+	}
+
+	return firstSeq, lastSeq, nil
+}
+
+func (m *MockAuditRepository) GetAuditChainEntries(ctx context.Context, fromSeq, toSeq int64, limit int) ([]*domain.AuditLog, error) {
+	if m.GetAuditChainEntriesFunc != nil {
+		return m.GetAuditChainEntriesFunc(ctx, fromSeq, toSeq, limit)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var entries []*domain.AuditLog
+	for _, log := range m.logs {
+		if log.ChainSeq != nil && *log.ChainSeq >= fromSeq && *log.ChainSeq <= toSeq {
+			entries = append(entries, log)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return *entries[i].ChainSeq < *entries[j].ChainSeq
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries, nil
+}
+
+func (m *MockAuditRepository) GetAuditChainCheckpoint(ctx context.Context, chainSeq int64) (*domain.AuditChainCheckpoint, error) {
+	if m.GetAuditChainCheckpointFunc != nil {
+		return m.GetAuditChainCheckpointFunc(ctx, chainSeq)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.checkpoints[chainSeq], nil
+}
+
+// GetAuditChainHead defaults to a validly signed head at the highest stored chain entry,
+// keyed from the start
+func (m *MockAuditRepository) GetAuditChainHead(ctx context.Context) (*domain.AuditChainHead, error) {
+	if m.GetAuditChainHeadFunc != nil {
+		return m.GetAuditChainHeadFunc(ctx)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	head := &domain.AuditChainHead{Hash: domain.AuditChainGenesisHash, KeyedFrom: 1, SignatureValid: true}
+	for _, log := range m.logs {
+		if log.ChainSeq != nil && *log.ChainSeq > head.ChainSeq && log.Hash != nil {
+			head.ChainSeq = *log.ChainSeq
+			head.Hash = *log.Hash
+		}
+	}
+	return head, nil
+}
+
+// MockAuditChainKey keys the link hashes of MockAuditRepository
+var MockAuditChainKey = []byte("mock-audit-chain-key")
+
+func (m *MockAuditRepository) AuditChainHash(auditLog *domain.AuditLog, seq int64, prevHash string, keyed bool) (string, error) {
+	if !keyed {
+		return auditLog.ChainHash(seq, prevHash, nil)
+	}
+	return auditLog.ChainHash(seq, prevHash, MockAuditChainKey)
+}
+
 // Helper methods for testing
+func (m *MockAuditRepository) AddCheckpoint(checkpoint *domain.AuditChainCheckpoint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkpoints[checkpoint.ChainSeq] = checkpoint
+}
+
 func (m *MockAuditRepository) Clear() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -450,5 +1133,98 @@ func (m *MockAuditRepository) matchesFilter(log *domain.AuditLog, filter *domain
 		return false
 	}
 
+	// Check metadata containment, comparing values as JSON like the database does
+	if len(filter.Metadata) > 0 && !jsonContains(normalizeJSON(log.Metadata), normalizeJSON(filter.Metadata)) {
+		return false
+	}
+
+	// Check search words
+	if filter.Search != nil {
+		text := strings.ToLower(log.EventName)
+		if log.Description != nil {
+			text += " " + strings.ToLower(*log.Description)
+		}
+		for _, word := range strings.Fields(strings.ToLower(*filter.Search)) {
+			if !strings.Contains(text, word) {
+				return false
+			}
+		}
+	}
+
 	return true
 }
+
+// normalizeJSON round-trips value through JSON so numbers and nested types compare the way
+// they are stored
+func normalizeJSON(value interface{}) interface{} {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(encoded, &normalized); err != nil {
+		return nil
+	}
+	return normalized
+}
+
+// jsonContains mirrors the JSONB @> operator for decoded JSON values
+func jsonContains(doc, want interface{}) bool {
+	switch w := want.(type) {
+	case map[string]interface{}:
+		d, ok := doc.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range w {
+			if field, exists := d[key]; !exists || !jsonContains(field, value) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		d, ok := doc.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, value := range w {
+			found := false
+			for _, element := range d {
+				if jsonContains(element, value) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	default:
+		return doc == want
+	}
+}
+
+// MockAuditLogListener is a mock implementation of AuditLogListener for testing; Notify
+// stands in for an audit log being stored
+type MockAuditLogListener struct {
+	refs      chan AuditLogRef
+	closeOnce sync.Once
+}
+
+func NewMockAuditLogListener() *MockAuditLogListener {
+	return &MockAuditLogListener{refs: make(chan AuditLogRef, 100)}
+}
+
+func (m *MockAuditLogListener) Notify(auditLog *domain.AuditLog) {
+	m.refs <- AuditLogRef{ID: auditLog.ID, CreatedAt: auditLog.CreatedAt}
+}
+
+func (m *MockAuditLogListener) AuditLogRefs() <-chan AuditLogRef {
+	return m.refs
+}
+
+func (m *MockAuditLogListener) Close() error {
+	m.closeOnce.Do(func() { close(m.refs) })
+	return nil
+}
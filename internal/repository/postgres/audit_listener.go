@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"ims/internal/repository"
+)
+
+// auditLogChannel is the channel the audit_logs trigger notifies after each insert, with the
+// stored entries as comma-separated id:created_at pairs, created_at in Unix microseconds
+const auditLogChannel = "audit_logs"
+
+type auditLogListener struct {
+	listener *pq.Listener
+	refs     chan repository.AuditLogRef
+	wg       sync.WaitGroup
+}
+
+// NewAuditLogListener listens for the audit logs stored by any replica on its own
+// connection, reconnecting when it drops. Entries stored while it is disconnected are missed.
+func NewAuditLogListener(databaseURL string) (repository.AuditLogListener, error) {
+	listener := pq.NewListener(databaseURL, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Audit log listener connection event %d: %v", event, err)
+		}
+	})
+	if err := listener.Listen(auditLogChannel); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to listen for audit logs: %w", err)
+	}
+
+	l := &auditLogListener{
+		listener: listener,
+		refs:     make(chan repository.AuditLogRef, 256),
+	}
+	l.wg.Add(1)
+	go l.run()
+
+	return l, nil
+}
+
+func (l *auditLogListener) run() {
+	defer l.wg.Done()
+	defer close(l.refs)
+
+	for notification := range l.listener.Notify {
+		// A nil notification follows a reconnect
+		if notification == nil {
+			log.Println("Audit log listener reconnected; entries stored while disconnected were missed")
+			continue
+		}
+
+		for _, entry := range strings.Split(notification.Extra, ",") {
+			ref, err := parseAuditLogRef(entry)
+			if err != nil {
+				log.Printf("Ignoring audit log notification entry %q: %v", entry, err)
+				continue
+			}
+			l.refs <- ref
+		}
+	}
+}
+
+// parseAuditLogRef parses one id:created_at pair of a notification
+func parseAuditLogRef(entry string) (repository.AuditLogRef, error) {
+	id, micros, ok := strings.Cut(entry, ":")
+	if !ok {
+		return repository.AuditLogRef{}, fmt.Errorf("missing creation time")
+	}
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return repository.AuditLogRef{}, fmt.Errorf("invalid ID: %w", err)
+	}
+	parsedMicros, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return repository.AuditLogRef{}, fmt.Errorf("invalid creation time: %w", err)
+	}
+	return repository.AuditLogRef{ID: parsedID, CreatedAt: time.UnixMicro(parsedMicros)}, nil
+}
+
+func (l *auditLogListener) AuditLogRefs() <-chan repository.AuditLogRef {
+	return l.refs
+}
+
+func (l *auditLogListener) Close() error {
+	err := l.listener.Close()
+	l.wg.Wait()
+	return err
+}
@@ -0,0 +1,441 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+)
+
+// Monthly partitions of audit_logs are named audit_logs_pYYYY_MM and cover one UTC month
+const (
+	auditPartitionPrefix = "audit_logs_p"
+	auditPartitionLayout = "2006_01"
+)
+
+type auditPartition struct {
+	name  string
+	start time.Time
+	end   time.Time
+}
+
+// auditPartitionFor returns the partition covering the month of t
+func auditPartitionFor(t time.Time) auditPartition {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return auditPartition{
+		name:  auditPartitionPrefix + start.Format(auditPartitionLayout),
+		start: start,
+		end:   start.AddDate(0, 1, 0),
+	}
+}
+
+// listAuditPartitions returns the monthly partitions of audit_logs, oldest first. The default
+// partition and any partition not named like the monthly ones are left out.
+func listAuditPartitions(ctx context.Context, q sqlx.QueryerContext) ([]auditPartition, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'audit_logs'::regclass`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit partitions: %w", err)
+	}
+	defer rows.Close()
+
+	var partitions []auditPartition
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan audit partition: %w", err)
+		}
+
+		month, found := strings.CutPrefix(name, auditPartitionPrefix)
+		if !found {
+			continue
+		}
+		start, err := time.Parse(auditPartitionLayout, month)
+		if err != nil {
+			continue
+		}
+		if partition := auditPartitionFor(start); partition.name == name {
+			partitions = append(partitions, partition)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	sort.Slice(partitions, func(i, j int) bool {
+		return partitions[i].start.Before(partitions[j].start)
+	})
+
+	return partitions, nil
+}
+
+// lockAuditPartitions serializes partition maintenance across replicas until the
+// transaction ends
+func lockAuditPartitions(ctx context.Context, tx *sqlx.Tx) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('audit_logs_partitions'))`); err != nil {
+		return fmt.Errorf("failed to lock audit partitions: %w", err)
+	}
+	return nil
+}
+
+type auditPartitionRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditPartitionRepository(db *sqlx.DB) repository.AuditPartitionRepository {
+	return &auditPartitionRepository{db: db}
+}
+
+// EnsureAuditPartitions creates the missing monthly partitions from the current month through
+// the one containing through. Each partition is created in its own transaction, so a month that
+// fails does not hold back the others; the failures are returned together.
+func (r *auditPartitionRepository) EnsureAuditPartitions(ctx context.Context, through time.Time) ([]string, error) {
+	var partitioned bool
+	err := r.db.QueryRowContext(ctx, `SELECT relkind = 'p' FROM pg_class WHERE oid = 'audit_logs'::regclass`).Scan(&partitioned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect audit_logs: %w", err)
+	}
+	if !partitioned {
+		return nil, fmt.Errorf("audit_logs is not partitioned, apply migration 015_partition_audit_logs.sql")
+	}
+
+	partitions, err := listAuditPartitions(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(partitions))
+	for _, partition := range partitions {
+		existing[partition.name] = true
+	}
+
+	var created []string
+	var errs []error
+	for partition := auditPartitionFor(time.Now()); !partition.start.After(through); partition = auditPartitionFor(partition.end) {
+		if existing[partition.name] {
+			continue
+		}
+
+		ok, err := r.createAuditPartition(ctx, partition)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create audit partition %s: %w", partition.name, err))
+			continue
+		}
+		if ok {
+			created = append(created, partition.name)
+		}
+	}
+
+	return created, errors.Join(errs...)
+}
+
+// createAuditPartition creates one monthly partition, reporting false if another replica
+// already did. Entries for that month that landed in the default partition would make a
+// plain CREATE ... PARTITION OF fail, so they are moved into the new partition first: it is
+// built as a standalone table, filled from the default partition and then attached.
+func (r *auditPartitionRepository) createAuditPartition(ctx context.Context, partition auditPartition) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			log.Printf("Error rolling back transaction: %v", rollbackErr)
+		}
+	}()
+
+	if err := lockAuditPartitions(ctx, tx); err != nil {
+		return false, err
+	}
+
+	var exists, hasDefault bool
+	err = tx.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL, to_regclass('audit_logs_default') IS NOT NULL`,
+		partition.name).Scan(&exists, &hasDefault)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up audit partition: %w", err)
+	}
+	if exists {
+		return false, nil
+	}
+
+	table := pq.QuoteIdentifier(partition.name)
+	// Bounds cannot be bind parameters in DDL
+	bounds := fmt.Sprintf(`FOR VALUES FROM (%s) TO (%s)`,
+		pq.QuoteLiteral(partition.start.Format(time.RFC3339)),
+		pq.QuoteLiteral(partition.end.Format(time.RFC3339)),
+	)
+
+	var strays bool
+	if hasDefault {
+		// Keep writers out of the default partition until the move is done, or a new entry
+		// for the month would make the attach fail
+		if _, err := tx.ExecContext(ctx, `LOCK TABLE audit_logs_default IN EXCLUSIVE MODE`); err != nil {
+			return false, fmt.Errorf("failed to lock default audit partition: %w", err)
+		}
+		err := tx.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM audit_logs_default WHERE created_at >= $1 AND created_at < $2)`,
+			partition.start, partition.end).Scan(&strays)
+		if err != nil {
+			return false, fmt.Errorf("failed to inspect default audit partition: %w", err)
+		}
+	}
+
+	if !strays {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE %s PARTITION OF audit_logs %s`, table, bounds)); err != nil {
+			return false, err
+		}
+	} else {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+			`CREATE TABLE %s (LIKE audit_logs INCLUDING DEFAULTS INCLUDING CONSTRAINTS)`, table)); err != nil {
+			return false, err
+		}
+		result, err := tx.ExecContext(ctx, fmt.Sprintf(`
+			INSERT INTO %s SELECT * FROM audit_logs_default
+			WHERE created_at >= $1 AND created_at < $2`, table), partition.start, partition.end)
+		if err != nil {
+			return false, fmt.Errorf("failed to copy entries from the default audit partition: %w", err)
+		}
+		moved, err := result.RowsAffected()
+		if err != nil {
+			return false, fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			DELETE FROM audit_logs_default WHERE created_at >= $1 AND created_at < $2`,
+			partition.start, partition.end); err != nil {
+			return false, fmt.Errorf("failed to delete entries from the default audit partition: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE audit_logs ATTACH PARTITION %s %s`, table, bounds)); err != nil {
+			return false, err
+		}
+		log.Printf("Moved %d audit logs from the default partition into %s", moved, partition.name)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+// auditRetentionKept builds the condition matching entries kept however old they are: event
+// types kept forever, and event types without a rule when there is no default. Its
+// placeholders are numbered after args.
+func auditRetentionKept(cutoffs domain.AuditRetentionCutoffs, args []interface{}) (string, []interface{}) {
+	var forever, ruled []string
+	for eventType, cutoff := range cutoffs.EventTypes {
+		ruled = append(ruled, string(eventType))
+		if cutoff.IsZero() {
+			forever = append(forever, string(eventType))
+		}
+	}
+	sort.Strings(forever)
+	sort.Strings(ruled)
+
+	var conditions []string
+	if len(forever) > 0 {
+		args = append(args, pq.Array(forever))
+		conditions = append(conditions, fmt.Sprintf("event_type = ANY($%d)", len(args)))
+	}
+	if cutoffs.Default == nil {
+		if len(ruled) == 0 {
+			return "TRUE", args
+		}
+		args = append(args, pq.Array(ruled))
+		conditions = append(conditions, fmt.Sprintf("NOT event_type = ANY($%d)", len(args)))
+	}
+
+	if len(conditions) == 0 {
+		return "FALSE", args
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// auditPartitionDetachTimeout bounds the wait for the lock detaching a partition takes on
+// audit_logs; queries queue up behind a waiting detach, so it gives up and retries next run
+const auditPartitionDetachTimeout = "5s"
+
+// dropExpiredAuditPartitions drops the monthly partitions that hold only entries that have
+// outlived the cutoffs, which costs far less than deleting their rows, and returns how many
+// entries went per event type. Each partition is detached in its own short transaction and
+// dropped after it, so audit_logs is never locked for more than one detach.
+func (r *auditRepository) dropExpiredAuditPartitions(ctx context.Context, cutoffs domain.AuditRetentionCutoffs) (map[domain.AuditEventType]int64, error) {
+	deleted := make(map[domain.AuditEventType]int64)
+
+	// Only a partition ending before every cutoff can have nothing left within retention
+	earliest, ok := cutoffs.Earliest()
+	if !ok {
+		return deleted, nil
+	}
+
+	partitions, err := listAuditPartitions(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, partition := range partitions {
+		if partition.end.After(earliest) {
+			break
+		}
+
+		counts, err := r.detachExpiredAuditPartition(ctx, partition, cutoffs)
+		if err != nil {
+			return nil, err
+		}
+		if counts == nil {
+			continue
+		}
+
+		// Detached, the partition no longer takes part in audit_logs queries
+		if _, err := r.db.ExecContext(ctx, fmt.Sprintf(`DROP TABLE %s`, pq.QuoteIdentifier(partition.name))); err != nil {
+			return nil, fmt.Errorf("failed to drop detached audit partition %s: %w", partition.name, err)
+		}
+
+		var total int64
+		for eventType, count := range counts {
+			deleted[eventType] += count
+			total += count
+		}
+		log.Printf("Dropped audit partition %s with %d entries", partition.name, total)
+	}
+
+	return deleted, nil
+}
+
+// detachExpiredAuditPartition detaches the partition if none of its entries are within
+// retention and returns the entries that went per event type, or nil if it stays. With the
+// hash chain the partition goes only if its chained entries all come before the first one
+// still within retention; chained entries up to its last one that sit in other partitions are
+// deleted along with it, and a checkpoint is recorded.
+func (r *auditRepository) detachExpiredAuditPartition(ctx context.Context, partition auditPartition, cutoffs domain.AuditRetentionCutoffs) (map[domain.AuditEventType]int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			log.Printf("Error rolling back transaction: %v", rollbackErr)
+		}
+	}()
+
+	if err := lockAuditPartitions(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	// Another replica may have dropped it since the partitions were listed
+	var attached bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM pg_inherits WHERE inhrelid = to_regclass($1) AND inhparent = 'audit_logs'::regclass)`,
+		partition.name).Scan(&attached)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up audit partition %s: %w", partition.name, err)
+	}
+	if !attached {
+		return nil, nil
+	}
+
+	table := pq.QuoteIdentifier(partition.name)
+	kept, keptArgs := auditRetentionKept(cutoffs, nil)
+
+	var retained bool
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE %s)`, table, kept), keptArgs...).Scan(&retained)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect audit partition %s: %w", partition.name, err)
+	}
+	if retained {
+		return nil, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT event_type, COUNT(*) FROM %s GROUP BY event_type`, table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count audit partition %s: %w", partition.name, err)
+	}
+	deleted, err := scanEventTypeCounts(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.chained {
+		boundary, err := chainPruneBoundary(ctx, tx, cutoffs)
+		if err != nil {
+			return nil, err
+		}
+
+		var lastSeq int64
+		var lastHash string
+		err = tx.QueryRowContext(ctx, fmt.Sprintf(`
+			SELECT chain_seq, hash FROM %s
+			WHERE chain_seq IS NOT NULL
+			ORDER BY chain_seq DESC
+			LIMIT 1`, table)).Scan(&lastSeq, &lastHash)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to read audit partition %s chain: %w", partition.name, err)
+		}
+		if err == nil {
+			if lastSeq > boundary {
+				return nil, nil
+			}
+
+			// Chained entries before its last one may sit in partitions that are kept
+			rows, err := tx.QueryContext(ctx, `
+				WITH deleted AS (
+					DELETE FROM audit_logs
+					WHERE chain_seq <= $1 AND (created_at < $2 OR created_at >= $3)
+					RETURNING event_type
+				)
+				SELECT event_type, COUNT(*) FROM deleted GROUP BY event_type`, lastSeq, partition.start, partition.end)
+			if err != nil {
+				return nil, fmt.Errorf("failed to prune chained audit logs: %w", err)
+			}
+			counts, err := scanEventTypeCounts(rows)
+			if err != nil {
+				return nil, err
+			}
+
+			var total int64
+			for eventType, count := range counts {
+				deleted[eventType] += count
+			}
+			for _, count := range deleted {
+				total += count
+			}
+			if err := r.recordCheckpoint(ctx, tx, lastSeq, lastHash, cutoffs.Latest(), total); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Detaching locks audit_logs exclusively, so it comes last and the lock is held only until
+	// the commit. DETACH ... CONCURRENTLY would avoid the lock but is not allowed while
+	// audit_logs has a default partition.
+	if _, err := tx.ExecContext(ctx, `SET LOCAL lock_timeout = '`+auditPartitionDetachTimeout+`'`); err != nil {
+		return nil, fmt.Errorf("failed to set lock timeout: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE audit_logs DETACH PARTITION %s`, table)); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "55P03" {
+			log.Printf("Skipping audit partition %s: audit_logs stayed busy for %s", partition.name, auditPartitionDetachTimeout)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to detach audit partition %s: %w", partition.name, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return deleted, nil
+}
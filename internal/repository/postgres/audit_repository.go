@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 	"time"
 
@@ -21,37 +22,52 @@ import (
 
 type auditRepository struct {
 	db *sqlx.DB
+
+	// chained links every stored entry into the hash chain; checkpointKey signs the
+	// checkpoints recorded when chained entries are pruned
+	chained       bool
+	checkpointKey []byte
 }
 
 func NewAuditRepository(db *sqlx.DB) repository.AuditRepository {
 	return &auditRepository{db: db}
 }
 
-func (r *auditRepository) Log(ctx context.Context, auditLog *domain.AuditLog) error {
-	query := `
+// NewHashChainedAuditRepository returns an audit repository that links every stored entry
+// to the one stored before it
+func NewHashChainedAuditRepository(db *sqlx.DB, checkpointKey []byte) repository.AuditRepository {
+	return &auditRepository{db: db, chained: true, checkpointKey: checkpointKey}
+}
+
+const insertAuditLogQuery = `
 		INSERT INTO audit_logs (
 			id, event_type, event_name, description, batch_id, message_id, request_id,
 			http_method, endpoint, status_code, duration_ms, message_count, 
-			success_count, failure_count, metadata, created_at
+			success_count, failure_count, metadata, created_at, chain_seq, prev_hash, hash
 		) VALUES (
 			:id, :event_type, :event_name, :description, :batch_id, :message_id, :request_id,
 			:http_method, :endpoint, :status_code, :duration_ms, :message_count,
-			:success_count, :failure_count, :metadata, :created_at
-		)`
+			:success_count, :failure_count, :metadata, :created_at, :chain_seq, :prev_hash, :hash
+		)
+		ON CONFLICT DO NOTHING`
+
+const selectAuditLogColumns = `
+			id, event_type, event_name, description, batch_id, message_id, request_id,
+			http_method, endpoint, status_code, duration_ms, message_count, 
+			success_count, failure_count, metadata, created_at, chain_seq, prev_hash, hash`
 
+// auditLogParams returns the named parameters for insertAuditLogQuery
+func auditLogParams(auditLog *domain.AuditLog) (map[string]interface{}, error) {
 	var metadataJSON interface{}
 	if len(auditLog.Metadata) > 0 {
 		jsonBytes, err := json.Marshal(auditLog.Metadata)
 		if err != nil {
-			return fmt.Errorf("failed to marshal metadata: %w", err)
+			return nil, fmt.Errorf("failed to marshal metadata: %w", err)
 		}
 		metadataJSON = jsonBytes
-	} else {
-		metadataJSON = nil
 	}
 
-	// Create a map for named parameters
-	params := map[string]interface{}{
+	return map[string]interface{}{
 		"id":            auditLog.ID,
 		"event_type":    auditLog.EventType,
 		"event_name":    auditLog.EventName,
@@ -68,9 +84,50 @@ func (r *auditRepository) Log(ctx context.Context, auditLog *domain.AuditLog) er
 		"failure_count": auditLog.FailureCount,
 		"metadata":      metadataJSON,
 		"created_at":    auditLog.CreatedAt,
+		"chain_seq":     nil,
+		"prev_hash":     nil,
+		"hash":          nil,
+	}, nil
+}
+
+// scanAuditLog scans a row selected with selectAuditLogColumns
+func scanAuditLog(row interface{ Scan(...interface{}) error }) (*domain.AuditLog, error) {
+	auditLog := &domain.AuditLog{}
+	var metadataJSON []byte
+
+	err := row.Scan(
+		&auditLog.ID, &auditLog.EventType, &auditLog.EventName, &auditLog.Description,
+		&auditLog.BatchID, &auditLog.MessageID, &auditLog.RequestID,
+		&auditLog.HTTPMethod, &auditLog.Endpoint, &auditLog.StatusCode,
+		&auditLog.DurationMs, &auditLog.MessageCount, &auditLog.SuccessCount,
+		&auditLog.FailureCount, &metadataJSON, &auditLog.CreatedAt,
+		&auditLog.ChainSeq, &auditLog.PrevHash, &auditLog.Hash,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse metadata JSON
+	if metadataJSON != nil {
+		if err := json.Unmarshal(metadataJSON, &auditLog.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		}
 	}
 
-	_, err := r.db.NamedExecContext(ctx, query, params)
+	return auditLog, nil
+}
+
+func (r *auditRepository) Log(ctx context.Context, auditLog *domain.AuditLog) error {
+	if r.chained {
+		return r.logChained(ctx, []*domain.AuditLog{auditLog})
+	}
+
+	params, err := auditLogParams(auditLog)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.NamedExecContext(ctx, insertAuditLogQuery, params)
 	if err != nil {
 		return fmt.Errorf("failed to insert audit log: %w", err)
 	}
@@ -82,6 +139,9 @@ func (r *auditRepository) LogBatch(ctx context.Context, auditLogs []*domain.Audi
 	if len(auditLogs) == 0 {
 		return nil
 	}
+	if r.chained {
+		return r.logChained(ctx, auditLogs)
+	}
 
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -94,52 +154,93 @@ func (r *auditRepository) LogBatch(ctx context.Context, auditLogs []*domain.Audi
 		}
 	}()
 
-	query := `
-		INSERT INTO audit_logs (
-			id, event_type, event_name, description, batch_id, message_id, request_id,
-			http_method, endpoint, status_code, duration_ms, message_count, 
-			success_count, failure_count, metadata, created_at
-		) VALUES (
-			:id, :event_type, :event_name, :description, :batch_id, :message_id, :request_id,
-			:http_method, :endpoint, :status_code, :duration_ms, :message_count,
-			:success_count, :failure_count, :metadata, :created_at
-		)`
+	for _, auditLog := range auditLogs {
+		params, err := auditLogParams(auditLog)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NamedExecContext(ctx, insertAuditLogQuery, params)
+		if err != nil {
+			return fmt.Errorf("failed to insert audit log: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// logChained stores entries while holding the chain head lock, linking each one to the
+// entry stored before it. Entries whose ID is already stored are skipped and do not take
+// a place in the chain.
+func (r *auditRepository) logChained(ctx context.Context, auditLogs []*domain.AuditLog) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			log.Printf("Error rolling back transaction: %v", rollbackErr)
+		}
+	}()
+
+	var seq int64
+	var prevHash string
+	var keyedFrom sql.NullInt64
+	err = tx.QueryRowContext(ctx, `SELECT chain_seq, hash, keyed_from FROM audit_chain_head WHERE id = 1 FOR UPDATE`).Scan(&seq, &prevHash, &keyedFrom)
+	if err != nil {
+		return fmt.Errorf("failed to lock audit chain head: %w", err)
+	}
+	// Entries chained before the head was first signed keep their plain link hashes
+	if !keyedFrom.Valid {
+		keyedFrom = sql.NullInt64{Int64: seq + 1, Valid: true}
+	}
 
 	for _, auditLog := range auditLogs {
-		var metadataJSON interface{}
-		if len(auditLog.Metadata) > 0 {
-			jsonBytes, err := json.Marshal(auditLog.Metadata)
-			if err != nil {
-				return fmt.Errorf("failed to marshal metadata: %w", err)
-			}
-			metadataJSON = jsonBytes
-		} else {
-			metadataJSON = nil
-		}
-
-		params := map[string]interface{}{
-			"id":            auditLog.ID,
-			"event_type":    auditLog.EventType,
-			"event_name":    auditLog.EventName,
-			"description":   auditLog.Description,
-			"batch_id":      auditLog.BatchID,
-			"message_id":    auditLog.MessageID,
-			"request_id":    auditLog.RequestID,
-			"http_method":   auditLog.HTTPMethod,
-			"endpoint":      auditLog.Endpoint,
-			"status_code":   auditLog.StatusCode,
-			"duration_ms":   auditLog.DurationMs,
-			"message_count": auditLog.MessageCount,
-			"success_count": auditLog.SuccessCount,
-			"failure_count": auditLog.FailureCount,
-			"metadata":      metadataJSON,
-			"created_at":    auditLog.CreatedAt,
-		}
-
-		_, err = tx.NamedExecContext(ctx, query, params)
+		// Hash the timestamp exactly as the database will store it
+		auditLog.CreatedAt = auditLog.CreatedAt.Truncate(time.Microsecond)
+
+		hash, err := r.AuditChainHash(auditLog, seq+1, prevHash, seq+1 >= keyedFrom.Int64)
+		if err != nil {
+			return fmt.Errorf("failed to hash audit log: %w", err)
+		}
+
+		params, err := auditLogParams(auditLog)
+		if err != nil {
+			return err
+		}
+		params["chain_seq"] = seq + 1
+		params["prev_hash"] = prevHash
+		params["hash"] = hash
+
+		result, err := tx.NamedExecContext(ctx, insertAuditLogQuery, params)
 		if err != nil {
 			return fmt.Errorf("failed to insert audit log: %w", err)
 		}
+		if inserted, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		} else if inserted == 0 {
+			continue
+		}
+
+		seq++
+		chainSeq, linkedPrev := seq, prevHash
+		auditLog.ChainSeq = &chainSeq
+		auditLog.PrevHash = &linkedPrev
+		auditLog.Hash = &hash
+		prevHash = hash
+	}
+
+	head := &domain.AuditChainHead{ChainSeq: seq, Hash: prevHash, KeyedFrom: keyedFrom.Int64}
+	head.Sign(r.checkpointKey)
+	_, err = tx.ExecContext(ctx, `
+		UPDATE audit_chain_head SET chain_seq = $1, hash = $2, keyed_from = $3, signature = $4
+		WHERE id = 1`, head.ChainSeq, head.Hash, head.KeyedFrom, head.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to advance audit chain head: %w", err)
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -149,17 +250,21 @@ func (r *auditRepository) LogBatch(ctx context.Context, auditLogs []*domain.Audi
 	return nil
 }
 
-func (r *auditRepository) GetAuditLogs(ctx context.Context, filter *domain.AuditLogFilter) ([]*domain.AuditLog, error) {
-	query := `
-		SELECT 
-			id, event_type, event_name, description, batch_id, message_id, request_id,
-			http_method, endpoint, status_code, duration_ms, message_count, 
-			success_count, failure_count, metadata, created_at
-		FROM audit_logs`
+// auditSearchVector is the text searched by AuditLogFilter.Search; it matches the expression
+// of the idx_audit_logs_search index
+const auditSearchVector = "to_tsvector('simple', event_name || ' ' || coalesce(description, ''))"
 
+// auditLogWhere builds the WHERE clause for the filter's criteria; limit and offset are
+// left to the caller, whose placeholders continue after the returned args
+func auditLogWhere(filter *domain.AuditLogFilter) (string, []interface{}, error) {
 	var conditions []string
 	var args []interface{}
-	argIndex := 1
+
+	// add appends a condition whose single placeholder is numbered after the args so far
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
 
 	if filter != nil {
 		if len(filter.EventTypes) > 0 {
@@ -167,53 +272,75 @@ func (r *auditRepository) GetAuditLogs(ctx context.Context, filter *domain.Audit
 			for i, et := range filter.EventTypes {
 				eventTypes[i] = string(et)
 			}
-			conditions = append(conditions, fmt.Sprintf("event_type = ANY($%d)", argIndex))
-			args = append(args, pq.Array(eventTypes))
-			argIndex++
+			add("event_type = ANY($%d)", pq.Array(eventTypes))
 		}
 
 		if filter.BatchID != nil {
-			conditions = append(conditions, fmt.Sprintf("batch_id = $%d", argIndex))
-			args = append(args, *filter.BatchID)
-			argIndex++
+			add("batch_id = $%d", *filter.BatchID)
 		}
 
 		if filter.MessageID != nil {
-			conditions = append(conditions, fmt.Sprintf("message_id = $%d", argIndex))
-			args = append(args, *filter.MessageID)
-			argIndex++
+			add("message_id = $%d", *filter.MessageID)
 		}
 
 		if filter.RequestID != nil {
-			conditions = append(conditions, fmt.Sprintf("request_id = $%d", argIndex))
-			args = append(args, *filter.RequestID)
-			argIndex++
+			add("request_id = $%d", *filter.RequestID)
 		}
 
 		if filter.Endpoint != nil {
-			conditions = append(conditions, fmt.Sprintf("endpoint = $%d", argIndex))
-			args = append(args, *filter.Endpoint)
-			argIndex++
+			add("endpoint = $%d", *filter.Endpoint)
 		}
 
 		if filter.FromDate != nil {
-			conditions = append(conditions, fmt.Sprintf("created_at >= $%d", argIndex))
-			args = append(args, *filter.FromDate)
-			argIndex++
+			add("created_at >= $%d", *filter.FromDate)
 		}
 
 		if filter.ToDate != nil {
-			conditions = append(conditions, fmt.Sprintf("created_at <= $%d", argIndex))
-			args = append(args, *filter.ToDate)
-			argIndex++
+			add("created_at <= $%d", *filter.ToDate)
+		}
+
+		if len(filter.Metadata) > 0 {
+			metadata, err := json.Marshal(filter.Metadata)
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to marshal metadata filter: %w", err)
+			}
+			add("metadata @> $%d::jsonb", string(metadata))
+		}
+
+		if filter.Search != nil {
+			add(auditSearchVector+" @@ websearch_to_tsquery('simple', $%d)", *filter.Search)
 		}
 	}
 
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
+	if len(conditions) == 0 {
+		return "", args, nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+func (r *auditRepository) GetAuditLogs(ctx context.Context, filter *domain.AuditLogFilter) ([]*domain.AuditLog, error) {
+	query := `
+		SELECT ` + selectAuditLogColumns + `
+		FROM audit_logs`
+
+	where, args, err := auditLogWhere(filter)
+	if err != nil {
+		return nil, err
+	}
+	query += where
+
+	if filter != nil && filter.Cursor != nil {
+		if where == "" {
+			query += " WHERE "
+		} else {
+			query += " AND "
+		}
+		query += fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)+1, len(args)+2)
+		args = append(args, filter.Cursor.Time, filter.Cursor.ID)
 	}
+	argIndex := len(args) + 1
 
-	query += " ORDER BY created_at DESC"
+	query += " ORDER BY created_at DESC, id DESC"
 
 	if filter != nil {
 		if filter.Limit > 0 {
@@ -236,28 +363,10 @@ func (r *auditRepository) GetAuditLogs(ctx context.Context, filter *domain.Audit
 
 	var auditLogs []*domain.AuditLog
 	for rows.Next() {
-		auditLog := &domain.AuditLog{}
-		var metadataJSON []byte
-
-		err := rows.Scan(
-			&auditLog.ID, &auditLog.EventType, &auditLog.EventName, &auditLog.Description,
-			&auditLog.BatchID, &auditLog.MessageID, &auditLog.RequestID,
-			&auditLog.HTTPMethod, &auditLog.Endpoint, &auditLog.StatusCode,
-			&auditLog.DurationMs, &auditLog.MessageCount, &auditLog.SuccessCount,
-			&auditLog.FailureCount, &metadataJSON, &auditLog.CreatedAt,
-		)
+		auditLog, err := scanAuditLog(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan audit log: %w", err)
 		}
-
-		// Parse metadata JSON
-		if metadataJSON != nil {
-			err = json.Unmarshal(metadataJSON, &auditLog.Metadata)
-			if err != nil {
-				return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
-			}
-		}
-
 		auditLogs = append(auditLogs, auditLog)
 	}
 
@@ -268,25 +377,127 @@ func (r *auditRepository) GetAuditLogs(ctx context.Context, filter *domain.Audit
 	return auditLogs, nil
 }
 
+// auditExportFetchSize is how many rows are fetched from the export cursor at a time
+const auditExportFetchSize = 1000
+
+func (r *auditRepository) StreamAuditLogs(ctx context.Context, filter *domain.AuditLogFilter, fn func(*domain.AuditLog) error) error {
+	return r.streamAuditLogs(ctx, filter, nil, fn)
+}
+
+func (r *auditRepository) ExportAuditLogs(ctx context.Context, filter *domain.AuditLogFilter, header func(metadataKeys []string) error, fn func(*domain.AuditLog) error) error {
+	return r.streamAuditLogs(ctx, filter, header, fn)
+}
+
+// streamAuditLogs reads the export cursor, first passing the metadata keys to header when it
+// is set. Both run in one REPEATABLE READ transaction, so they see the same entries.
+func (r *auditRepository) streamAuditLogs(ctx context.Context, filter *domain.AuditLogFilter, header func([]string) error, fn func(*domain.AuditLog) error) error {
+	where, args, err := auditLogWhere(filter)
+	if err != nil {
+		return err
+	}
+
+	// Cursors only live inside a transaction
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			log.Printf("Error rolling back transaction: %v", rollbackErr)
+		}
+	}()
+
+	if header != nil {
+		keys, err := auditMetadataKeys(ctx, tx, where, args)
+		if err != nil {
+			return err
+		}
+		if err := header(keys); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		DECLARE audit_export NO SCROLL CURSOR FOR
+		SELECT ` + selectAuditLogColumns + `
+		FROM audit_logs` + where + `
+		ORDER BY created_at, id`
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to open audit export cursor: %w", err)
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM audit_export", auditExportFetchSize)
+	for {
+		fetched, err := r.fetchAuditLogs(ctx, tx, fetch, fn)
+		if err != nil {
+			return err
+		}
+		if fetched < auditExportFetchSize {
+			return nil
+		}
+	}
+}
+
+// fetchAuditLogs runs one FETCH against the export cursor and returns how many rows it read
+func (r *auditRepository) fetchAuditLogs(ctx context.Context, tx *sqlx.Tx, fetch string, fn func(*domain.AuditLog) error) (int, error) {
+	rows, err := tx.QueryContext(ctx, fetch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch from audit export cursor: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Error closing rows: %v", closeErr)
+		}
+	}()
+
+	fetched := 0
+	for rows.Next() {
+		auditLog, err := scanAuditLog(rows)
+		if err != nil {
+			return fetched, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		fetched++
+		if err := fn(auditLog); err != nil {
+			return fetched, err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fetched, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return fetched, nil
+}
+
+// auditMetadataKeys returns the sorted top-level metadata keys of the entries matching where
+func auditMetadataKeys(ctx context.Context, q sqlx.QueryerContext, where string, args []interface{}) ([]string, error) {
+	if where == "" {
+		where = " WHERE metadata IS NOT NULL"
+	} else {
+		where += " AND metadata IS NOT NULL"
+	}
+
+	query := `
+		SELECT DISTINCT jsonb_object_keys(metadata) AS key
+		FROM audit_logs` + where + `
+		ORDER BY key`
+
+	var keys []string
+	if err := sqlx.SelectContext(ctx, q, &keys, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get audit metadata keys: %w", err)
+	}
+
+	return keys, nil
+}
+
 func (r *auditRepository) GetAuditLogByID(ctx context.Context, id string) (*domain.AuditLog, error) {
 	query := `
-		SELECT 
-			id, event_type, event_name, description, batch_id, message_id, request_id,
-			http_method, endpoint, status_code, duration_ms, message_count, 
-			success_count, failure_count, metadata, created_at
+		SELECT ` + selectAuditLogColumns + `
 		FROM audit_logs 
 		WHERE id = $1`
 
-	auditLog := &domain.AuditLog{}
-	var metadataJSON []byte
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&auditLog.ID, &auditLog.EventType, &auditLog.EventName, &auditLog.Description,
-		&auditLog.BatchID, &auditLog.MessageID, &auditLog.RequestID,
-		&auditLog.HTTPMethod, &auditLog.Endpoint, &auditLog.StatusCode,
-		&auditLog.DurationMs, &auditLog.MessageCount, &auditLog.SuccessCount,
-		&auditLog.FailureCount, &metadataJSON, &auditLog.CreatedAt,
-	)
+	auditLog, err := scanAuditLog(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("audit log not found")
@@ -294,12 +505,21 @@ func (r *auditRepository) GetAuditLogByID(ctx context.Context, id string) (*doma
 		return nil, fmt.Errorf("failed to get audit log: %w", err)
 	}
 
-	// Parse metadata JSON
-	if metadataJSON != nil {
-		err = json.Unmarshal(metadataJSON, &auditLog.Metadata)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	return auditLog, nil
+}
+
+func (r *auditRepository) GetAuditLogByRef(ctx context.Context, ref repository.AuditLogRef) (*domain.AuditLog, error) {
+	query := `
+		SELECT ` + selectAuditLogColumns + `
+		FROM audit_logs
+		WHERE id = $1 AND created_at = $2`
+
+	auditLog, err := scanAuditLog(r.db.QueryRowContext(ctx, query, ref.ID, ref.CreatedAt))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("audit log not found")
 		}
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
 	}
 
 	return auditLog, nil
@@ -409,10 +629,97 @@ func (r *auditRepository) GetAuditLogStats(ctx context.Context, filter *domain.A
 	return stats, nil
 }
 
+func (r *auditRepository) GetAuditLogTimeSeries(ctx context.Context, filter *domain.AuditLogFilter, interval domain.AuditStatsInterval) ([]*domain.AuditStatsBucket, error) {
+	where, args, err := auditLogWhere(filter)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, string(interval))
+
+	// Buckets are truncated in UTC so day buckets do not depend on the session time zone;
+	// percentile_cont skips rows without a duration and is NULL when none has one
+	query := fmt.Sprintf(`
+		SELECT
+			date_trunc($%d, created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS bucket,
+			event_type,
+			COUNT(*),
+			COUNT(duration_ms),
+			percentile_cont(0.50) WITHIN GROUP (ORDER BY duration_ms),
+			percentile_cont(0.95) WITHIN GROUP (ORDER BY duration_ms),
+			percentile_cont(0.99) WITHIN GROUP (ORDER BY duration_ms)
+		FROM audit_logs%s
+		GROUP BY bucket, event_type
+		ORDER BY bucket`, len(args), where)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log time series: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []*domain.AuditStatsBucket
+	for rows.Next() {
+		var start time.Time
+		var eventType string
+		var count, durationCount int64
+		var p50, p95, p99 sql.NullFloat64
+		if err := rows.Scan(&start, &eventType, &count, &durationCount, &p50, &p95, &p99); err != nil {
+			return nil, fmt.Errorf("failed to scan audit log time series: %w", err)
+		}
+
+		// Rows arrive grouped by bucket
+		start = start.UTC()
+		if len(buckets) == 0 || !buckets[len(buckets)-1].Start.Equal(start) {
+			buckets = append(buckets, &domain.AuditStatsBucket{
+				Start:           start,
+				EventTypeCounts: make(map[domain.AuditEventType]int64),
+			})
+		}
+		bucket := buckets[len(buckets)-1]
+
+		bucket.EventTypeCounts[domain.AuditEventType(eventType)] = count
+		bucket.TotalCount += count
+		if durationCount > 0 {
+			if bucket.Durations == nil {
+				bucket.Durations = make(map[domain.AuditEventType]*domain.AuditDurationPercentiles)
+			}
+			bucket.Durations[domain.AuditEventType(eventType)] = &domain.AuditDurationPercentiles{
+				Count: durationCount,
+				P50:   p50.Float64,
+				P95:   p95.Float64,
+				P99:   p99.Float64,
+			}
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return buckets, nil
+}
+
 func (r *auditRepository) DeleteOldAuditLogs(ctx context.Context, days int) (int64, error) {
-	query := `DELETE FROM audit_logs WHERE created_at < $1`
 	cutoffDate := time.Now().AddDate(0, 0, -days)
 
+	// Partitions that end before the cutoff are dropped whole; only the rows of the
+	// partition the cutoff falls in are deleted one by one
+	dropped, err := r.dropExpiredAuditPartitions(ctx, domain.AuditRetentionCutoffs{Default: &cutoffDate})
+	if err != nil {
+		return 0, err
+	}
+	var droppedCount int64
+	for _, count := range dropped {
+		droppedCount += count
+	}
+
+	if r.chained {
+		rowsAffected, err := r.pruneChained(ctx, cutoffDate)
+		return droppedCount + rowsAffected, err
+	}
+
+	query := `DELETE FROM audit_logs WHERE created_at < $1`
+
 	result, err := r.db.ExecContext(ctx, query, cutoffDate)
 	if err != nil {
 		return 0, fmt.Errorf("failed to delete old audit logs: %w", err)
@@ -423,5 +730,389 @@ func (r *auditRepository) DeleteOldAuditLogs(ctx context.Context, days int) (int
 		return 0, fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
+	return droppedCount + rowsAffected, nil
+}
+
+// pruneChained deletes entries created before cutoff without leaving holes in the chain:
+// chained entries go only up to the first one that is still within retention. The last
+// chained entry deleted is recorded in a signed checkpoint in the same transaction.
+func (r *auditRepository) pruneChained(ctx context.Context, cutoff time.Time) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			log.Printf("Error rolling back transaction: %v", rollbackErr)
+		}
+	}()
+
+	// Hold the head so no entry is chained while the boundary is worked out
+	var headSeq int64
+	if err := tx.QueryRowContext(ctx, `SELECT chain_seq FROM audit_chain_head WHERE id = 1 FOR UPDATE`).Scan(&headSeq); err != nil {
+		return 0, fmt.Errorf("failed to lock audit chain head: %w", err)
+	}
+
+	var boundary int64
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(MIN(chain_seq) - 1, $2)
+		FROM audit_logs
+		WHERE chain_seq IS NOT NULL AND created_at >= $1`, cutoff, headSeq).Scan(&boundary)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find audit chain prune boundary: %w", err)
+	}
+
+	var boundaryHash string
+	err = tx.QueryRowContext(ctx, `SELECT hash FROM audit_logs WHERE chain_seq = $1`, boundary).Scan(&boundaryHash)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to read audit chain boundary entry: %w", err)
+	}
+	// No boundary entry means every chained entry up to it is already gone
+	pruneChain := err == nil
+
+	result, err := tx.ExecContext(ctx, `
+		DELETE FROM audit_logs
+		WHERE (chain_seq IS NULL AND created_at < $1) OR chain_seq <= $2`, cutoff, boundary)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old audit logs: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if pruneChain {
+		if err := r.recordCheckpoint(ctx, tx, boundary, boundaryHash, cutoff, rowsAffected); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return rowsAffected, nil
 }
+
+// recordCheckpoint stores a signed checkpoint for the last chained entry deleted
+func (r *auditRepository) recordCheckpoint(ctx context.Context, tx *sqlx.Tx, chainSeq int64, hash string, prunedBefore time.Time, deletedCount int64) error {
+	checkpoint := &domain.AuditChainCheckpoint{
+		ChainSeq:     chainSeq,
+		Hash:         hash,
+		PrunedBefore: prunedBefore.Truncate(time.Microsecond),
+		DeletedCount: deletedCount,
+		CreatedAt:    time.Now().Truncate(time.Microsecond),
+	}
+	checkpoint.Sign(r.checkpointKey)
+
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO audit_chain_checkpoints (chain_seq, hash, pruned_before, deleted_count, signature, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		checkpoint.ChainSeq, checkpoint.Hash, checkpoint.PrunedBefore, checkpoint.DeletedCount,
+		checkpoint.Signature, checkpoint.CreatedAt,
+	).Scan(&checkpoint.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record audit chain checkpoint: %w", err)
+	}
+	log.Printf("Recorded audit chain checkpoint %d at chain sequence %d", checkpoint.ID, checkpoint.ChainSeq)
+
+	return nil
+}
+
+// auditRetentionExpired builds the condition matching entries that have outlived the
+// cutoffs; its placeholders are numbered after args
+func auditRetentionExpired(cutoffs domain.AuditRetentionCutoffs, args []interface{}) (string, []interface{}) {
+	var conditions []string
+
+	eventTypes := make([]string, 0, len(cutoffs.EventTypes))
+	for eventType := range cutoffs.EventTypes {
+		eventTypes = append(eventTypes, string(eventType))
+	}
+	sort.Strings(eventTypes)
+
+	for _, eventType := range eventTypes {
+		cutoff := cutoffs.EventTypes[domain.AuditEventType(eventType)]
+		if cutoff.IsZero() {
+			continue
+		}
+		args = append(args, eventType, cutoff)
+		conditions = append(conditions, fmt.Sprintf("(event_type = $%d AND created_at < $%d)", len(args)-1, len(args)))
+	}
+
+	if cutoffs.Default != nil {
+		if len(eventTypes) > 0 {
+			args = append(args, pq.Array(eventTypes), *cutoffs.Default)
+			conditions = append(conditions, fmt.Sprintf("(NOT event_type = ANY($%d) AND created_at < $%d)", len(args)-1, len(args)))
+		} else {
+			args = append(args, *cutoffs.Default)
+			conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "FALSE", args
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// scanEventTypeCounts reads (event_type, count) rows
+func scanEventTypeCounts(rows *sql.Rows) (map[domain.AuditEventType]int64, error) {
+	defer rows.Close()
+
+	counts := make(map[domain.AuditEventType]int64)
+	for rows.Next() {
+		var eventType string
+		var count int64
+		if err := rows.Scan(&eventType, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan event type count: %w", err)
+		}
+		counts[domain.AuditEventType(eventType)] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (r *auditRepository) PruneAuditLogs(ctx context.Context, cutoffs domain.AuditRetentionCutoffs, limit int) (map[domain.AuditEventType]int64, error) {
+	// Whole expired partitions go first, however many entries they hold; they do not count
+	// against limit since dropping them does not touch any rows
+	dropped, err := r.dropExpiredAuditPartitions(ctx, cutoffs)
+	if err != nil {
+		return nil, err
+	}
+
+	expired, args := auditRetentionExpired(cutoffs, nil)
+
+	// Chained entries can only go from the start of the chain; see pruneChainedBatch
+	scope := ""
+	if r.chained {
+		scope = "chain_seq IS NULL AND "
+	}
+
+	args = append(args, limit)
+	query := fmt.Sprintf(`
+		WITH deleted AS (
+			DELETE FROM audit_logs
+			WHERE (id, created_at) IN (SELECT id, created_at FROM audit_logs WHERE %s%s LIMIT $%d)
+			RETURNING event_type
+		)
+		SELECT event_type, COUNT(*) FROM deleted GROUP BY event_type`, scope, expired, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune audit logs: %w", err)
+	}
+	deleted, err := scanEventTypeCounts(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, count := range deleted {
+		total += count
+	}
+	if r.chained && total < int64(limit) {
+		chainDeleted, err := r.pruneChainedBatch(ctx, cutoffs, limit-int(total))
+		if err != nil {
+			return nil, err
+		}
+		for eventType, count := range chainDeleted {
+			deleted[eventType] += count
+		}
+	}
+
+	for eventType, count := range dropped {
+		deleted[eventType] += count
+	}
+	return deleted, nil
+}
+
+// chainPruneBoundary locks the chain head, so no entry is chained until the transaction ends,
+// and returns the last chain sequence before the first chained entry still within retention
+func chainPruneBoundary(ctx context.Context, tx *sqlx.Tx, cutoffs domain.AuditRetentionCutoffs) (int64, error) {
+	var headSeq int64
+	if err := tx.QueryRowContext(ctx, `SELECT chain_seq FROM audit_chain_head WHERE id = 1 FOR UPDATE`).Scan(&headSeq); err != nil {
+		return 0, fmt.Errorf("failed to lock audit chain head: %w", err)
+	}
+
+	expired, args := auditRetentionExpired(cutoffs, nil)
+	args = append(args, headSeq)
+	var boundary int64
+	err := tx.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT COALESCE(MIN(chain_seq) - 1, $%d)
+		FROM audit_logs
+		WHERE chain_seq IS NOT NULL AND NOT %s`, len(args), expired),
+		args...).Scan(&boundary)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find audit chain prune boundary: %w", err)
+	}
+
+	return boundary, nil
+}
+
+// pruneChainedBatch deletes up to limit chained entries from the start of the chain, stopping
+// before the first one still within retention so no hole is left. An expired entry behind a
+// retained one waits until every entry before it has expired too.
+func (r *auditRepository) pruneChainedBatch(ctx context.Context, cutoffs domain.AuditRetentionCutoffs, limit int) (map[domain.AuditEventType]int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			log.Printf("Error rolling back transaction: %v", rollbackErr)
+		}
+	}()
+
+	boundary, err := chainPruneBoundary(ctx, tx, cutoffs)
+	if err != nil {
+		return nil, err
+	}
+
+	var firstSeq sql.NullInt64
+	err = tx.QueryRowContext(ctx, `SELECT MIN(chain_seq) FROM audit_logs WHERE chain_seq IS NOT NULL`).Scan(&firstSeq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find first chained audit log: %w", err)
+	}
+	if !firstSeq.Valid || firstSeq.Int64 > boundary {
+		return nil, nil
+	}
+
+	upTo := min(boundary, firstSeq.Int64+int64(limit)-1)
+	var upToHash string
+	if err := tx.QueryRowContext(ctx, `SELECT hash FROM audit_logs WHERE chain_seq = $1`, upTo).Scan(&upToHash); err != nil {
+		return nil, fmt.Errorf("failed to read audit chain boundary entry: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		WITH deleted AS (
+			DELETE FROM audit_logs WHERE chain_seq <= $1
+			RETURNING event_type
+		)
+		SELECT event_type, COUNT(*) FROM deleted GROUP BY event_type`, upTo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune chained audit logs: %w", err)
+	}
+	deleted, err := scanEventTypeCounts(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, count := range deleted {
+		total += count
+	}
+	if err := r.recordCheckpoint(ctx, tx, upTo, upToHash, cutoffs.Latest(), total); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return deleted, nil
+}
+
+func (r *auditRepository) GetAuditChainBounds(ctx context.Context, from, to *time.Time) (int64, int64, error) {
+	var firstSeq, lastSeq sql.NullInt64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT MIN(chain_seq), MAX(chain_seq)
+		FROM audit_logs
+		WHERE chain_seq IS NOT NULL
+		  AND ($1::timestamptz IS NULL OR created_at >= $1)
+		  AND ($2::timestamptz IS NULL OR created_at <= $2)`, from, to).Scan(&firstSeq, &lastSeq)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get audit chain bounds: %w", err)
+	}
+
+	return firstSeq.Int64, lastSeq.Int64, nil
+}
+
+func (r *auditRepository) GetAuditChainEntries(ctx context.Context, fromSeq, toSeq int64, limit int) ([]*domain.AuditLog, error) {
+	query := `
+		SELECT ` + selectAuditLogColumns + `
+		FROM audit_logs
+		WHERE chain_seq BETWEEN $1 AND $2
+		ORDER BY chain_seq
+		LIMIT $3`
+
+	rows, err := r.db.QueryContext(ctx, query, fromSeq, toSeq, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit chain: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Error closing rows: %v", closeErr)
+		}
+	}()
+
+	var auditLogs []*domain.AuditLog
+	for rows.Next() {
+		auditLog, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		auditLogs = append(auditLogs, auditLog)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return auditLogs, nil
+}
+
+func (r *auditRepository) GetAuditChainCheckpoint(ctx context.Context, chainSeq int64) (*domain.AuditChainCheckpoint, error) {
+	checkpoint := &domain.AuditChainCheckpoint{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, chain_seq, hash, pruned_before, deleted_count, signature, created_at
+		FROM audit_chain_checkpoints
+		WHERE chain_seq = $1
+		ORDER BY id DESC
+		LIMIT 1`, chainSeq).Scan(
+		&checkpoint.ID, &checkpoint.ChainSeq, &checkpoint.Hash, &checkpoint.PrunedBefore,
+		&checkpoint.DeletedCount, &checkpoint.Signature, &checkpoint.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get audit chain checkpoint: %w", err)
+	}
+
+	checkpoint.SignatureValid = checkpoint.VerifySignature(r.checkpointKey)
+	return checkpoint, nil
+}
+
+func (r *auditRepository) GetAuditChainHead(ctx context.Context) (*domain.AuditChainHead, error) {
+	head := &domain.AuditChainHead{}
+	var keyedFrom sql.NullInt64
+	var signature sql.NullString
+	err := r.db.QueryRowContext(ctx, `
+		SELECT chain_seq, hash, keyed_from, signature
+		FROM audit_chain_head
+		WHERE id = 1`).Scan(&head.ChainSeq, &head.Hash, &keyedFrom, &signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit chain head: %w", err)
+	}
+
+	// A head never signed has only plain link hashes behind it
+	head.KeyedFrom = head.ChainSeq + 1
+	if keyedFrom.Valid {
+		head.KeyedFrom = keyedFrom.Int64
+	}
+	head.Signature = signature.String
+	head.SignatureValid = head.VerifySignature(r.checkpointKey)
+	return head, nil
+}
+
+func (r *auditRepository) AuditChainHash(auditLog *domain.AuditLog, seq int64, prevHash string, keyed bool) (string, error) {
+	if !keyed {
+		return auditLog.ChainHash(seq, prevHash, nil)
+	}
+	return auditLog.ChainHash(seq, prevHash, r.checkpointKey)
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+)
+
+type batchResultRepository struct {
+	db *sql.DB
+}
+
+func NewBatchResultRepository(db *sql.DB) repository.BatchResultRepository {
+	return &batchResultRepository{db: db}
+}
+
+func (r *batchResultRepository) SaveBatchResult(ctx context.Context, result *domain.BatchResult, keep int) error {
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch result: %w", err)
+	}
+
+	query := `
+		INSERT INTO batch_results (batch_id, instance_id, started_at, result)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (batch_id) DO NOTHING
+	`
+	if _, err := r.db.ExecContext(ctx, query, result.BatchID, result.InstanceID, result.StartedAt, resultJSON); err != nil {
+		return fmt.Errorf("failed to save batch result: %w", err)
+	}
+
+	pruneQuery := `
+		DELETE FROM batch_results
+		WHERE batch_id IN (
+			SELECT batch_id FROM batch_results
+			ORDER BY started_at DESC
+			OFFSET $1
+		)
+	`
+	if _, err := r.db.ExecContext(ctx, pruneQuery, keep); err != nil {
+		return fmt.Errorf("failed to prune batch results: %w", err)
+	}
+
+	return nil
+}
+
+func (r *batchResultRepository) GetRecentBatchResults(ctx context.Context, limit int) ([]*domain.BatchResult, error) {
+	query := `
+		SELECT result
+		FROM batch_results
+		ORDER BY started_at DESC
+		LIMIT $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch results: %w", err)
+	}
+	defer rows.Close()
+
+	results := []*domain.BatchResult{}
+	for rows.Next() {
+		var resultJSON []byte
+		if err := rows.Scan(&resultJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan batch result: %w", err)
+		}
+		var result domain.BatchResult
+		if err := json.Unmarshal(resultJSON, &result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal batch result: %w", err)
+		}
+		results = append(results, &result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get batch results: %w", err)
+	}
+
+	return results, nil
+}
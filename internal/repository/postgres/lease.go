@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+)
+
+type leaseRepository struct {
+	db *sql.DB
+}
+
+func NewLeaseRepository(db *sql.DB) repository.LeaseRepository {
+	return &leaseRepository{db: db}
+}
+
+func (r *leaseRepository) TryAcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	// The upsert only succeeds when the lease is free, expired, or already ours
+	query := `
+		INSERT INTO scheduler_leases (name, holder, acquired_at, renewed_at, expires_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP + $3 * INTERVAL '1 millisecond')
+		ON CONFLICT (name) DO UPDATE SET
+			holder = EXCLUDED.holder,
+			acquired_at = CASE
+				WHEN scheduler_leases.holder = EXCLUDED.holder THEN scheduler_leases.acquired_at
+				ELSE EXCLUDED.acquired_at
+			END,
+			renewed_at = EXCLUDED.renewed_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE scheduler_leases.holder = EXCLUDED.holder OR scheduler_leases.expires_at < CURRENT_TIMESTAMP
+		RETURNING holder
+	`
+
+	var acquiredBy string
+	err := r.db.QueryRowContext(ctx, query, name, holder, ttl.Milliseconds()).Scan(&acquiredBy)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to acquire lease: %w", err)
+	}
+
+	return acquiredBy == holder, nil
+}
+
+func (r *leaseRepository) ReleaseLease(ctx context.Context, name, holder string) error {
+	query := `DELETE FROM scheduler_leases WHERE name = $1 AND holder = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, name, holder); err != nil {
+		return fmt.Errorf("failed to release lease: %w", err)
+	}
+
+	return nil
+}
+
+func (r *leaseRepository) GetLease(ctx context.Context, name string) (*domain.LeaderLease, error) {
+	query := `
+		SELECT holder, acquired_at, expires_at
+		FROM scheduler_leases
+		WHERE name = $1 AND expires_at >= CURRENT_TIMESTAMP
+	`
+
+	lease := &domain.LeaderLease{}
+	err := r.db.QueryRowContext(ctx, query, name).Scan(&lease.Holder, &lease.AcquiredAt, &lease.ExpiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get lease: %w", err)
+	}
+
+	return lease, nil
+}
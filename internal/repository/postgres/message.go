@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log"
 	"time"
 
 	"ims/internal/domain"
@@ -22,48 +23,85 @@ func NewMessageRepository(db *sql.DB) repository.MessageRepository {
 	return &messageRepository{db: db}
 }
 
-func (r *messageRepository) GetUnsentMessages(ctx context.Context, limit int) ([]*domain.Message, error) {
-	query := `
-		SELECT id, phone_number, content, status, message_id, retry_count, created_at, sent_at, updated_at
-		FROM messages 
-		WHERE status = 'pending'
-		ORDER BY created_at ASC
-		LIMIT $1
-	`
+// messageColumns lists the columns read by scanMessage, in scan order
+const messageColumns = `id, phone_number, content, status, message_id, retry_count, created_at, sent_at, updated_at,
+			idempotency_key, COALESCE(idempotency_hash, ''), next_attempt_at, last_error, claimed_by, claimed_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
-	rows, err := r.db.QueryContext(ctx, query, limit)
+func scanMessage(row rowScanner) (*domain.Message, error) {
+	msg := &domain.Message{}
+	err := row.Scan(
+		&msg.ID,
+		&msg.PhoneNumber,
+		&msg.Content,
+		&msg.Status,
+		&msg.MessageID,
+		&msg.RetryCount,
+		&msg.CreatedAt,
+		&msg.SentAt,
+		&msg.UpdatedAt,
+		&msg.IdempotencyKey,
+		&msg.IdempotencyHash,
+		&msg.NextAttemptAt,
+		&msg.LastError,
+		&msg.ClaimedBy,
+		&msg.ClaimedAt,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query unsent messages: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
+	return msg, nil
+}
 
+func scanMessages(rows *sql.Rows) ([]*domain.Message, error) {
 	var messages []*domain.Message
 	for rows.Next() {
-		msg := &domain.Message{}
-		err := rows.Scan(
-			&msg.ID,
-			&msg.PhoneNumber,
-			&msg.Content,
-			&msg.Status,
-			&msg.MessageID,
-			&msg.RetryCount,
-			&msg.CreatedAt,
-			&msg.SentAt,
-			&msg.UpdatedAt,
-		)
+		msg, err := scanMessage(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan message: %w", err)
 		}
 		messages = append(messages, msg)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows iteration error: %w", err)
 	}
 
 	return messages, nil
 }
 
+func (r *messageRepository) ClaimMessages(ctx context.Context, owner string, limit int) ([]*domain.Message, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE messages 
+			SET status = 'sending', claimed_by = $1, claimed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			WHERE id IN (
+				SELECT id FROM messages
+				WHERE status = 'pending' AND (next_attempt_at IS NULL OR next_attempt_at <= CURRENT_TIMESTAMP)
+				ORDER BY COALESCE(next_attempt_at, created_at) ASC
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT ` + messageColumns + `
+		FROM claimed
+		ORDER BY COALESCE(next_attempt_at, created_at) ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, owner, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim messages: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMessages(rows)
+}
+
 func (r *messageRepository) UpdateMessageStatus(ctx context.Context, id uuid.UUID, status domain.MessageStatus, messageID *string) error {
 	var query string
 	var args []interface{}
@@ -71,7 +109,7 @@ func (r *messageRepository) UpdateMessageStatus(ctx context.Context, id uuid.UUI
 	if status == domain.StatusSent && messageID != nil {
 		query = `
 			UPDATE messages 
-			SET status = $1, message_id = $2, sent_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			SET status = $1, message_id = $2, sent_at = CURRENT_TIMESTAMP, next_attempt_at = NULL, updated_at = CURRENT_TIMESTAMP
 			WHERE id = $3
 		`
 		args = []interface{}{status, *messageID, id}
@@ -101,12 +139,88 @@ func (r *messageRepository) UpdateMessageStatus(ctx context.Context, id uuid.UUI
 	return nil
 }
 
+// RecordFailedAttempt increments retry_count and stores the error. With a non-nil
+// nextAttemptAt the message is re-queued as pending until then; otherwise it is marked failed.
+func (r *messageRepository) RecordFailedAttempt(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt *time.Time) error {
+	query := `
+		UPDATE messages 
+		SET retry_count = retry_count + 1,
+			last_error = $1,
+			next_attempt_at = $2,
+			status = CASE WHEN $2::timestamptz IS NULL THEN 'failed'::message_status ELSE 'pending'::message_status END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`
+
+	result, err := r.db.ExecContext(ctx, query, lastError, nextAttemptAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to record failed attempt: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrMessageNotFound
+	}
+
+	return nil
+}
+
+// RecoverStuckMessages returns messages that entered 'sending' before stuckBefore to
+// 'pending' and reports them. A send in flight holds no row lock, so the lease must outlast
+// the batch timeout; rows locked by a concurrent recovery or status update are skipped.
+func (r *messageRepository) RecoverStuckMessages(ctx context.Context, stuckBefore time.Time) ([]*domain.Message, error) {
+	query := `
+		UPDATE messages 
+		SET status = 'pending', claimed_by = NULL, claimed_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id IN (
+			SELECT id FROM messages
+			WHERE status = 'sending' AND COALESCE(claimed_at, updated_at) < $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + messageColumns
+
+	rows, err := r.db.QueryContext(ctx, query, stuckBefore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover stuck messages: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMessages(rows)
+}
+
+func (r *messageRepository) ReleaseClaims(ctx context.Context, owner string, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	idStrings := make([]string, len(ids))
+	for i, id := range ids {
+		idStrings[i] = id.String()
+	}
+
+	query := `
+		UPDATE messages 
+		SET status = 'pending', claimed_by = NULL, claimed_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ANY($1::uuid[]) AND status = 'sending' AND claimed_by = $2
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, pq.Array(idStrings), owner); err != nil {
+		return fmt.Errorf("failed to release message claims: %w", err)
+	}
+
+	return nil
+}
+
 func (r *messageRepository) GetSentMessages(ctx context.Context, offset, limit int) ([]*domain.Message, error) {
 	query := `
-		SELECT id, phone_number, content, status, message_id, retry_count, created_at, sent_at, updated_at
+		SELECT ` + messageColumns + `
 		FROM messages 
 		WHERE status = 'sent'
-		ORDER BY sent_at DESC
+		ORDER BY sent_at DESC, id DESC
 		LIMIT $1 OFFSET $2
 	`
 
@@ -116,53 +230,39 @@ func (r *messageRepository) GetSentMessages(ctx context.Context, offset, limit i
 	}
 	defer rows.Close()
 
-	var messages []*domain.Message
-	for rows.Next() {
-		msg := &domain.Message{}
-		err := rows.Scan(
-			&msg.ID,
-			&msg.PhoneNumber,
-			&msg.Content,
-			&msg.Status,
-			&msg.MessageID,
-			&msg.RetryCount,
-			&msg.CreatedAt,
-			&msg.SentAt,
-			&msg.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan message: %w", err)
-		}
-		messages = append(messages, msg)
+	return scanMessages(rows)
+}
+
+func (r *messageRepository) GetSentMessagesAfter(ctx context.Context, cursor *domain.PageCursor, limit int) ([]*domain.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE status = 'sent'`
+	args := []interface{}{limit}
+
+	if cursor != nil {
+		query += " AND (sent_at, id) < ($2, $3)"
+		args = append(args, cursor.Time, cursor.ID)
 	}
+	query += " ORDER BY sent_at DESC, id DESC LIMIT $1"
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows iteration error: %w", err)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sent messages: %w", err)
 	}
+	defer rows.Close()
 
-	return messages, nil
+	return scanMessages(rows)
 }
 
 func (r *messageRepository) GetMessage(ctx context.Context, id uuid.UUID) (*domain.Message, error) {
 	query := `
-		SELECT id, phone_number, content, status, message_id, retry_count, created_at, sent_at, updated_at
+		SELECT ` + messageColumns + `
 		FROM messages 
 		WHERE id = $1
 	`
 
-	msg := &domain.Message{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&msg.ID,
-		&msg.PhoneNumber,
-		&msg.Content,
-		&msg.Status,
-		&msg.MessageID,
-		&msg.RetryCount,
-		&msg.CreatedAt,
-		&msg.SentAt,
-		&msg.UpdatedAt,
-	)
-
+	msg, err := scanMessage(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrMessageNotFound
@@ -175,8 +275,8 @@ func (r *messageRepository) GetMessage(ctx context.Context, id uuid.UUID) (*doma
 
 func (r *messageRepository) CreateMessage(ctx context.Context, message *domain.Message) error {
 	query := `
-		INSERT INTO messages (id, phone_number, content, status, retry_count, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO messages (id, phone_number, content, status, retry_count, created_at, updated_at, idempotency_key, idempotency_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''))
 	`
 
 	if message.ID == uuid.Nil {
@@ -203,12 +303,17 @@ func (r *messageRepository) CreateMessage(ctx context.Context, message *domain.M
 		message.RetryCount,
 		message.CreatedAt,
 		message.UpdatedAt,
+		message.IdempotencyKey,
+		message.IdempotencyHash,
 	)
 
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code {
 			case "23505": // unique_violation
+				if pqErr.Constraint == "idx_messages_idempotency_key" {
+					return domain.ErrDuplicateIdempotencyKey
+				}
 				return fmt.Errorf("message with this ID already exists: %w", err)
 			}
 		}
@@ -218,6 +323,109 @@ func (r *messageRepository) CreateMessage(ctx context.Context, message *domain.M
 	return nil
 }
 
+func (r *messageRepository) GetMessageByIdempotencyKey(ctx context.Context, key string) (*domain.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages 
+		WHERE idempotency_key = $1
+	`
+
+	msg, err := scanMessage(r.db.QueryRowContext(ctx, query, key))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrMessageNotFound
+		}
+		return nil, fmt.Errorf("failed to get message by idempotency key: %w", err)
+	}
+
+	return msg, nil
+}
+
+// ReleaseIdempotencyKey frees a key held by a message created before the given time,
+// allowing it to be reused once its idempotency window has expired
+func (r *messageRepository) ReleaseIdempotencyKey(ctx context.Context, key string, createdBefore time.Time) error {
+	query := `
+		UPDATE messages 
+		SET idempotency_key = NULL, idempotency_hash = NULL
+		WHERE idempotency_key = $1 AND created_at < $2
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, key, createdBefore); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// CreateMessages inserts all messages in a single transaction using COPY,
+// so either every message is persisted or none are
+func (r *messageRepository) CreateMessages(ctx context.Context, messages []*domain.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			log.Printf("Error rolling back transaction: %v", rollbackErr)
+		}
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("messages",
+		"id", "phone_number", "content", "status", "retry_count", "created_at", "updated_at"))
+	if err != nil {
+		return fmt.Errorf("failed to prepare copy statement: %w", err)
+	}
+
+	now := time.Now()
+	for _, message := range messages {
+		if message.ID == uuid.Nil {
+			message.ID = uuid.New()
+		}
+		if message.CreatedAt.IsZero() {
+			message.CreatedAt = now
+		}
+		if message.UpdatedAt.IsZero() {
+			message.UpdatedAt = now
+		}
+		if message.Status == "" {
+			message.Status = domain.StatusPending
+		}
+
+		if _, err := stmt.ExecContext(ctx,
+			message.ID,
+			message.PhoneNumber,
+			message.Content,
+			string(message.Status),
+			message.RetryCount,
+			message.CreatedAt,
+			message.UpdatedAt,
+		); err != nil {
+			_ = stmt.Close()
+			return fmt.Errorf("failed to copy message: %w", err)
+		}
+	}
+
+	// Flush buffered rows
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		_ = stmt.Close()
+		return fmt.Errorf("failed to flush copied messages: %w", err)
+	}
+
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("failed to close copy statement: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 func NewDB(databaseURL string, maxConnections, maxIdleConnections int) (*sql.DB, error) {
 	db, err := sql.Open("postgres", databaseURL)
 	if err != nil {
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+)
+
+type schedulerStateRepository struct {
+	db *sql.DB
+}
+
+func NewSchedulerStateRepository(db *sql.DB) repository.SchedulerStateRepository {
+	return &schedulerStateRepository{db: db}
+}
+
+// desiredStateColumns is the stored state as read back by every query below
+const desiredStateColumns = `desired_running, COALESCE(interval_ms, 0), COALESCE(batch_size, 0), changed_by, changed_at`
+
+func (r *schedulerStateRepository) GetDesiredState(ctx context.Context) (*domain.SchedulerDesiredState, error) {
+	query := `SELECT ` + desiredStateColumns + ` FROM scheduler_state WHERE id = 1`
+
+	state, err := scanDesiredState(r.db.QueryRowContext(ctx, query))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get scheduler state: %w", err)
+	}
+
+	return state, nil
+}
+
+// SetDesiredRunning and SetDesiredSettings each update only their own columns in a single
+// statement, so a stop issued on one replica and a configure issued on another cannot
+// undo each other
+func (r *schedulerStateRepository) SetDesiredRunning(ctx context.Context, running bool, changedBy string) (*domain.SchedulerDesiredState, error) {
+	query := `
+		INSERT INTO scheduler_state (id, desired_running, changed_by, changed_at)
+		VALUES (1, $1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT (id) DO UPDATE SET
+			desired_running = EXCLUDED.desired_running,
+			changed_by = EXCLUDED.changed_by,
+			changed_at = EXCLUDED.changed_at
+		RETURNING ` + desiredStateColumns
+
+	state, err := scanDesiredState(r.db.QueryRowContext(ctx, query, running, changedBy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set scheduler run state: %w", err)
+	}
+
+	return state, nil
+}
+
+func (r *schedulerStateRepository) SetDesiredSettings(ctx context.Context, intervalMs int64, batchSize int, initialRunning bool, changedBy string) (*domain.SchedulerDesiredState, error) {
+	query := `
+		INSERT INTO scheduler_state (id, desired_running, interval_ms, batch_size, changed_by, changed_at)
+		VALUES (1, $1, NULLIF($2, 0), NULLIF($3, 0), $4, CURRENT_TIMESTAMP)
+		ON CONFLICT (id) DO UPDATE SET
+			interval_ms = EXCLUDED.interval_ms,
+			batch_size = EXCLUDED.batch_size,
+			changed_by = EXCLUDED.changed_by,
+			changed_at = EXCLUDED.changed_at
+		RETURNING ` + desiredStateColumns
+
+	state, err := scanDesiredState(r.db.QueryRowContext(ctx, query, initialRunning, intervalMs, batchSize, changedBy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set scheduler settings: %w", err)
+	}
+
+	return state, nil
+}
+
+func scanDesiredState(row rowScanner) (*domain.SchedulerDesiredState, error) {
+	state := &domain.SchedulerDesiredState{}
+	if err := row.Scan(&state.Running, &state.IntervalMs, &state.BatchSize, &state.ChangedBy, &state.ChangedAt); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
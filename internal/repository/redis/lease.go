@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// acquireLeaseScript sets the lease when it is free, or extends it when the caller already
+// holds it, keeping the original acquisition time. KEYS[1] is the lease key, ARGV[1] the
+// holder, ARGV[2] the TTL in milliseconds and ARGV[3] the encoded lease for a new holder.
+var acquireLeaseScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current then
+	local lease = cjson.decode(current)
+	if lease.holder ~= ARGV[1] then
+		return 0
+	end
+	redis.call('SET', KEYS[1], current, 'PX', ARGV[2])
+	return 1
+end
+redis.call('SET', KEYS[1], ARGV[3], 'PX', ARGV[2])
+return 1
+`)
+
+// releaseLeaseScript deletes the lease only if the caller holds it
+var releaseLeaseScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current and cjson.decode(current).holder == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+type leaseRepository struct {
+	client *redis.Client
+}
+
+func NewLeaseRepository(client *redis.Client) repository.LeaseRepository {
+	return &leaseRepository{client: client}
+}
+
+type storedLease struct {
+	Holder     string    `json:"holder"`
+	AcquiredAt time.Time `json:"acquired_at"`
+}
+
+func leaseKey(name string) string {
+	return "lease:" + name
+}
+
+func (r *leaseRepository) TryAcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	value, err := json.Marshal(storedLease{Holder: holder, AcquiredAt: time.Now()})
+	if err != nil {
+		return false, err
+	}
+
+	acquired, err := acquireLeaseScript.Run(ctx, r.client, []string{leaseKey(name)}, holder, ttl.Milliseconds(), value).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return acquired == 1, nil
+}
+
+func (r *leaseRepository) ReleaseLease(ctx context.Context, name, holder string) error {
+	return releaseLeaseScript.Run(ctx, r.client, []string{leaseKey(name)}, holder).Err()
+}
+
+func (r *leaseRepository) GetLease(ctx context.Context, name string) (*domain.LeaderLease, error) {
+	key := leaseKey(name)
+
+	result, err := r.client.Get(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var stored storedLease
+	if err := json.Unmarshal([]byte(result), &stored); err != nil {
+		return nil, err
+	}
+
+	ttl, err := r.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	if ttl < 0 {
+		// The key expired between GET and PTTL
+		return nil, nil
+	}
+
+	return &domain.LeaderLease{
+		Holder:     stored.Holder,
+		AcquiredAt: stored.AcquiredAt,
+		ExpiresAt:  time.Now().Add(ttl),
+	}, nil
+}
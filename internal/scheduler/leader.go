@@ -0,0 +1,147 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+)
+
+// SchedulerLeaseName is the lease that elects the replica allowed to process batches
+const SchedulerLeaseName = "scheduler"
+
+// RetentionLeaseName is the lease that elects the replica allowed to prune audit logs
+const RetentionLeaseName = "audit_retention"
+
+// LeaderElector campaigns for a named lease so that only one replica does the lease's work.
+// The lease is renewed at a third of its TTL; if the leader dies, another replica takes
+// over once the lease expires.
+type LeaderElector struct {
+	repo       repository.LeaseRepository
+	name       string
+	instanceID string
+	ttl        time.Duration
+
+	isLeader int32
+	mu       sync.Mutex
+	done     chan struct{}
+	running  bool
+	wg       sync.WaitGroup
+}
+
+func NewLeaderElector(repo repository.LeaseRepository, instanceID string, ttl time.Duration) *LeaderElector {
+	return NewNamedLeaderElector(repo, SchedulerLeaseName, instanceID, ttl)
+}
+
+// NewNamedLeaderElector returns an elector campaigning for the lease called name
+func NewNamedLeaderElector(repo repository.LeaseRepository, name, instanceID string, ttl time.Duration) *LeaderElector {
+	return &LeaderElector{
+		repo:       repo,
+		name:       name,
+		instanceID: instanceID,
+		ttl:        ttl,
+	}
+}
+
+// Start makes a first acquisition attempt synchronously and then keeps campaigning in
+// the background until Stop is called
+func (e *LeaderElector) Start() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.running {
+		return
+	}
+
+	e.done = make(chan struct{})
+	e.running = true
+
+	e.campaign()
+
+	e.wg.Add(1)
+	go e.run()
+}
+
+// Stop ends the campaign and releases the lease if this instance holds it, so another
+// replica can take over without waiting for the lease to expire
+func (e *LeaderElector) Stop() {
+	e.mu.Lock()
+	if !e.running {
+		e.mu.Unlock()
+		return
+	}
+	close(e.done)
+	e.running = false
+	e.mu.Unlock()
+
+	e.wg.Wait()
+
+	if atomic.SwapInt32(&e.isLeader, 0) == 1 {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := e.repo.ReleaseLease(ctx, e.name, e.instanceID); err != nil {
+			log.Printf("Failed to release %s leadership: %v", e.name, err)
+		} else {
+			log.Printf("Instance %s released %s leadership", e.instanceID, e.name)
+		}
+	}
+}
+
+func (e *LeaderElector) IsLeader() bool {
+	return atomic.LoadInt32(&e.isLeader) == 1
+}
+
+func (e *LeaderElector) InstanceID() string {
+	return e.instanceID
+}
+
+// Leader returns the lease of the replica currently holding leadership, or nil if the
+// lease is free
+func (e *LeaderElector) Leader(ctx context.Context) (*domain.LeaderLease, error) {
+	return e.repo.GetLease(ctx, e.name)
+}
+
+func (e *LeaderElector) run() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-ticker.C:
+			e.campaign()
+		}
+	}
+}
+
+func (e *LeaderElector) campaign() {
+	ctx, cancel := context.WithTimeout(context.Background(), e.ttl/3)
+	defer cancel()
+
+	acquired, err := e.repo.TryAcquireLease(ctx, e.name, e.instanceID, e.ttl)
+	if err != nil {
+		// Without a confirmed renewal we must assume another replica may take over
+		log.Printf("Failed to acquire %s leadership: %v", e.name, err)
+		acquired = false
+	}
+
+	var state int32
+	if acquired {
+		state = 1
+	}
+
+	if previous := atomic.SwapInt32(&e.isLeader, state); previous != state {
+		if acquired {
+			log.Printf("Instance %s became %s leader", e.instanceID, e.name)
+		} else {
+			log.Printf("Instance %s lost %s leadership", e.instanceID, e.name)
+		}
+	}
+}
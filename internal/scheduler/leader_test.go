@@ -0,0 +1,161 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ims/internal/repository"
+)
+
+// A TTL long enough that the background renewal never fires during a test; campaign is
+// called directly instead
+const testLeaseTTL = 30 * time.Second
+
+func startElector(t *testing.T, repo repository.LeaseRepository, instanceID string) *LeaderElector {
+	t.Helper()
+	elector := NewLeaderElector(repo, instanceID, testLeaseTTL)
+	elector.Start()
+	t.Cleanup(elector.Stop)
+	return elector
+}
+
+func TestLeaderElector_Acquire(t *testing.T) {
+	repo := repository.NewMockLeaseRepository()
+
+	first := startElector(t, repo, "replica-a")
+	second := startElector(t, repo, "replica-b")
+
+	if !first.IsLeader() {
+		t.Error("Expected the first replica to become leader")
+	}
+	if second.IsLeader() {
+		t.Error("Expected the second replica not to become leader")
+	}
+
+	lease, err := second.Leader(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if lease == nil || lease.Holder != "replica-a" {
+		t.Errorf("Expected replica-a to hold the lease, got %+v", lease)
+	}
+}
+
+func TestLeaderElector_Renew(t *testing.T) {
+	repo := repository.NewMockLeaseRepository()
+	elector := startElector(t, repo, "replica-a")
+
+	before, err := elector.Leader(context.Background())
+	if err != nil || before == nil {
+		t.Fatalf("Expected a lease, got %+v, %v", before, err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	elector.campaign()
+
+	after, err := elector.Leader(context.Background())
+	if err != nil || after == nil {
+		t.Fatalf("Expected a lease, got %+v, %v", after, err)
+	}
+	if !elector.IsLeader() {
+		t.Error("Expected the replica to stay leader after renewing")
+	}
+	if !after.ExpiresAt.After(before.ExpiresAt) {
+		t.Errorf("Expected the renewal to extend the lease past %v, got %v", before.ExpiresAt, after.ExpiresAt)
+	}
+	if !after.AcquiredAt.Equal(before.AcquiredAt) {
+		t.Errorf("Expected the renewal to keep the acquisition time %v, got %v", before.AcquiredAt, after.AcquiredAt)
+	}
+}
+
+func TestLeaderElector_LosesLeadershipOnRepositoryError(t *testing.T) {
+	repo := repository.NewMockLeaseRepository()
+	elector := startElector(t, repo, "replica-a")
+	if !elector.IsLeader() {
+		t.Fatal("Expected the replica to become leader")
+	}
+
+	repo.TryAcquireLeaseFunc = func(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+		return false, errors.New("connection refused")
+	}
+	elector.campaign()
+
+	if elector.IsLeader() {
+		t.Error("Expected the replica to give up leadership when the renewal fails")
+	}
+}
+
+func TestLeaderElector_TakeoverAfterExpiry(t *testing.T) {
+	repo := repository.NewMockLeaseRepository()
+	first := startElector(t, repo, "replica-a")
+	second := startElector(t, repo, "replica-b")
+
+	// replica-a stops renewing and its lease runs out
+	repo.Expire(SchedulerLeaseName)
+	second.campaign()
+	first.campaign()
+
+	if !second.IsLeader() {
+		t.Error("Expected replica-b to take over the expired lease")
+	}
+	if first.IsLeader() {
+		t.Error("Expected replica-a to notice it lost leadership")
+	}
+}
+
+func TestLeaderElector_StepDown(t *testing.T) {
+	repo := repository.NewMockLeaseRepository()
+	first := NewLeaderElector(repo, "replica-a", testLeaseTTL)
+	first.Start()
+	second := startElector(t, repo, "replica-b")
+
+	first.Stop()
+
+	if first.IsLeader() {
+		t.Error("Expected the stopped replica not to be leader")
+	}
+	lease, err := repo.GetLease(context.Background(), SchedulerLeaseName)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if lease != nil {
+		t.Errorf("Expected the lease to be released, got %+v", lease)
+	}
+
+	// No need to wait for the lease to expire
+	second.campaign()
+	if !second.IsLeader() {
+		t.Error("Expected replica-b to take over right after the step-down")
+	}
+}
+
+func TestScheduler_SkipsBatchWhenNotLeader(t *testing.T) {
+	repo := repository.NewMockLeaseRepository()
+	startElector(t, repo, "replica-a")
+	follower := startElector(t, repo, "replica-b")
+
+	// A nil message service would panic if the batch were not skipped
+	s := NewScheduler(nil, nil, time.Minute, 10)
+	s.SetLeaderElector(follower)
+	s.processBatch(context.Background())
+
+	if batches, _ := s.RecentBatches(context.Background(), 10); len(batches) != 0 {
+		t.Errorf("Expected no batch on a follower, got %d", len(batches))
+	}
+}
+
+func TestLeaderElector_NamedLeasesAreIndependent(t *testing.T) {
+	repo := repository.NewMockLeaseRepository()
+
+	scheduler := startElector(t, repo, "replica-a")
+	retention := NewNamedLeaderElector(repo, RetentionLeaseName, "replica-b", testLeaseTTL)
+	retention.Start()
+	t.Cleanup(retention.Stop)
+
+	if !scheduler.IsLeader() || !retention.IsLeader() {
+		t.Errorf("Expected each replica to lead its own lease, got scheduler=%t retention=%t",
+			scheduler.IsLeader(), retention.IsLeader())
+	}
+}
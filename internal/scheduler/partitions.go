@@ -0,0 +1,101 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"ims/internal/repository"
+)
+
+// PartitionManager keeps the monthly audit log partitions created a few months ahead, so
+// entries never land in the default partition.
+type PartitionManager struct {
+	repo     repository.AuditPartitionRepository
+	ahead    int
+	interval time.Duration
+
+	mu      sync.Mutex
+	done    chan struct{}
+	running bool
+	wg      sync.WaitGroup
+}
+
+// NewPartitionManager returns a manager that creates partitions through ahead months after
+// the current one
+func NewPartitionManager(repo repository.AuditPartitionRepository, ahead int, interval time.Duration) *PartitionManager {
+	return &PartitionManager{
+		repo:     repo,
+		ahead:    ahead,
+		interval: interval,
+	}
+}
+
+func (m *PartitionManager) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		return
+	}
+
+	m.done = make(chan struct{})
+	m.running = true
+
+	m.wg.Add(1)
+	go m.run()
+
+	log.Printf("Audit partition manager started with %d months ahead, interval: %v", m.ahead, m.interval)
+}
+
+// Stop signals the manager to exit and waits for an in-progress run to finish
+func (m *PartitionManager) Stop() {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return
+	}
+	close(m.done)
+	m.running = false
+	m.mu.Unlock()
+
+	m.wg.Wait()
+	log.Println("Audit partition manager stopped")
+}
+
+func (m *PartitionManager) run() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	// Check immediately so a deployment that was down over a month boundary catches up on boot
+	m.ensure()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+			m.ensure()
+		}
+	}
+}
+
+func (m *PartitionManager) ensure() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	through := time.Date(now.Year(), now.Month()+time.Month(m.ahead), 1, 0, 0, 0, 0, time.UTC)
+
+	// Some months may be created even when others fail
+	created, err := m.repo.EnsureAuditPartitions(ctx, through)
+	for _, name := range created {
+		log.Printf("Created audit partition %s", name)
+	}
+	if err != nil {
+		log.Printf("Error creating audit partitions: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"ims/internal/service"
+)
+
+// Reaper periodically returns messages stranded in the sending state, e.g. after a crash
+// or a batch timeout, back to pending so the scheduler can pick them up again.
+type Reaper struct {
+	service      *service.MessageService
+	auditService service.AuditService
+	lease        time.Duration
+	interval     time.Duration
+
+	mu      sync.Mutex
+	done    chan struct{}
+	running bool
+	wg      sync.WaitGroup
+}
+
+func NewReaper(service *service.MessageService, auditService service.AuditService, lease, interval time.Duration) *Reaper {
+	return &Reaper{
+		service:      service,
+		auditService: auditService,
+		lease:        lease,
+		interval:     interval,
+	}
+}
+
+func (r *Reaper) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		return
+	}
+
+	r.done = make(chan struct{})
+	r.running = true
+
+	r.wg.Add(1)
+	go r.run()
+
+	log.Printf("Reaper started with lease: %v, interval: %v", r.lease, r.interval)
+}
+
+// Stop signals the reaper to exit and waits for an in-progress sweep to finish
+func (r *Reaper) Stop() {
+	r.mu.Lock()
+	if !r.running {
+		r.mu.Unlock()
+		return
+	}
+	close(r.done)
+	r.running = false
+	r.mu.Unlock()
+
+	r.wg.Wait()
+	log.Println("Reaper stopped")
+}
+
+func (r *Reaper) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	// Sweep immediately so messages stranded by a previous process are recovered on boot
+	r.reap()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.reap()
+		}
+	}
+}
+
+func (r *Reaper) reap() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	messages, err := r.service.RecoverStuckMessages(ctx, r.lease)
+	if err != nil {
+		log.Printf("Error recovering stuck messages: %v", err)
+		return
+	}
+
+	if len(messages) == 0 {
+		return
+	}
+
+	log.Printf("Recovered %d messages stuck in sending for more than %v", len(messages), r.lease)
+
+	if r.auditService == nil {
+		return
+	}
+	for _, msg := range messages {
+		if err := r.auditService.LogMessageRecovered(ctx, msg.ID, r.lease); err != nil {
+			log.Printf("Failed to log message recovered event: %v", err)
+		}
+	}
+}
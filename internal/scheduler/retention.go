@@ -0,0 +1,128 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"ims/internal/domain"
+	"ims/internal/service"
+)
+
+// RetentionPruner periodically deletes audit logs that have outlived the retention policy
+// and records every run in the audit log.
+type RetentionPruner struct {
+	auditService service.AuditService
+	policy       domain.AuditRetentionPolicy
+	interval     time.Duration
+	batchSize    int
+	elector      *LeaderElector
+
+	mu      sync.Mutex
+	done    chan struct{}
+	running bool
+	wg      sync.WaitGroup
+}
+
+func NewRetentionPruner(auditService service.AuditService, policy domain.AuditRetentionPolicy, interval time.Duration, batchSize int) *RetentionPruner {
+	return &RetentionPruner{
+		auditService: auditService,
+		policy:       policy,
+		interval:     interval,
+		batchSize:    batchSize,
+	}
+}
+
+// SetLeaderElector makes each run conditional on holding the retention lease, so only one
+// replica prunes at a time. The pruner campaigns for the lease while it is started.
+func (p *RetentionPruner) SetLeaderElector(elector *LeaderElector) {
+	p.elector = elector
+}
+
+func (p *RetentionPruner) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.running {
+		return
+	}
+
+	p.done = make(chan struct{})
+	p.running = true
+
+	if p.elector != nil {
+		p.elector.Start()
+	}
+
+	p.wg.Add(1)
+	go p.run()
+
+	log.Printf("Audit retention pruner started with policy: %s, interval: %v, batch size: %d", p.policy, p.interval, p.batchSize)
+}
+
+// Stop signals the pruner to exit and waits for an in-progress run to finish
+func (p *RetentionPruner) Stop() {
+	p.mu.Lock()
+	if !p.running {
+		p.mu.Unlock()
+		return
+	}
+	close(p.done)
+	p.running = false
+	p.mu.Unlock()
+
+	p.wg.Wait()
+	if p.elector != nil {
+		p.elector.Stop()
+	}
+	log.Println("Audit retention pruner stopped")
+}
+
+func (p *RetentionPruner) run() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	// Prune immediately so a backlog left while the service was down is not kept a full interval longer
+	p.prune()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.prune()
+		}
+	}
+}
+
+func (p *RetentionPruner) prune() {
+	if p.elector != nil && !p.elector.IsLeader() {
+		log.Printf("Skipping audit retention: instance %s is not the %s leader", p.elector.InstanceID(), RetentionLeaseName)
+		return
+	}
+
+	// A run ends early on Stop; the next one picks up where it left off
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-p.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	result, err := p.auditService.ApplyRetention(ctx, p.policy, p.batchSize)
+	if err != nil {
+		log.Printf("Error applying audit retention after deleting %d audit logs: %v", result.Total, err)
+	}
+
+	log.Printf("Audit retention pruned %d audit logs in %d batches (%dms)", result.Total, result.Batches, result.DurationMs)
+
+	if err := p.auditService.LogAuditPruned(context.WithoutCancel(ctx), p.policy, result); err != nil {
+		log.Printf("Failed to log audit pruned event: %v", err)
+	}
+}
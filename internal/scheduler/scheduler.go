@@ -4,6 +4,7 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"sync"
 	"sync/atomic"
@@ -12,14 +13,33 @@ import (
 	"github.com/google/uuid"
 
 	"ims/internal/domain"
+	"ims/internal/repository"
 	"ims/internal/service"
 )
 
+// Bounds for settings changed at runtime through the control API
+const (
+	MinInterval  = time.Second
+	MaxInterval  = 24 * time.Hour
+	MinBatchSize = 1
+	MaxBatchSize = 1000
+)
+
+// BatchHistorySize is how many recent batch results are kept, in the shared store when one
+// is set and in memory otherwise
+const BatchHistorySize = 100
+
+// minBatchTimeout is how long a batch may take at intervals up to a minute; longer
+// intervals allow half the interval
+const minBatchTimeout = 30 * time.Second
+
 type Scheduler struct {
 	service      *service.MessageService
 	auditService service.AuditService
 	interval     time.Duration
 	batchSize    int
+	sendingLease time.Duration
+	instanceID   string
 
 	mu        sync.Mutex
 	ticker    *time.Ticker
@@ -29,6 +49,13 @@ type Scheduler struct {
 	ctx       context.Context
 	cancel    context.CancelFunc
 	wg        sync.WaitGroup
+
+	elector   *LeaderElector
+	stateSync *StateSync
+
+	batchResults repository.BatchResultRepository
+	historyMu    sync.Mutex
+	history      []*domain.BatchResult
 }
 
 func NewScheduler(service *service.MessageService, auditService service.AuditService, interval time.Duration, batchSize int) *Scheduler {
@@ -40,6 +67,59 @@ func NewScheduler(service *service.MessageService, auditService service.AuditSer
 	}
 }
 
+// SetSendingLease sets how long a message may stay in the sending state before the reaper
+// takes it back; settings whose batches could outlast it are rejected
+func (s *Scheduler) SetSendingLease(lease time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sendingLease = lease
+}
+
+// SetInstanceID sets the ID recorded on the batch results of this replica
+func (s *Scheduler) SetInstanceID(instanceID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.instanceID = instanceID
+}
+
+// SetBatchResultRepository stores batch results where every replica can read them, so the
+// history is not limited to the batches this replica ran while it was leader
+func (s *Scheduler) SetBatchResultRepository(repo repository.BatchResultRepository) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batchResults = repo
+}
+
+// SetLeaderElector makes batch processing conditional on holding scheduler leadership,
+// so that only one of several replicas sends messages
+func (s *Scheduler) SetLeaderElector(elector *LeaderElector) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.elector = elector
+}
+
+// LeaderElector returns the elector coordinating this scheduler, or nil if leader
+// election is disabled
+func (s *Scheduler) LeaderElector() *LeaderElector {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.elector
+}
+
+// SetStateSync attaches the store of the desired run state shared by all replicas
+func (s *Scheduler) SetStateSync(stateSync *StateSync) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stateSync = stateSync
+}
+
+// StateSync returns the desired state store, or nil if the state is not persisted
+func (s *Scheduler) StateSync() *StateSync {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stateSync
+}
+
 func (s *Scheduler) Start(ctx context.Context) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -55,13 +135,16 @@ func (s *Scheduler) Start(ctx context.Context) error {
 
 	atomic.StoreInt32(&s.running, 1)
 
-	// Log scheduler started event
+	// Campaign for leadership only while this replica's scheduler is running
+	if s.elector != nil {
+		s.elector.Start()
+	}
+
+	// Log scheduler started event; ctx carries the ID of the request that triggered it, if any
 	if s.auditService != nil {
-		go func() {
-			if err := s.auditService.LogSchedulerStarted(context.Background()); err != nil {
-				log.Printf("Failed to log scheduler started event: %v", err)
-			}
-		}()
+		if err := s.auditService.LogSchedulerStarted(context.WithoutCancel(ctx)); err != nil {
+			log.Printf("Failed to log scheduler started event: %v", err)
+		}
 	}
 
 	// Use background context for scheduler operations, not the HTTP request context
@@ -77,7 +160,7 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	return nil
 }
 
-func (s *Scheduler) Stop() error {
+func (s *Scheduler) Stop(ctx context.Context) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -90,19 +173,95 @@ func (s *Scheduler) Stop() error {
 	atomic.StoreInt32(&s.running, 0)
 	s.startedAt = nil
 
+	if s.elector != nil {
+		s.elector.Stop()
+	}
+
 	// Log scheduler stopped event
 	if s.auditService != nil {
-		go func() {
-			if err := s.auditService.LogSchedulerStopped(context.Background()); err != nil {
-				log.Printf("Failed to log scheduler stopped event: %v", err)
-			}
-		}()
+		if err := s.auditService.LogSchedulerStopped(context.WithoutCancel(ctx)); err != nil {
+			log.Printf("Failed to log scheduler stopped event: %v", err)
+		}
 	}
 
 	log.Println("Scheduler stopped")
 	return nil
 }
 
+// ValidateSettings checks interval and batch size against the runtime bounds
+func ValidateSettings(interval time.Duration, batchSize int) error {
+	if interval < MinInterval || interval > MaxInterval {
+		return fmt.Errorf("%w: interval must be between %v and %v", domain.ErrInvalidSchedulerConfig, MinInterval, MaxInterval)
+	}
+	if batchSize < MinBatchSize || batchSize > MaxBatchSize {
+		return fmt.Errorf("%w: batch size must be between %d and %d", domain.ErrInvalidSchedulerConfig, MinBatchSize, MaxBatchSize)
+	}
+	return nil
+}
+
+// ValidateSettings checks interval and batch size against the runtime bounds and the
+// sending lease: a batch must end before the reaper could hand its messages to another one
+func (s *Scheduler) ValidateSettings(interval time.Duration, batchSize int) error {
+	if err := ValidateSettings(interval, batchSize); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	lease := s.sendingLease
+	s.mu.Unlock()
+
+	if timeout := BatchTimeout(interval); lease > 0 && timeout >= lease {
+		return fmt.Errorf("%w: batches at interval %v may take %v, which must be shorter than the sending lease %v",
+			domain.ErrInvalidSchedulerConfig, interval, timeout, lease)
+	}
+	return nil
+}
+
+// BatchTimeout is how long a batch may take at interval: half the interval, but at least
+// minBatchTimeout
+func BatchTimeout(interval time.Duration) time.Duration {
+	return max(interval/2, minBatchTimeout)
+}
+
+// Reconfigure changes the interval and batch size. A running scheduler keeps running: its
+// ticker is reset so the next batch follows the new interval, and the new batch size
+// applies from the next batch on.
+func (s *Scheduler) Reconfigure(ctx context.Context, interval time.Duration, batchSize int) error {
+	if err := s.ValidateSettings(interval, batchSize); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	previousInterval, previousBatchSize := s.interval, s.batchSize
+	if previousInterval == interval && previousBatchSize == batchSize {
+		s.mu.Unlock()
+		return nil
+	}
+
+	s.interval = interval
+	s.batchSize = batchSize
+	if atomic.LoadInt32(&s.running) == 1 {
+		s.ticker.Reset(interval)
+	}
+	s.mu.Unlock()
+
+	if s.auditService != nil {
+		if err := s.auditService.LogSchedulerReconfigured(context.WithoutCancel(ctx), previousInterval, interval, previousBatchSize, batchSize); err != nil {
+			log.Printf("Failed to log scheduler reconfigured event: %v", err)
+		}
+	}
+
+	log.Printf("Scheduler reconfigured: interval %v -> %v, batch size %d -> %d", previousInterval, interval, previousBatchSize, batchSize)
+	return nil
+}
+
+// Settings returns the current interval and batch size
+func (s *Scheduler) Settings() (time.Duration, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.interval, s.batchSize
+}
+
 func (s *Scheduler) IsRunning() bool {
 	return atomic.LoadInt32(&s.running) == 1
 }
@@ -119,7 +278,7 @@ func (s *Scheduler) run(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			log.Println("Scheduler stopping due to context cancellation")
-			if err := s.Stop(); err != nil {
+			if err := s.Stop(context.Background()); err != nil {
 				log.Printf("Error stopping scheduler: %v", err)
 			}
 			return
@@ -133,55 +292,111 @@ func (s *Scheduler) run(ctx context.Context) {
 }
 
 func (s *Scheduler) processBatch(ctx context.Context) {
+	if s.elector != nil && !s.elector.IsLeader() {
+		log.Printf("Skipping batch: instance %s is not the scheduler leader", s.elector.InstanceID())
+		return
+	}
+
+	interval, batchSize := s.Settings()
+
 	// Create a unique batch ID for tracking
 	batchID := uuid.New()
 	startTime := time.Now()
 
-	// Create a timeout context for batch processing (use a reasonable timeout)
-	timeout := 30 * time.Second
-	if s.interval > time.Minute {
-		// Use up to half the interval for batch processing, but at least 30 seconds
-		timeout = s.interval / 2
-	}
-
-	batchCtx, cancel := context.WithTimeout(ctx, timeout)
+	// Bound the batch so it ends before the reaper could take its messages back
+	batchCtx, cancel := context.WithTimeout(service.WithBatchID(ctx, batchID), BatchTimeout(interval))
 	defer cancel()
 
-	log.Printf("Processing batch %s of %d messages", batchID.String(), s.batchSize)
+	log.Printf("Processing batch %s of %d messages", batchID.String(), batchSize)
 
 	// Log batch started
 	if s.auditService != nil {
-		go func() {
-			if err := s.auditService.LogBatchStarted(context.Background(), batchID, s.batchSize); err != nil {
-				log.Printf("Failed to log batch started event: %v", err)
-			}
-		}()
+		if err := s.auditService.LogBatchStarted(context.Background(), batchID, batchSize); err != nil {
+			log.Printf("Failed to log batch started event: %v", err)
+		}
 	}
 
 	// Process the batch
-	err := s.service.ProcessMessages(batchCtx, s.batchSize)
+	result, err := s.service.ProcessMessages(batchCtx, batchSize)
 	duration := time.Since(startTime)
 
+	if result == nil {
+		result = domain.NewBatchResult(startTime, nil)
+	}
+	result.BatchID = batchID
+	result.DurationMs = duration.Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+	}
+	s.recordBatchResult(result)
+
 	// Log batch completion or failure
 	if s.auditService != nil {
-		go func() {
-			if err != nil {
-				if logErr := s.auditService.LogBatchFailed(context.Background(), batchID, duration, err); logErr != nil {
-					log.Printf("Failed to log batch failed event: %v", logErr)
-				}
-			} else {
-				// For now, we'll log with generic success count since we don't have detailed metrics from ProcessMessages
-				// In a real implementation, ProcessMessages would return success/failure counts
-				if logErr := s.auditService.LogBatchCompleted(context.Background(), batchID, duration, s.batchSize, 0); logErr != nil {
-					log.Printf("Failed to log batch completed event: %v", logErr)
-				}
+		if err != nil {
+			if logErr := s.auditService.LogBatchFailed(context.Background(), batchID, duration, err); logErr != nil {
+				log.Printf("Failed to log batch failed event: %v", logErr)
+			}
+		} else {
+			if logErr := s.auditService.LogBatchCompleted(context.Background(), batchID, duration, result.SuccessCount(), result.FailureCount()); logErr != nil {
+				log.Printf("Failed to log batch completed event: %v", logErr)
 			}
-		}()
+		}
 	}
 
 	if err != nil {
 		log.Printf("Error processing messages batch %s: %v", batchID.String(), err)
 	} else {
-		log.Printf("Completed processing batch %s in %v", batchID.String(), duration)
+		log.Printf("Completed processing batch %s in %v: %d sent, %d failed",
+			batchID.String(), duration, result.SuccessCount(), result.FailureCount())
+	}
+}
+
+// recordBatchResult stores the result in the shared store, if any, and in the local
+// history, dropping the oldest beyond BatchHistorySize
+func (s *Scheduler) recordBatchResult(result *domain.BatchResult) {
+	s.mu.Lock()
+	result.InstanceID = s.instanceID
+	batchResults := s.batchResults
+	s.mu.Unlock()
+
+	if batchResults != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := batchResults.SaveBatchResult(ctx, result, BatchHistorySize); err != nil {
+			log.Printf("Failed to store result of batch %s: %v", result.BatchID, err)
+		}
+	}
+
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+
+	s.history = append(s.history, result)
+	if len(s.history) > BatchHistorySize {
+		s.history = s.history[len(s.history)-BatchHistorySize:]
+	}
+}
+
+// RecentBatches returns up to limit of the latest batch results, newest first. With a
+// shared store these are the batches of every replica; otherwise only this one's.
+func (s *Scheduler) RecentBatches(ctx context.Context, limit int) ([]*domain.BatchResult, error) {
+	s.mu.Lock()
+	batchResults := s.batchResults
+	s.mu.Unlock()
+
+	if limit <= 0 || limit > BatchHistorySize {
+		limit = BatchHistorySize
+	}
+
+	if batchResults != nil {
+		return batchResults.GetRecentBatchResults(ctx, limit)
+	}
+
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+
+	batches := make([]*domain.BatchResult, 0, min(limit, len(s.history)))
+	for i := len(s.history) - 1; i >= 0 && len(batches) < limit; i-- {
+		batches = append(batches, s.history[i])
 	}
+	return batches, nil
 }
@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+)
+
+func TestBatchTimeout(t *testing.T) {
+	tests := []struct {
+		interval time.Duration
+		expected time.Duration
+	}{
+		{time.Second, 30 * time.Second},
+		{time.Minute, 30 * time.Second},
+		{90 * time.Second, 45 * time.Second},
+		{time.Hour, 30 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := BatchTimeout(tt.interval); got != tt.expected {
+			t.Errorf("BatchTimeout(%v) = %v, expected %v", tt.interval, got, tt.expected)
+		}
+	}
+}
+
+func TestScheduler_ValidateSettingsAgainstSendingLease(t *testing.T) {
+	s := NewScheduler(nil, nil, time.Minute, 10)
+	s.SetSendingLease(5 * time.Minute)
+
+	if err := s.ValidateSettings(9*time.Minute, 10); err != nil {
+		t.Errorf("Expected batches of 4m30s to fit a 5m lease, got %v", err)
+	}
+	if err := s.ValidateSettings(10*time.Minute, 10); !errors.Is(err, domain.ErrInvalidSchedulerConfig) {
+		t.Errorf("Expected ErrInvalidSchedulerConfig for batches as long as the lease, got %v", err)
+	}
+	if err := s.ValidateSettings(time.Minute, 0); !errors.Is(err, domain.ErrInvalidSchedulerConfig) {
+		t.Errorf("Expected ErrInvalidSchedulerConfig for a zero batch size, got %v", err)
+	}
+
+	if err := s.Reconfigure(context.Background(), time.Hour, 10); !errors.Is(err, domain.ErrInvalidSchedulerConfig) {
+		t.Errorf("Expected Reconfigure to reject an interval outlasting the lease, got %v", err)
+	}
+	if interval, _ := s.Settings(); interval != time.Minute {
+		t.Errorf("Expected the interval to stay at 1m, got %v", interval)
+	}
+}
+
+func TestScheduler_RecentBatchesSharedAcrossReplicas(t *testing.T) {
+	batchResults := repository.NewMockBatchResultRepository()
+
+	leader := NewScheduler(nil, nil, time.Minute, 10)
+	leader.SetInstanceID("replica-a")
+	leader.SetBatchResultRepository(batchResults)
+	follower := NewScheduler(nil, nil, time.Minute, 10)
+	follower.SetBatchResultRepository(batchResults)
+
+	first := domain.NewBatchResult(time.Now(), nil)
+	second := domain.NewBatchResult(time.Now(), nil)
+	leader.recordBatchResult(first)
+	leader.recordBatchResult(second)
+
+	batches, err := follower.RecentBatches(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(batches) != 2 || batches[0] != second || batches[1] != first {
+		t.Fatalf("Expected the leader's 2 batches newest first, got %d", len(batches))
+	}
+	if batches[0].InstanceID != "replica-a" {
+		t.Errorf("Expected the batch tagged with replica-a, got %q", batches[0].InstanceID)
+	}
+}
+
+func TestScheduler_RecentBatchesInMemory(t *testing.T) {
+	s := NewScheduler(nil, nil, time.Minute, 10)
+	for i := 0; i < BatchHistorySize+5; i++ {
+		s.recordBatchResult(domain.NewBatchResult(time.Now(), nil))
+	}
+
+	batches, err := s.RecentBatches(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(batches) != BatchHistorySize {
+		t.Errorf("Expected %d batches, got %d", BatchHistorySize, len(batches))
+	}
+	if batches, _ := s.RecentBatches(context.Background(), 3); len(batches) != 3 {
+		t.Errorf("Expected 3 batches, got %d", len(batches))
+	}
+}
@@ -0,0 +1,174 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+)
+
+// StateSync persists the scheduler's desired run state and settings and keeps the local
+// scheduler in line with them. A change issued on one replica is stored, and every other
+// replica picks it up on its next poll; the stored state also survives restarts.
+type StateSync struct {
+	scheduler *Scheduler
+	repo      repository.SchedulerStateRepository
+	interval  time.Duration
+
+	mu      sync.Mutex
+	done    chan struct{}
+	running bool
+	wg      sync.WaitGroup
+}
+
+func NewStateSync(scheduler *Scheduler, repo repository.SchedulerStateRepository, interval time.Duration) *StateSync {
+	return &StateSync{
+		scheduler: scheduler,
+		repo:      repo,
+		interval:  interval,
+	}
+}
+
+// Restore applies the stored desired state on boot. When nothing has been stored yet,
+// autostart decides whether the scheduler starts, and that choice is recorded so the
+// other replicas follow it.
+func (s *StateSync) Restore(ctx context.Context, autostart bool, changedBy string) error {
+	state, err := s.repo.GetDesiredState(ctx)
+	if err != nil {
+		return err
+	}
+
+	if state == nil {
+		if !autostart {
+			log.Println("No stored scheduler state and autostart is disabled, scheduler stays stopped")
+			return nil
+		}
+		if state, err = s.SetDesired(ctx, true, changedBy); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Restoring scheduler state: running=%t, interval_ms=%d, batch_size=%d (changed by %s at %s)",
+		state.Running, state.IntervalMs, state.BatchSize, state.ChangedBy, state.ChangedAt.Format(time.RFC3339))
+	s.apply(state)
+	return nil
+}
+
+// SetDesired records the desired run state, keeping any stored settings; callers apply it
+// to the local scheduler
+func (s *StateSync) SetDesired(ctx context.Context, running bool, changedBy string) (*domain.SchedulerDesiredState, error) {
+	return s.repo.SetDesiredRunning(ctx, running, changedBy)
+}
+
+// SetSettings records the desired interval and batch size, keeping the stored run state;
+// callers apply them to the local scheduler
+func (s *StateSync) SetSettings(ctx context.Context, interval time.Duration, batchSize int, changedBy string) (*domain.SchedulerDesiredState, error) {
+	return s.repo.SetDesiredSettings(ctx, interval.Milliseconds(), batchSize, s.scheduler.IsRunning(), changedBy)
+}
+
+// Desired returns the stored desired state, or nil if none has been recorded
+func (s *StateSync) Desired(ctx context.Context) (*domain.SchedulerDesiredState, error) {
+	return s.repo.GetDesiredState(ctx)
+}
+
+// Start polls the stored state in the background until Stop is called
+func (s *StateSync) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+
+	s.done = make(chan struct{})
+	s.running = true
+
+	s.wg.Add(1)
+	go s.run()
+
+	log.Printf("Scheduler state sync started with interval: %v", s.interval)
+}
+
+// Stop ends polling. It must run before the scheduler is stopped on shutdown, otherwise a
+// poll could start it again.
+func (s *StateSync) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	close(s.done)
+	s.running = false
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	log.Println("Scheduler state sync stopped")
+}
+
+func (s *StateSync) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.converge()
+		}
+	}
+}
+
+func (s *StateSync) converge() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+	defer cancel()
+
+	state, err := s.repo.GetDesiredState(ctx)
+	if err != nil {
+		log.Printf("Failed to read scheduler state: %v", err)
+		return
+	}
+	if state == nil {
+		return
+	}
+
+	s.apply(state)
+}
+
+func (s *StateSync) apply(state *domain.SchedulerDesiredState) {
+	// Settings go first so a scheduler started below uses them from its first batch
+	if state.IntervalMs > 0 || state.BatchSize > 0 {
+		interval, batchSize := s.scheduler.Settings()
+		if state.IntervalMs > 0 {
+			interval = time.Duration(state.IntervalMs) * time.Millisecond
+		}
+		if state.BatchSize > 0 {
+			batchSize = state.BatchSize
+		}
+		if err := s.scheduler.Reconfigure(context.Background(), interval, batchSize); err != nil {
+			log.Printf("Failed to apply scheduler settings set by %s: %v", state.ChangedBy, err)
+		}
+	}
+
+	if state.Running == s.scheduler.IsRunning() {
+		return
+	}
+
+	if state.Running {
+		log.Printf("Starting scheduler to match state set by %s", state.ChangedBy)
+		if err := s.scheduler.Start(context.Background()); err != nil && err != domain.ErrSchedulerRunning {
+			log.Printf("Failed to start scheduler: %v", err)
+		}
+		return
+	}
+
+	log.Printf("Stopping scheduler to match state set by %s", state.ChangedBy)
+	if err := s.scheduler.Stop(context.Background()); err != nil && err != domain.ErrSchedulerNotRunning {
+		log.Printf("Failed to stop scheduler: %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+	"ims/internal/service"
+)
+
+// newTestReplica returns a stopped scheduler and its state sync, sharing stateRepo with
+// any other replica built from it
+func newTestReplica(t *testing.T, stateRepo repository.SchedulerStateRepository) (*Scheduler, *StateSync) {
+	t.Helper()
+	messageService := service.NewMessageService(
+		repository.NewMockMessageRepository(),
+		repository.NewMockCacheRepository(),
+		service.NewWebhookClient("http://example.com", "test-key", time.Second, 0),
+		160,
+	)
+	s := NewScheduler(messageService, nil, time.Minute, 10)
+	stateSync := NewStateSync(s, stateRepo, time.Second)
+	t.Cleanup(func() {
+		stateSync.Stop()
+		s.Stop(context.Background())
+	})
+	return s, stateSync
+}
+
+func TestStateSync_ConvergesRunState(t *testing.T) {
+	stateRepo := repository.NewMockSchedulerStateRepository()
+	a, syncA := newTestReplica(t, stateRepo)
+	b, syncB := newTestReplica(t, stateRepo)
+
+	state, err := syncA.SetDesired(context.Background(), true, "operator")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	syncA.apply(state)
+	syncB.converge()
+
+	if !a.IsRunning() || !b.IsRunning() {
+		t.Errorf("Expected both replicas running, got a=%t b=%t", a.IsRunning(), b.IsRunning())
+	}
+
+	state, err = syncB.SetDesired(context.Background(), false, "operator")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	syncB.apply(state)
+	syncA.converge()
+
+	if a.IsRunning() || b.IsRunning() {
+		t.Errorf("Expected both replicas stopped, got a=%t b=%t", a.IsRunning(), b.IsRunning())
+	}
+}
+
+func TestStateSync_ConvergesSettings(t *testing.T) {
+	stateRepo := repository.NewMockSchedulerStateRepository()
+	_, syncA := newTestReplica(t, stateRepo)
+	b, syncB := newTestReplica(t, stateRepo)
+
+	state, err := syncA.SetSettings(context.Background(), 5*time.Minute, 25, "operator")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if state.Running {
+		t.Error("Expected the stored run state to stay stopped")
+	}
+	syncB.converge()
+
+	if interval, batchSize := b.Settings(); interval != 5*time.Minute || batchSize != 25 {
+		t.Errorf("Expected 5m and 25, got %v and %d", interval, batchSize)
+	}
+	if b.IsRunning() {
+		t.Error("Expected changing settings not to start the scheduler")
+	}
+
+	// A later start keeps the stored settings
+	if _, err := syncA.SetDesired(context.Background(), true, "operator"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stored, err := syncB.Desired(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !stored.Running || stored.IntervalMs != (5*time.Minute).Milliseconds() || stored.BatchSize != 25 {
+		t.Errorf("Expected running with 5m and 25, got %+v", stored)
+	}
+}
+
+func TestStateSync_ConcurrentChangesKeepEachOther(t *testing.T) {
+	stateRepo := repository.NewMockSchedulerStateRepository()
+	_, syncA := newTestReplica(t, stateRepo)
+	_, syncB := newTestReplica(t, stateRepo)
+
+	if _, err := syncA.SetDesired(context.Background(), true, "operator"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// A stop on one replica and a configure on another must not undo each other
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if _, err := syncA.SetDesired(context.Background(), false, "replica-a"); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if _, err := syncB.SetSettings(context.Background(), 5*time.Minute, 25, "replica-b"); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}()
+	wg.Wait()
+
+	stored, err := stateRepo.GetDesiredState(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stored.Running || stored.IntervalMs != (5*time.Minute).Milliseconds() || stored.BatchSize != 25 {
+		t.Errorf("Expected stopped with 5m and 25, got %+v", stored)
+	}
+}
+
+func TestStateSync_KeepsSettingsWhenStoredOnesAreInvalid(t *testing.T) {
+	stateRepo := repository.NewMockSchedulerStateRepository()
+	s, stateSync := newTestReplica(t, stateRepo)
+
+	if _, err := stateRepo.SetDesiredSettings(context.Background(), time.Millisecond.Milliseconds(), 25, false, "operator"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stateSync.converge()
+
+	if interval, batchSize := s.Settings(); interval != time.Minute || batchSize != 10 {
+		t.Errorf("Expected the previous 1m and 10 to stay, got %v and %d", interval, batchSize)
+	}
+}
+
+func TestStateSync_IgnoresReadErrors(t *testing.T) {
+	stateRepo := repository.NewMockSchedulerStateRepository()
+	s, stateSync := newTestReplica(t, stateRepo)
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	stateRepo.GetDesiredStateFunc = func(ctx context.Context) (*domain.SchedulerDesiredState, error) {
+		return nil, errors.New("connection refused")
+	}
+	stateSync.converge()
+
+	if !s.IsRunning() {
+		t.Error("Expected the scheduler to keep running when the state cannot be read")
+	}
+}
+
+func TestStateSync_Restore(t *testing.T) {
+	t.Run("no stored state without autostart", func(t *testing.T) {
+		stateRepo := repository.NewMockSchedulerStateRepository()
+		s, stateSync := newTestReplica(t, stateRepo)
+
+		if err := stateSync.Restore(context.Background(), false, "boot"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if s.IsRunning() {
+			t.Error("Expected the scheduler to stay stopped")
+		}
+		if state, _ := stateRepo.GetDesiredState(context.Background()); state != nil {
+			t.Errorf("Expected nothing stored, got %+v", state)
+		}
+	})
+
+	t.Run("no stored state with autostart", func(t *testing.T) {
+		stateRepo := repository.NewMockSchedulerStateRepository()
+		s, stateSync := newTestReplica(t, stateRepo)
+
+		if err := stateSync.Restore(context.Background(), true, "boot"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !s.IsRunning() {
+			t.Error("Expected the scheduler to start")
+		}
+		state, _ := stateRepo.GetDesiredState(context.Background())
+		if state == nil || !state.Running || state.ChangedBy != "boot" {
+			t.Errorf("Expected the start to be recorded by boot, got %+v", state)
+		}
+	})
+
+	t.Run("stored stop wins over autostart", func(t *testing.T) {
+		stateRepo := repository.NewMockSchedulerStateRepository()
+		stateRepo.SetDesiredRunning(context.Background(), false, "operator")
+		s, stateSync := newTestReplica(t, stateRepo)
+
+		if err := stateSync.Restore(context.Background(), true, "boot"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if s.IsRunning() {
+			t.Error("Expected the stored stop to keep the scheduler stopped")
+		}
+	})
+}
@@ -19,9 +19,13 @@ import (
 )
 
 type Server struct {
-	httpServer *http.Server
-	scheduler  *scheduler.Scheduler
-	ctx        context.Context
+	httpServer  *http.Server
+	scheduler   *scheduler.Scheduler
+	auditWriter *service.AuditWriter
+	auditSpool  *service.AuditSpool
+	auditSinks  *service.AuditSinks
+	auditStream *service.AuditStream
+	ctx         context.Context
 }
 
 func NewServer(
@@ -31,14 +35,30 @@ func NewServer(
 	messageService *service.MessageService,
 	scheduler *scheduler.Scheduler,
 	auditService service.AuditService,
+	auditWriter *service.AuditWriter,
+	auditSpool *service.AuditSpool,
+	auditSinks *service.AuditSinks,
+	auditStream *service.AuditStream,
 ) *Server {
 	mux := http.NewServeMux()
 
 	// Create handlers
 	healthHandler := handlers.NewHealthHandler(db, redis, scheduler)
+	if auditWriter != nil {
+		healthHandler.SetAuditWriter(auditWriter)
+	}
+	if auditSpool != nil {
+		healthHandler.SetAuditSpool(auditSpool)
+	}
+	if auditSinks != nil {
+		healthHandler.SetAuditSinks(auditSinks)
+	}
 	controlHandler := handlers.NewControlHandler(scheduler)
 	messageHandler := handlers.NewMessageHandler(messageService)
 	auditHandler := handlers.NewAuditHandler(auditService)
+	if auditStream != nil {
+		auditHandler.SetAuditStream(auditStream, cfg.Audit.StreamKeepalive)
+	}
 
 	// Apply authentication middleware to protected routes
 	authMiddleware := middleware.AuthMiddleware(cfg.Webhook.AuthKey)
@@ -46,12 +66,20 @@ func NewServer(
 	// Routes
 	mux.Handle("/api/health", middleware.LoggingMiddleware(http.HandlerFunc(healthHandler.Handle)))
 	mux.Handle("/api/control", middleware.LoggingMiddleware(authMiddleware(http.HandlerFunc(controlHandler.Handle))))
+	mux.Handle("/api/batches", middleware.LoggingMiddleware(authMiddleware(http.HandlerFunc(controlHandler.GetRecentBatches))))
+	mux.Handle("/api/messages", middleware.LoggingMiddleware(authMiddleware(http.HandlerFunc(messageHandler.CreateMessage))))
+	mux.Handle("/api/messages/bulk", middleware.LoggingMiddleware(authMiddleware(http.HandlerFunc(messageHandler.CreateMessagesBulk))))
 	mux.Handle("/api/messages/sent", middleware.LoggingMiddleware(authMiddleware(http.HandlerFunc(messageHandler.GetSentMessages))))
+	mux.Handle("/api/messages/", middleware.LoggingMiddleware(authMiddleware(http.HandlerFunc(messageHandler.GetMessage))))
 
 	// Audit routes
 	mux.Handle("/api/audit", middleware.LoggingMiddleware(authMiddleware(http.HandlerFunc(auditHandler.GetAuditLogs))))
 	mux.Handle("/api/audit/stats", middleware.LoggingMiddleware(authMiddleware(http.HandlerFunc(auditHandler.GetAuditLogStats))))
+	mux.Handle("/api/audit/stats/timeseries", middleware.LoggingMiddleware(authMiddleware(http.HandlerFunc(auditHandler.GetAuditLogTimeSeries))))
 	mux.Handle("/api/audit/cleanup", middleware.LoggingMiddleware(authMiddleware(http.HandlerFunc(auditHandler.CleanupOldAuditLogs))))
+	mux.Handle("/api/audit/export", middleware.LoggingMiddleware(authMiddleware(http.HandlerFunc(auditHandler.ExportAuditLogs))))
+	mux.Handle("/api/audit/verify", middleware.LoggingMiddleware(authMiddleware(http.HandlerFunc(auditHandler.VerifyAuditChain))))
+	mux.Handle("/api/audit/stream", middleware.LoggingMiddleware(authMiddleware(http.HandlerFunc(auditHandler.StreamAuditLogs))))
 
 	// Setup path-based routing for audit endpoints that need path parameters
 	// For now, using simple path matching since we don't have a full router
@@ -61,18 +89,25 @@ func NewServer(
 	// Setup Swagger UI
 	SetupSwagger(mux)
 
+	// Every request gets an ID; health probes and documentation are not audited
+	requestIDMiddleware := middleware.RequestIDMiddleware(auditService, "/api/health", "/api/docs", "/api/swagger")
+
 	server := &http.Server{
 		Addr:           ":" + cfg.Server.Port,
-		Handler:        mux,
+		Handler:        requestIDMiddleware(mux),
 		ReadTimeout:    cfg.Server.ReadTimeout,
 		WriteTimeout:   cfg.Server.WriteTimeout,
 		MaxHeaderBytes: 1 << 20, // 1 MB
 	}
 
 	return &Server{
-		httpServer: server,
-		scheduler:  scheduler,
-		ctx:        context.Background(),
+		httpServer:  server,
+		scheduler:   scheduler,
+		auditWriter: auditWriter,
+		auditSpool:  auditSpool,
+		auditSinks:  auditSinks,
+		auditStream: auditStream,
+		ctx:         context.Background(),
 	}
 }
 
@@ -86,18 +121,52 @@ func (s *Server) Start() error {
 }
 
 func (s *Server) Shutdown() error {
-	// Stop scheduler first
+	// Stop scheduler first; the state sync goes before it so it cannot restart the
+	// scheduler, and the stored desired state is left as is for the next boot
 	if s.scheduler != nil {
-		if err := s.scheduler.Stop(); err != nil {
+		if stateSync := s.scheduler.StateSync(); stateSync != nil {
+			stateSync.Stop()
+		}
+		if err := s.scheduler.Stop(context.Background()); err != nil {
 			log.Printf("Error stopping scheduler: %v", err)
 		}
 	}
 
+	// End live audit streams, which would otherwise hold the HTTP shutdown open
+	if s.auditStream != nil {
+		s.auditStream.Stop()
+	}
+
 	// Shutdown HTTP server
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	return s.httpServer.Shutdown(ctx)
+	err := s.httpServer.Shutdown(ctx)
+
+	// Drain queued audit entries last, once nothing else can produce them
+	if s.auditWriter != nil {
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer drainCancel()
+		if drainErr := s.auditWriter.Close(drainCtx); drainErr != nil {
+			log.Printf("Error draining audit writer: %v", drainErr)
+		}
+	}
+
+	// Sinks take the drained entries, then deliver what they have queued
+	if s.auditSinks != nil {
+		sinkCtx, sinkCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer sinkCancel()
+		if sinkErr := s.auditSinks.Close(sinkCtx); sinkErr != nil {
+			log.Printf("Error closing audit sinks: %v", sinkErr)
+		}
+	}
+
+	// The spool closes after the drain, which may still spool entries
+	if s.auditSpool != nil {
+		s.auditSpool.Stop()
+	}
+
+	return err
 }
 
 // SetupSwagger configures Swagger UI endpoint with dynamic docs
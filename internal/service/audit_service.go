@@ -19,8 +19,11 @@ type AuditService interface {
 	LogBatchFailed(ctx context.Context, batchID uuid.UUID, duration time.Duration, err error) error
 
 	// Message-related audit logging
+	LogMessagesCreated(ctx context.Context, messages []*domain.Message) error
+	LogMessagesClaimed(ctx context.Context, messageIDs []uuid.UUID, owner string) error
 	LogMessageSent(ctx context.Context, messageID uuid.UUID, duration time.Duration, webhookURL string) error
 	LogMessageFailed(ctx context.Context, messageID uuid.UUID, duration time.Duration, webhookURL string, err error) error
+	LogMessageRecovered(ctx context.Context, messageID uuid.UUID, lease time.Duration) error
 
 	// Webhook-related audit logging
 	LogWebhookRequest(ctx context.Context, messageID uuid.UUID, webhookURL, method string, requestBody interface{}) error
@@ -32,18 +35,43 @@ type AuditService interface {
 	// Scheduler audit logging
 	LogSchedulerStarted(ctx context.Context) error
 	LogSchedulerStopped(ctx context.Context) error
+	LogSchedulerReconfigured(ctx context.Context, previousInterval, interval time.Duration, previousBatchSize, batchSize int) error
+
+	// Retention audit logging
+	LogAuditPruned(ctx context.Context, policy domain.AuditRetentionPolicy, result *domain.AuditPruneResult) error
 
 	// Generic audit logging
 	Log(ctx context.Context, auditLog *domain.AuditLog) error
 
 	// Query audit logs
 	GetAuditLogs(ctx context.Context, filter *domain.AuditLogFilter) ([]*domain.AuditLog, error)
+	GetAuditLogsPage(ctx context.Context, filter *domain.AuditLogFilter) ([]*domain.AuditLog, *domain.PageCursor, error)
 	GetBatchAuditLogs(ctx context.Context, batchID string) ([]*domain.AuditLog, error)
 	GetMessageAuditLogs(ctx context.Context, messageID string) ([]*domain.AuditLog, error)
 	GetAuditLogStats(ctx context.Context, filter *domain.AuditLogFilter) (*domain.AuditLogStats, error)
+	GetAuditLogTimeSeries(ctx context.Context, filter *domain.AuditLogFilter, interval domain.AuditStatsInterval) (*domain.AuditStatsTimeSeries, error)
+
+	// Export audit logs
+	StreamAuditLogs(ctx context.Context, filter *domain.AuditLogFilter, fn func(*domain.AuditLog) error) error
+	ExportAuditLogs(ctx context.Context, filter *domain.AuditLogFilter, header func(metadataKeys []string) error, fn func(*domain.AuditLog) error) error
 
 	// Maintenance
 	CleanupOldAuditLogs(ctx context.Context, days int) (int64, error)
+	ApplyRetention(ctx context.Context, policy domain.AuditRetentionPolicy, batchSize int) (*domain.AuditPruneResult, error)
+	VerifyAuditChain(ctx context.Context, from, to *time.Time) (*domain.AuditChainVerification, error)
+}
+
+// auditChainPageSize is how many chained entries are read at a time while verifying
+const auditChainPageSize = 1000
+
+// maxAuditStatsBuckets caps how many buckets one time series may span
+const maxAuditStatsBuckets = 2000
+
+// auditStatsDefaultWindows is how far back a time series reaches when no start is given
+var auditStatsDefaultWindows = map[domain.AuditStatsInterval]time.Duration{
+	domain.AuditStatsMinute: time.Hour,
+	domain.AuditStatsHour:   24 * time.Hour,
+	domain.AuditStatsDay:    30 * 24 * time.Hour,
 }
 
 type auditService struct {
@@ -89,6 +117,36 @@ func (s *auditService) LogBatchFailed(ctx context.Context, batchID uuid.UUID, du
 	return s.logWithFallback(ctx, auditLog)
 }
 
+// LogMessagesCreated records one entry per enqueued message, written together
+func (s *auditService) LogMessagesCreated(ctx context.Context, messages []*domain.Message) error {
+	auditLogs := make([]*domain.AuditLog, 0, len(messages))
+	for _, msg := range messages {
+		builder := domain.NewAuditLog(domain.EventMessageCreated, "Message Created").
+			WithDescription("Message enqueued for sending").
+			WithMessageID(msg.ID)
+		if msg.IdempotencyKey != nil && *msg.IdempotencyKey != "" {
+			builder = builder.WithMetadata("idempotency_key", *msg.IdempotencyKey)
+		}
+		auditLogs = append(auditLogs, builder.Build())
+	}
+
+	return s.logBatchWithFallback(ctx, auditLogs)
+}
+
+// LogMessagesClaimed records one entry per message claimed by owner, written together
+func (s *auditService) LogMessagesClaimed(ctx context.Context, messageIDs []uuid.UUID, owner string) error {
+	auditLogs := make([]*domain.AuditLog, 0, len(messageIDs))
+	for _, messageID := range messageIDs {
+		auditLogs = append(auditLogs, domain.NewAuditLog(domain.EventMessageClaimed, "Message Claimed").
+			WithDescription(fmt.Sprintf("Message claimed for sending by %s", owner)).
+			WithMessageID(messageID).
+			WithMetadata("claimed_by", owner).
+			Build())
+	}
+
+	return s.logBatchWithFallback(ctx, auditLogs)
+}
+
 func (s *auditService) LogMessageSent(ctx context.Context, messageID uuid.UUID, duration time.Duration, webhookURL string) error {
 	auditLog := domain.NewAuditLog(domain.EventMessageSent, "Message Sent Successfully").
 		WithDescription("Message sent to webhook successfully").
@@ -112,6 +170,16 @@ func (s *auditService) LogMessageFailed(ctx context.Context, messageID uuid.UUID
 	return s.logWithFallback(ctx, auditLog)
 }
 
+func (s *auditService) LogMessageRecovered(ctx context.Context, messageID uuid.UUID, lease time.Duration) error {
+	auditLog := domain.NewAuditLog(domain.EventMessageRecovered, "Stuck Message Recovered").
+		WithDescription(fmt.Sprintf("Message was stuck in sending for longer than %v and was returned to pending", lease)).
+		WithMessageID(messageID).
+		WithMetadata("lease", lease.String()).
+		Build()
+
+	return s.logWithFallback(ctx, auditLog)
+}
+
 func (s *auditService) LogWebhookRequest(ctx context.Context, messageID uuid.UUID, webhookURL, method string, requestBody interface{}) error {
 	auditLog := domain.NewAuditLog(domain.EventWebhookRequest, "Webhook Request Sent").
 		WithDescription("Sent request to webhook endpoint").
@@ -163,6 +231,37 @@ func (s *auditService) LogSchedulerStopped(ctx context.Context) error {
 	return s.logWithFallback(ctx, auditLog)
 }
 
+func (s *auditService) LogSchedulerReconfigured(ctx context.Context, previousInterval, interval time.Duration, previousBatchSize, batchSize int) error {
+	auditLog := domain.NewAuditLog(domain.EventSchedulerReconfigured, "Message Scheduler Reconfigured").
+		WithDescription(fmt.Sprintf("Scheduler interval changed from %v to %v and batch size from %d to %d",
+			previousInterval, interval, previousBatchSize, batchSize)).
+		WithMetadata("previous_interval", previousInterval.String()).
+		WithMetadata("interval", interval.String()).
+		WithMetadata("previous_batch_size", previousBatchSize).
+		WithMetadata("batch_size", batchSize).
+		Build()
+
+	return s.logWithFallback(ctx, auditLog)
+}
+
+func (s *auditService) LogAuditPruned(ctx context.Context, policy domain.AuditRetentionPolicy, result *domain.AuditPruneResult) error {
+	deleted := make(map[string]interface{}, len(result.Deleted))
+	for eventType, count := range result.Deleted {
+		deleted[string(eventType)] = count
+	}
+
+	auditLog := domain.NewAuditLog(domain.EventAuditPruned, "Audit Logs Pruned").
+		WithDescription(fmt.Sprintf("Retention pruned %d audit logs in %d batches", result.Total, result.Batches)).
+		WithDuration(time.Duration(result.DurationMs)*time.Millisecond).
+		WithMetadata("policy", policy.String()).
+		WithMetadata("deleted", deleted).
+		WithMetadata("total", result.Total).
+		WithMetadata("batches", result.Batches).
+		Build()
+
+	return s.logWithFallback(ctx, auditLog)
+}
+
 func (s *auditService) Log(ctx context.Context, auditLog *domain.AuditLog) error {
 	return s.logWithFallback(ctx, auditLog)
 }
@@ -171,6 +270,27 @@ func (s *auditService) GetAuditLogs(ctx context.Context, filter *domain.AuditLog
 	return s.auditRepo.GetAuditLogs(ctx, filter)
 }
 
+// GetAuditLogsPage returns up to filter.Limit audit logs, newest first, and a cursor for the
+// next page, or nil when this page is the last one
+func (s *auditService) GetAuditLogsPage(ctx context.Context, filter *domain.AuditLogFilter) ([]*domain.AuditLog, *domain.PageCursor, error) {
+	// Ask for one extra entry to learn whether another page follows
+	pageFilter := *filter
+	pageFilter.Limit = filter.Limit + 1
+
+	auditLogs, err := s.auditRepo.GetAuditLogs(ctx, &pageFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(auditLogs) <= filter.Limit {
+		return auditLogs, nil, nil
+	}
+
+	auditLogs = auditLogs[:filter.Limit]
+	last := auditLogs[len(auditLogs)-1]
+	return auditLogs, domain.NewPageCursor(last.CreatedAt, last.ID), nil
+}
+
 func (s *auditService) GetBatchAuditLogs(ctx context.Context, batchID string) ([]*domain.AuditLog, error) {
 	return s.auditRepo.GetBatchAuditLogs(ctx, batchID)
 }
@@ -183,30 +303,376 @@ func (s *auditService) GetAuditLogStats(ctx context.Context, filter *domain.Audi
 	return s.auditRepo.GetAuditLogStats(ctx, filter)
 }
 
+// GetAuditLogTimeSeries returns statistics for every bucket of the interval between the
+// filter's dates, including empty ones. Without a to date the series ends now; without a from
+// date it covers the interval's default window. Windows spanning more than
+// maxAuditStatsBuckets buckets yield ErrInvalidStatsWindow.
+func (s *auditService) GetAuditLogTimeSeries(ctx context.Context, filter *domain.AuditLogFilter, interval domain.AuditStatsInterval) (*domain.AuditStatsTimeSeries, error) {
+	width := interval.Duration()
+	if width == 0 {
+		return nil, fmt.Errorf("%w: unknown interval %q", domain.ErrInvalidStatsWindow, interval)
+	}
+
+	windowFilter := *filter
+	to := time.Now().UTC()
+	if filter.ToDate != nil {
+		to = filter.ToDate.UTC()
+	}
+	from := to.Add(-auditStatsDefaultWindows[interval])
+	if filter.FromDate != nil {
+		from = filter.FromDate.UTC()
+	}
+	if from.After(to) {
+		return nil, fmt.Errorf("%w: from date is after to date", domain.ErrInvalidStatsWindow)
+	}
+	windowFilter.FromDate = &from
+	windowFilter.ToDate = &to
+
+	first := from.Truncate(width)
+	if count := int64(to.Sub(first)/width) + 1; count > maxAuditStatsBuckets {
+		return nil, fmt.Errorf("%w: %d %s buckets exceed the limit of %d", domain.ErrInvalidStatsWindow, count, interval, maxAuditStatsBuckets)
+	}
+
+	stored, err := s.auditRepo.GetAuditLogTimeSeries(ctx, &windowFilter, interval)
+	if err != nil {
+		return nil, err
+	}
+	byStart := make(map[time.Time]*domain.AuditStatsBucket, len(stored))
+	for _, bucket := range stored {
+		byStart[bucket.Start.UTC()] = bucket
+	}
+
+	series := &domain.AuditStatsTimeSeries{
+		Interval: interval,
+		From:     from,
+		To:       to,
+	}
+	for start := first; !start.After(to); start = start.Add(width) {
+		bucket, exists := byStart[start]
+		if !exists {
+			bucket = &domain.AuditStatsBucket{
+				Start:           start,
+				EventTypeCounts: make(map[domain.AuditEventType]int64),
+			}
+		}
+
+		bucket.SuccessCount = bucket.EventTypeCounts[domain.EventMessageSent]
+		bucket.FailureCount = bucket.EventTypeCounts[domain.EventMessageFailed]
+		if attempts := bucket.SuccessCount + bucket.FailureCount; attempts > 0 {
+			rate := float64(bucket.SuccessCount) / float64(attempts)
+			bucket.SuccessRate = &rate
+		}
+
+		series.Buckets = append(series.Buckets, bucket)
+	}
+
+	return series, nil
+}
+
+func (s *auditService) StreamAuditLogs(ctx context.Context, filter *domain.AuditLogFilter, fn func(*domain.AuditLog) error) error {
+	return s.auditRepo.StreamAuditLogs(ctx, filter, fn)
+}
+
+func (s *auditService) ExportAuditLogs(ctx context.Context, filter *domain.AuditLogFilter, header func(metadataKeys []string) error, fn func(*domain.AuditLog) error) error {
+	return s.auditRepo.ExportAuditLogs(ctx, filter, header, fn)
+}
+
 func (s *auditService) CleanupOldAuditLogs(ctx context.Context, days int) (int64, error) {
 	return s.auditRepo.DeleteOldAuditLogs(ctx, days)
 }
 
+// ApplyRetention deletes every entry that has outlived the policy, at most batchSize per
+// delete so no single statement holds locks on a large part of the table
+func (s *auditService) ApplyRetention(ctx context.Context, policy domain.AuditRetentionPolicy, batchSize int) (*domain.AuditPruneResult, error) {
+	start := time.Now()
+	result := &domain.AuditPruneResult{Deleted: make(map[domain.AuditEventType]int64)}
+	if batchSize <= 0 {
+		return result, fmt.Errorf("retention batch size must be positive, got %d", batchSize)
+	}
+	cutoffs := policy.Cutoffs(start)
+
+	for {
+		deleted, err := s.auditRepo.PruneAuditLogs(ctx, cutoffs, batchSize)
+		if err != nil {
+			result.DurationMs = time.Since(start).Milliseconds()
+			return result, err
+		}
+		result.Batches++
+
+		var batchTotal int64
+		for eventType, count := range deleted {
+			result.Deleted[eventType] += count
+			batchTotal += count
+		}
+		result.Total += batchTotal
+
+		if batchTotal < int64(batchSize) {
+			break
+		}
+	}
+
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// VerifyAuditChain walks the hash chain over entries created within the range and reports
+// the first broken link. The first entry is checked against the genesis hash, the entry
+// before it, or the signed checkpoint left when that entry was pruned. The newest entry is
+// checked against the signed chain head, so deleting entries from the end is caught too.
+func (s *auditService) VerifyAuditChain(ctx context.Context, from, to *time.Time) (*domain.AuditChainVerification, error) {
+	result := &domain.AuditChainVerification{Valid: true, From: from, To: to}
+
+	head, err := s.auditRepo.GetAuditChainHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.HeadSeq = head.ChainSeq
+	if head.ChainSeq > 0 && !head.SignatureValid {
+		result.Valid = false
+		result.Break = &domain.AuditChainBreak{ChainSeq: head.ChainSeq, Reason: "chain head has an invalid signature"}
+		return result, nil
+	}
+
+	firstSeq, lastSeq, err := s.auditRepo.GetAuditChainBounds(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if firstSeq > 0 {
+		result.FirstSeq = firstSeq
+		result.LastSeq = lastSeq
+
+		chainBreak, err := s.walkAuditChain(ctx, head, firstSeq, lastSeq, result)
+		if err != nil {
+			return nil, err
+		}
+		if chainBreak != nil {
+			result.Valid = false
+			result.Break = chainBreak
+			return result, nil
+		}
+	}
+
+	chainBreak, err := s.checkAuditChainTail(ctx, head)
+	if err != nil {
+		return nil, err
+	}
+	if chainBreak != nil {
+		result.Valid = false
+		result.Break = chainBreak
+	}
+
+	return result, nil
+}
+
+// walkAuditChain checks every link from firstSeq through lastSeq
+func (s *auditService) walkAuditChain(ctx context.Context, head *domain.AuditChainHead, firstSeq, lastSeq int64, result *domain.AuditChainVerification) (*domain.AuditChainBreak, error) {
+	prevHash, chainBreak, err := s.auditChainAnchor(ctx, firstSeq, result)
+	if err != nil || chainBreak != nil {
+		return chainBreak, err
+	}
+
+	expectedSeq := firstSeq
+	for expectedSeq <= lastSeq {
+		entries, err := s.auditRepo.GetAuditChainEntries(ctx, expectedSeq, lastSeq, auditChainPageSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			return &domain.AuditChainBreak{ChainSeq: expectedSeq, Reason: "entry is missing"}, nil
+		}
+
+		for _, entry := range entries {
+			if chainBreak := s.checkAuditChainLink(entry, expectedSeq, prevHash, expectedSeq >= head.KeyedFrom); chainBreak != nil {
+				return chainBreak, nil
+			}
+			prevHash = *entry.Hash
+			expectedSeq++
+			result.Checked++
+		}
+	}
+
+	return nil, nil
+}
+
+// checkAuditChainTail checks that the newest chained entry is the one the signed head
+// records. Once retention has pruned the whole chain, the head's checkpoint stands in for it.
+func (s *auditService) checkAuditChainTail(ctx context.Context, head *domain.AuditChainHead) (*domain.AuditChainBreak, error) {
+	if head.ChainSeq == 0 {
+		return nil, nil
+	}
+
+	entries, err := s.auditRepo.GetAuditChainEntries(ctx, head.ChainSeq, head.ChainSeq, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 1 {
+		entry := entries[0]
+		if entry.Hash == nil || *entry.Hash != head.Hash {
+			id := entry.ID
+			actual := ""
+			if entry.Hash != nil {
+				actual = *entry.Hash
+			}
+			return &domain.AuditChainBreak{
+				ChainSeq: head.ChainSeq, AuditLogID: &id,
+				Reason:   "newest entry does not match the chain head",
+				Expected: head.Hash, Actual: actual,
+			}, nil
+		}
+		return nil, nil
+	}
+
+	checkpoint, err := s.auditRepo.GetAuditChainCheckpoint(ctx, head.ChainSeq)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint != nil && checkpoint.SignatureValid && checkpoint.Hash == head.Hash {
+		return nil, nil
+	}
+
+	_, lastSeq, err := s.auditRepo.GetAuditChainBounds(ctx, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.AuditChainBreak{
+		ChainSeq: lastSeq + 1,
+		Reason:   fmt.Sprintf("entries after chain sequence %d are missing; the chain head is at %d", lastSeq, head.ChainSeq),
+	}, nil
+}
+
+// auditChainAnchor returns the hash the entry at firstSeq must link to
+func (s *auditService) auditChainAnchor(ctx context.Context, firstSeq int64, result *domain.AuditChainVerification) (string, *domain.AuditChainBreak, error) {
+	if firstSeq == 1 {
+		result.Anchor = domain.AuditChainAnchorGenesis
+		return domain.AuditChainGenesisHash, nil, nil
+	}
+
+	previous, err := s.auditRepo.GetAuditChainEntries(ctx, firstSeq-1, firstSeq-1, 1)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(previous) == 1 && previous[0].Hash != nil {
+		result.Anchor = domain.AuditChainAnchorPreviousEntry
+		return *previous[0].Hash, nil, nil
+	}
+
+	checkpoint, err := s.auditRepo.GetAuditChainCheckpoint(ctx, firstSeq-1)
+	if err != nil {
+		return "", nil, err
+	}
+	if checkpoint == nil {
+		return "", &domain.AuditChainBreak{
+			ChainSeq: firstSeq - 1,
+			Reason:   "entry is missing and no checkpoint records its removal",
+		}, nil
+	}
+	if !checkpoint.SignatureValid {
+		return "", &domain.AuditChainBreak{
+			ChainSeq: firstSeq - 1,
+			Reason:   fmt.Sprintf("checkpoint %d has an invalid signature", checkpoint.ID),
+		}, nil
+	}
+
+	result.Anchor = domain.AuditChainAnchorCheckpoint
+	return checkpoint.Hash, nil, nil
+}
+
+// checkAuditChainLink checks that entry sits at seq, links to prevHash and still matches
+// its stored hash, keyed or not
+func (s *auditService) checkAuditChainLink(entry *domain.AuditLog, seq int64, prevHash string, keyed bool) *domain.AuditChainBreak {
+	id := entry.ID
+	if entry.ChainSeq == nil || *entry.ChainSeq != seq {
+		return &domain.AuditChainBreak{ChainSeq: seq, Reason: "entry is missing"}
+	}
+	if entry.PrevHash == nil || *entry.PrevHash != prevHash {
+		actual := ""
+		if entry.PrevHash != nil {
+			actual = *entry.PrevHash
+		}
+		return &domain.AuditChainBreak{
+			ChainSeq: seq, AuditLogID: &id,
+			Reason:   "previous hash does not match the preceding entry",
+			Expected: prevHash, Actual: actual,
+		}
+	}
+
+	hash, err := s.auditRepo.AuditChainHash(entry, seq, prevHash, keyed)
+	if err != nil {
+		return &domain.AuditChainBreak{ChainSeq: seq, AuditLogID: &id, Reason: err.Error()}
+	}
+	if entry.Hash == nil || *entry.Hash != hash {
+		actual := ""
+		if entry.Hash != nil {
+			actual = *entry.Hash
+		}
+		return &domain.AuditChainBreak{
+			ChainSeq: seq, AuditLogID: &id,
+			Reason:   "entry contents do not match its hash",
+			Expected: hash, Actual: actual,
+		}
+	}
+
+	return nil
+}
+
 // logWithFallback attempts to log the audit entry, but falls back to standard logging if it fails
 // This ensures that audit logging failures don't break the main application flow
 func (s *auditService) logWithFallback(ctx context.Context, auditLog *domain.AuditLog) error {
+	tagAuditLog(ctx, auditLog)
+
 	err := s.auditRepo.Log(ctx, auditLog)
 	if err != nil {
-		// Fall back to standard logging if audit logging fails
-		description := ""
-		if auditLog.Description != nil {
-			description = *auditLog.Description
-		}
-		log.Printf("AUDIT LOG FAILED (fallback to standard log): %s - %s: %s",
-			auditLog.EventType, auditLog.EventName, description)
-		if auditLog.BatchID != nil {
-			log.Printf("  Batch ID: %s", auditLog.BatchID.String())
-		}
-		if auditLog.MessageID != nil {
-			log.Printf("  Message ID: %s", auditLog.MessageID.String())
-		}
+		logAuditFallback(auditLog)
 		// Don't return error on fallback - this allows the application to continue
 		return nil
 	}
 	return nil
 }
+
+// logBatchWithFallback writes the entries in one LogBatch call, falling back to standard
+// logging for all of them if it fails
+func (s *auditService) logBatchWithFallback(ctx context.Context, auditLogs []*domain.AuditLog) error {
+	if len(auditLogs) == 0 {
+		return nil
+	}
+	for _, auditLog := range auditLogs {
+		tagAuditLog(ctx, auditLog)
+	}
+
+	if err := s.auditRepo.LogBatch(ctx, auditLogs); err != nil {
+		for _, auditLog := range auditLogs {
+			logAuditFallback(auditLog)
+		}
+	}
+	return nil
+}
+
+// tagAuditLog tags entries written on behalf of a batch or an API request with its ID
+func tagAuditLog(ctx context.Context, auditLog *domain.AuditLog) {
+	if auditLog.BatchID == nil {
+		if batchID, ok := BatchIDFromContext(ctx); ok {
+			auditLog.BatchID = &batchID
+		}
+	}
+	if auditLog.RequestID == nil {
+		if requestID, ok := RequestIDFromContext(ctx); ok {
+			auditLog.RequestID = &requestID
+		}
+	}
+}
+
+// logAuditFallback writes an entry that could not be stored to the standard log
+func logAuditFallback(auditLog *domain.AuditLog) {
+	description := ""
+	if auditLog.Description != nil {
+		description = *auditLog.Description
+	}
+	log.Printf("AUDIT LOG FAILED (fallback to standard log): %s - %s: %s",
+		auditLog.EventType, auditLog.EventName, description)
+	if auditLog.BatchID != nil {
+		log.Printf("  Batch ID: %s", auditLog.BatchID.String())
+	}
+	if auditLog.MessageID != nil {
+		log.Printf("  Message ID: %s", auditLog.MessageID.String())
+	}
+}
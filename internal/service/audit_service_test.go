@@ -236,6 +236,39 @@ func TestAuditService_LogMessageFailed(t *testing.T) {
 	}
 }
 
+func TestAuditService_LogMessageRecovered(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	service := NewAuditService(auditRepo)
+
+	messageID := uuid.New()
+	lease := 5 * time.Minute
+
+	ctx := context.Background()
+	err := service.LogMessageRecovered(ctx, messageID, lease)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	logs, err := auditRepo.GetAuditLogs(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to get audit logs: %v", err)
+	}
+
+	log := logs[0]
+	if log.EventType != domain.EventMessageRecovered {
+		t.Errorf("Expected event type %s, got %s", domain.EventMessageRecovered, log.EventType)
+	}
+
+	if *log.MessageID != messageID {
+		t.Errorf("Expected message ID %s, got %s", messageID, *log.MessageID)
+	}
+
+	if value, exists := log.Metadata["lease"]; !exists || value != lease.String() {
+		t.Errorf("Expected lease in metadata to be '%s', got %v", lease.String(), value)
+	}
+}
+
 func TestAuditService_LogWebhookRequest(t *testing.T) {
 	auditRepo := repository.NewMockAuditRepository()
 	service := NewAuditService(auditRepo)
@@ -429,6 +462,85 @@ func TestAuditService_LogSchedulerStopped(t *testing.T) {
 	}
 }
 
+func TestAuditService_LogSchedulerReconfigured(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	service := NewAuditService(auditRepo)
+
+	ctx := context.Background()
+	err := service.LogSchedulerReconfigured(ctx, 2*time.Minute, 30*time.Second, 2, 50)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	logs, err := auditRepo.GetAuditLogs(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to get audit logs: %v", err)
+	}
+
+	log := logs[0]
+	if log.EventType != domain.EventSchedulerReconfigured {
+		t.Errorf("Expected event type %s, got %s", domain.EventSchedulerReconfigured, log.EventType)
+	}
+
+	if value := log.Metadata["interval"]; value != "30s" {
+		t.Errorf("Expected interval in metadata to be '30s', got %v", value)
+	}
+
+	if value := log.Metadata["previous_batch_size"]; value != 2 {
+		t.Errorf("Expected previous_batch_size in metadata to be 2, got %v", value)
+	}
+
+	if value := log.Metadata["batch_size"]; value != 50 {
+		t.Errorf("Expected batch_size in metadata to be 50, got %v", value)
+	}
+}
+
+func TestAuditService_TagsEntriesFromContext(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	service := NewAuditService(auditRepo)
+
+	batchID := uuid.New()
+	ctx := WithRequestID(WithBatchID(context.Background(), batchID), "req-123")
+
+	if err := service.LogSchedulerStarted(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	logs, err := auditRepo.GetAuditLogs(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to get audit logs: %v", err)
+	}
+
+	log := logs[0]
+	if log.RequestID == nil || *log.RequestID != "req-123" {
+		t.Errorf("Expected request ID req-123, got %v", log.RequestID)
+	}
+
+	if log.BatchID == nil || *log.BatchID != batchID {
+		t.Errorf("Expected batch ID %s, got %v", batchID, log.BatchID)
+	}
+}
+
+func TestAuditService_ExplicitRequestIDWins(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	service := NewAuditService(auditRepo)
+
+	ctx := WithRequestID(context.Background(), "from-context")
+	if err := service.LogAPIRequest(ctx, "explicit", "GET", "/api/audit", 200, time.Millisecond, "curl"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	logs, err := auditRepo.GetAuditLogs(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to get audit logs: %v", err)
+	}
+
+	if logs[0].RequestID == nil || *logs[0].RequestID != "explicit" {
+		t.Errorf("Expected request ID explicit, got %v", logs[0].RequestID)
+	}
+}
+
 func TestAuditService_Log_Generic(t *testing.T) {
 	auditRepo := repository.NewMockAuditRepository()
 	service := NewAuditService(auditRepo)
@@ -568,6 +680,89 @@ func TestAuditService_GetAuditLogStats(t *testing.T) {
 	}
 }
 
+func TestAuditService_GetAuditLogTimeSeries(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	service := NewAuditService(auditRepo)
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	add := func(eventType domain.AuditEventType, at time.Time, durationMs *int) {
+		entry := domain.NewAuditLog(eventType, string(eventType)).Build()
+		entry.CreatedAt = at
+		entry.DurationMs = durationMs
+		auditRepo.AddLog(entry)
+	}
+	duration := func(ms int) *int { return &ms }
+
+	// First minute: 3 sent, 1 failed and four webhook responses; third minute: one failure
+	for i := 0; i < 3; i++ {
+		add(domain.EventMessageSent, start.Add(time.Duration(i)*time.Second), nil)
+	}
+	add(domain.EventMessageFailed, start.Add(10*time.Second), nil)
+	for i, ms := range []int{100, 200, 300, 400} {
+		add(domain.EventWebhookResponse, start.Add(time.Duration(20+i)*time.Second), duration(ms))
+	}
+	add(domain.EventMessageFailed, start.Add(2*time.Minute+5*time.Second), nil)
+
+	from := start
+	to := start.Add(2*time.Minute + 30*time.Second)
+	series, err := service.GetAuditLogTimeSeries(context.Background(), &domain.AuditLogFilter{FromDate: &from, ToDate: &to}, domain.AuditStatsMinute)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(series.Buckets) != 3 {
+		t.Fatalf("Expected 3 buckets including the empty one, got %d", len(series.Buckets))
+	}
+
+	first := series.Buckets[0]
+	if first.TotalCount != 8 || first.SuccessCount != 3 || first.FailureCount != 1 {
+		t.Errorf("Expected 8 entries, 3 sent and 1 failed, got %d, %d and %d", first.TotalCount, first.SuccessCount, first.FailureCount)
+	}
+	if first.SuccessRate == nil || *first.SuccessRate != 0.75 {
+		t.Errorf("Expected success rate 0.75, got %v", first.SuccessRate)
+	}
+	latency := first.Durations[domain.EventWebhookResponse]
+	if latency == nil || latency.Count != 4 || latency.P50 != 250 || latency.P99 != 397 {
+		t.Errorf("Expected p50 250 and p99 397 over 4 durations, got %+v", latency)
+	}
+
+	empty := series.Buckets[1]
+	if !empty.Start.Equal(start.Add(time.Minute)) || empty.TotalCount != 0 || empty.SuccessRate != nil {
+		t.Errorf("Expected an empty bucket at %v, got %+v", start.Add(time.Minute), empty)
+	}
+
+	last := series.Buckets[2]
+	if last.SuccessRate == nil || *last.SuccessRate != 0 {
+		t.Errorf("Expected success rate 0 in the last bucket, got %v", last.SuccessRate)
+	}
+}
+
+func TestAuditService_GetAuditLogTimeSeries_InvalidWindow(t *testing.T) {
+	service := NewAuditService(repository.NewMockAuditRepository())
+
+	now := time.Now()
+	weekAgo := now.Add(-7 * 24 * time.Hour)
+
+	tests := []struct {
+		name     string
+		filter   *domain.AuditLogFilter
+		interval domain.AuditStatsInterval
+	}{
+		{"unknown interval", &domain.AuditLogFilter{}, domain.AuditStatsInterval("week")},
+		{"reversed dates", &domain.AuditLogFilter{FromDate: &now, ToDate: &weekAgo}, domain.AuditStatsHour},
+		{"too many buckets", &domain.AuditLogFilter{FromDate: &weekAgo}, domain.AuditStatsMinute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.GetAuditLogTimeSeries(context.Background(), tt.filter, tt.interval)
+			if !errors.Is(err, domain.ErrInvalidStatsWindow) {
+				t.Errorf("Expected ErrInvalidStatsWindow, got %v", err)
+			}
+		})
+	}
+}
+
 func TestAuditService_CleanupOldAuditLogs(t *testing.T) {
 	auditRepo := repository.NewMockAuditRepository()
 	service := NewAuditService(auditRepo)
@@ -589,6 +784,133 @@ func TestAuditService_CleanupOldAuditLogs(t *testing.T) {
 	}
 }
 
+func TestAuditService_ApplyRetention(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	service := NewAuditService(auditRepo)
+
+	now := time.Now()
+	addLog := func(eventType domain.AuditEventType, age time.Duration) {
+		auditLog := domain.NewAuditLog(eventType, string(eventType)).Build()
+		auditLog.CreatedAt = now.Add(-age)
+		auditRepo.AddLog(auditLog)
+	}
+	for i := 0; i < 5; i++ {
+		addLog(domain.EventAPIRequest, 8*24*time.Hour)
+	}
+	addLog(domain.EventAPIRequest, time.Hour)
+	addLog(domain.EventMessageSent, 365*24*time.Hour)
+	addLog(domain.EventMessageSent, 800*24*time.Hour)
+	addLog(domain.EventBatchStarted, 100*24*time.Hour)
+
+	policy := domain.AuditRetentionPolicy{
+		Default: 90 * 24 * time.Hour,
+		Rules: map[domain.AuditEventType]time.Duration{
+			domain.EventAPIRequest:  7 * 24 * time.Hour,
+			domain.EventMessageSent: 730 * 24 * time.Hour,
+		},
+	}
+
+	result, err := service.ApplyRetention(context.Background(), policy, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Total != 7 {
+		t.Errorf("Expected 7 deleted logs, got %d", result.Total)
+	}
+	// 7 expired logs in batches of 2: three full batches and a short last one
+	if result.Batches != 4 {
+		t.Errorf("Expected 4 batches, got %d", result.Batches)
+	}
+	expected := map[domain.AuditEventType]int64{
+		domain.EventAPIRequest:   5,
+		domain.EventMessageSent:  1,
+		domain.EventBatchStarted: 1,
+	}
+	for eventType, count := range expected {
+		if result.Deleted[eventType] != count {
+			t.Errorf("Expected %d %s logs deleted, got %d", count, eventType, result.Deleted[eventType])
+		}
+	}
+	if auditRepo.Count() != 2 {
+		t.Errorf("Expected 2 logs kept, got %d", auditRepo.Count())
+	}
+}
+
+func TestAuditService_ApplyRetention_Error(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	service := NewAuditService(auditRepo)
+
+	calls := 0
+	auditRepo.PruneAuditLogsFunc = func(ctx context.Context, cutoffs domain.AuditRetentionCutoffs, limit int) (map[domain.AuditEventType]int64, error) {
+		calls++
+		if calls > 1 {
+			return nil, errors.New("database error")
+		}
+		return map[domain.AuditEventType]int64{domain.EventAPIRequest: int64(limit)}, nil
+	}
+
+	policy := domain.AuditRetentionPolicy{Default: time.Hour}
+	result, err := service.ApplyRetention(context.Background(), policy, 10)
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+	if result.Total != 10 || result.Batches != 1 {
+		t.Errorf("Expected the first batch of 10 to be reported, got %d in %d batches", result.Total, result.Batches)
+	}
+}
+
+func TestAuditService_ApplyRetention_InvalidBatchSize(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	service := NewAuditService(auditRepo)
+
+	calls := 0
+	auditRepo.PruneAuditLogsFunc = func(ctx context.Context, cutoffs domain.AuditRetentionCutoffs, limit int) (map[domain.AuditEventType]int64, error) {
+		calls++
+		return map[domain.AuditEventType]int64{}, nil
+	}
+
+	policy := domain.AuditRetentionPolicy{Default: time.Hour}
+	if _, err := service.ApplyRetention(context.Background(), policy, 0); err == nil {
+		t.Error("Expected an error for a zero batch size")
+	}
+	if calls != 0 {
+		t.Errorf("Expected no prune without a valid batch size, got %d", calls)
+	}
+}
+
+func TestAuditService_LogAuditPruned(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	service := NewAuditService(auditRepo)
+
+	policy := domain.AuditRetentionPolicy{Default: 24 * time.Hour}
+	result := &domain.AuditPruneResult{
+		Deleted:    map[domain.AuditEventType]int64{domain.EventAPIRequest: 3},
+		Total:      3,
+		Batches:    1,
+		DurationMs: 12,
+	}
+	if err := service.LogAuditPruned(context.Background(), policy, result); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	logs, _ := auditRepo.GetAuditLogs(context.Background(), &domain.AuditLogFilter{})
+	if len(logs) != 1 {
+		t.Fatalf("Expected 1 audit log, got %d", len(logs))
+	}
+	auditLog := logs[0]
+	if auditLog.EventType != domain.EventAuditPruned {
+		t.Errorf("Expected event type %s, got %s", domain.EventAuditPruned, auditLog.EventType)
+	}
+	deleted, ok := auditLog.Metadata["deleted"].(map[string]interface{})
+	if !ok || deleted["api_request"] != int64(3) {
+		t.Errorf("Expected per-type counts in metadata, got %v", auditLog.Metadata["deleted"])
+	}
+	if auditLog.DurationMs == nil || *auditLog.DurationMs != 12 {
+		t.Errorf("Expected duration 12ms, got %v", auditLog.DurationMs)
+	}
+}
+
 func TestAuditService_RepositoryError(t *testing.T) {
 	auditRepo := repository.NewMockAuditRepository()
 	service := NewAuditService(auditRepo)
@@ -607,3 +929,254 @@ func TestAuditService_RepositoryError(t *testing.T) {
 		t.Errorf("Expected no error due to fallback, got %v", err)
 	}
 }
+
+// buildAuditChain returns count entries linked into a hash chain starting at sequence 1
+func buildAuditChain(t *testing.T, count int) []*domain.AuditLog {
+	t.Helper()
+
+	prevHash := domain.AuditChainGenesisHash
+	start := time.Now().Add(-time.Hour)
+	entries := make([]*domain.AuditLog, count)
+	for i := range entries {
+		entry := domain.NewAuditLog(domain.EventAPIRequest, "API Request Processed").
+			WithMetadata("index", i).
+			Build()
+		entry.CreatedAt = start.Add(time.Duration(i) * time.Minute)
+
+		seq := int64(i + 1)
+		hash, err := entry.ChainHash(seq, prevHash, repository.MockAuditChainKey)
+		if err != nil {
+			t.Fatalf("Failed to hash entry: %v", err)
+		}
+		linkedPrev := prevHash
+		entry.ChainSeq = &seq
+		entry.PrevHash = &linkedPrev
+		entry.Hash = &hash
+
+		entries[i] = entry
+		prevHash = hash
+	}
+	return entries
+}
+
+func TestAuditService_VerifyAuditChain(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	for _, entry := range buildAuditChain(t, 5) {
+		auditRepo.AddLog(entry)
+	}
+
+	result, err := NewAuditService(auditRepo).VerifyAuditChain(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !result.Valid || result.Break != nil {
+		t.Fatalf("Expected a valid chain, got break %+v", result.Break)
+	}
+	if result.Checked != 5 || result.FirstSeq != 1 || result.LastSeq != 5 {
+		t.Errorf("Expected 5 entries checked from 1 to 5, got %+v", result)
+	}
+	if result.Anchor != domain.AuditChainAnchorGenesis {
+		t.Errorf("Expected genesis anchor, got %s", result.Anchor)
+	}
+}
+
+func TestAuditService_VerifyAuditChainDetectsEdit(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	entries := buildAuditChain(t, 5)
+	for _, entry := range entries {
+		auditRepo.AddLog(entry)
+	}
+
+	entries[2].Metadata["index"] = 99
+
+	result, err := NewAuditService(auditRepo).VerifyAuditChain(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Valid || result.Break == nil {
+		t.Fatal("Expected the edited entry to break the chain")
+	}
+	if result.Break.ChainSeq != 3 || *result.Break.AuditLogID != entries[2].ID {
+		t.Errorf("Expected the break at entry 3, got %+v", result.Break)
+	}
+	if result.Checked != 2 {
+		t.Errorf("Expected 2 entries checked before the break, got %d", result.Checked)
+	}
+}
+
+func TestAuditService_VerifyAuditChainDetectsDeletion(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	for i, entry := range buildAuditChain(t, 5) {
+		if i == 3 {
+			continue
+		}
+		auditRepo.AddLog(entry)
+	}
+
+	result, err := NewAuditService(auditRepo).VerifyAuditChain(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Valid || result.Break == nil || result.Break.ChainSeq != 4 {
+		t.Fatalf("Expected a missing entry at 4, got %+v", result.Break)
+	}
+}
+
+func TestAuditService_VerifyAuditChainDetectsTruncation(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	entries := buildAuditChain(t, 5)
+	for _, entry := range entries[:3] {
+		auditRepo.AddLog(entry)
+	}
+	// The head still records the deleted newest entry
+	auditRepo.GetAuditChainHeadFunc = func(ctx context.Context) (*domain.AuditChainHead, error) {
+		return &domain.AuditChainHead{ChainSeq: 5, Hash: *entries[4].Hash, KeyedFrom: 1, SignatureValid: true}, nil
+	}
+
+	result, err := NewAuditService(auditRepo).VerifyAuditChain(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Valid || result.Break == nil || result.Break.ChainSeq != 4 {
+		t.Fatalf("Expected entries missing from 4, got %+v", result.Break)
+	}
+	if result.HeadSeq != 5 || result.Checked != 3 {
+		t.Errorf("Expected head 5 and 3 entries checked, got %+v", result)
+	}
+}
+
+func TestAuditService_VerifyAuditChainDetectsUnsignedHead(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	entries := buildAuditChain(t, 3)
+	for _, entry := range entries {
+		auditRepo.AddLog(entry)
+	}
+	auditRepo.GetAuditChainHeadFunc = func(ctx context.Context) (*domain.AuditChainHead, error) {
+		return &domain.AuditChainHead{ChainSeq: 3, Hash: *entries[2].Hash, KeyedFrom: 1}, nil
+	}
+
+	result, err := NewAuditService(auditRepo).VerifyAuditChain(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Valid || result.Break == nil || result.Break.ChainSeq != 3 {
+		t.Fatalf("Expected the head signature to break the chain, got %+v", result.Break)
+	}
+}
+
+func TestAuditService_VerifyAuditChainUnkeyedPrefix(t *testing.T) {
+	// Entries 1 and 2 were chained before link hashes were keyed
+	prevHash := domain.AuditChainGenesisHash
+	auditRepo := repository.NewMockAuditRepository()
+	for i := 0; i < 4; i++ {
+		entry := domain.NewAuditLog(domain.EventAPIRequest, "API Request Processed").Build()
+		seq := int64(i + 1)
+		var key []byte
+		if seq >= 3 {
+			key = repository.MockAuditChainKey
+		}
+		hash, err := entry.ChainHash(seq, prevHash, key)
+		if err != nil {
+			t.Fatalf("Failed to hash entry: %v", err)
+		}
+		linkedPrev := prevHash
+		entry.ChainSeq, entry.PrevHash, entry.Hash = &seq, &linkedPrev, &hash
+		auditRepo.AddLog(entry)
+		prevHash = hash
+	}
+	auditRepo.GetAuditChainHeadFunc = func(ctx context.Context) (*domain.AuditChainHead, error) {
+		return &domain.AuditChainHead{ChainSeq: 4, Hash: prevHash, KeyedFrom: 3, SignatureValid: true}, nil
+	}
+
+	result, err := NewAuditService(auditRepo).VerifyAuditChain(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !result.Valid || result.Checked != 4 {
+		t.Fatalf("Expected 4 valid entries, got %+v (break %+v)", result, result.Break)
+	}
+}
+
+func TestAuditService_VerifyAuditChainAfterPruning(t *testing.T) {
+	key := []byte("checkpoint-key")
+	entries := buildAuditChain(t, 5)
+
+	newRepo := func(checkpoint *domain.AuditChainCheckpoint) *repository.MockAuditRepository {
+		auditRepo := repository.NewMockAuditRepository()
+		for _, entry := range entries[2:] {
+			auditRepo.AddLog(entry)
+		}
+		if checkpoint != nil {
+			auditRepo.AddCheckpoint(checkpoint)
+		}
+		return auditRepo
+	}
+
+	t.Run("signed checkpoint", func(t *testing.T) {
+		checkpoint := &domain.AuditChainCheckpoint{
+			ChainSeq:     2,
+			Hash:         *entries[1].Hash,
+			PrunedBefore: entries[2].CreatedAt,
+			DeletedCount: 2,
+			CreatedAt:    time.Now(),
+		}
+		checkpoint.Sign(key)
+		checkpoint.SignatureValid = checkpoint.VerifySignature(key)
+
+		result, err := NewAuditService(newRepo(checkpoint)).VerifyAuditChain(context.Background(), nil, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !result.Valid || result.Anchor != domain.AuditChainAnchorCheckpoint || result.Checked != 3 {
+			t.Errorf("Expected 3 entries verified from the checkpoint, got %+v (break %+v)", result, result.Break)
+		}
+	})
+
+	t.Run("no checkpoint", func(t *testing.T) {
+		result, err := NewAuditService(newRepo(nil)).VerifyAuditChain(context.Background(), nil, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Valid || result.Break == nil || result.Break.ChainSeq != 2 {
+			t.Errorf("Expected a break at the pruned entry, got %+v", result.Break)
+		}
+	})
+
+	t.Run("forged checkpoint", func(t *testing.T) {
+		checkpoint := &domain.AuditChainCheckpoint{ChainSeq: 2, Hash: *entries[1].Hash, Signature: "forged"}
+
+		result, err := NewAuditService(newRepo(checkpoint)).VerifyAuditChain(context.Background(), nil, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Valid || result.Break == nil {
+			t.Error("Expected an unsigned checkpoint to break the chain")
+		}
+	})
+}
+
+func TestAuditService_VerifyAuditChainRange(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	entries := buildAuditChain(t, 5)
+	for _, entry := range entries {
+		auditRepo.AddLog(entry)
+	}
+
+	from := entries[2].CreatedAt
+	result, err := NewAuditService(auditRepo).VerifyAuditChain(context.Background(), &from, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !result.Valid || result.FirstSeq != 3 || result.Checked != 3 {
+		t.Errorf("Expected entries 3 to 5 verified, got %+v", result)
+	}
+	if result.Anchor != domain.AuditChainAnchorPreviousEntry {
+		t.Errorf("Expected the previous entry as anchor, got %s", result.Anchor)
+	}
+}
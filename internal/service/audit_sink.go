@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+)
+
+// auditSinkBatchSize is the most entries handed to a sink in one Write
+const auditSinkBatchSize = 100
+
+// auditSinkWriteTimeout bounds a single Write to a sink
+const auditSinkWriteTimeout = 30 * time.Second
+
+// auditSinkRetryPolicy paces retries of a batch a sink failed to take
+var auditSinkRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Second,
+	MaxDelay:    10 * time.Second,
+}
+
+// AuditSink delivers audit entries somewhere besides the database
+type AuditSink interface {
+	// Name identifies the sink in logs and stats
+	Name() string
+	// Write delivers entries in the order they were written
+	Write(ctx context.Context, auditLogs []*domain.AuditLog) error
+	// Close releases the sink once nothing more will be written
+	Close() error
+}
+
+// AuditSinkConfig controls which entries reach a sink and how far it may fall behind
+type AuditSinkConfig struct {
+	// EventTypes limits the sink to these event types; empty sends every entry
+	EventTypes []domain.AuditEventType
+	// QueueSize is how many entries may wait for the sink before new ones are dropped
+	QueueSize int
+}
+
+// AuditSinkStats reports how entries fared with one sink
+type AuditSinkStats struct {
+	Name       string `json:"name" example:"file"`
+	QueueDepth int    `json:"queue_depth" example:"0"`
+	Written    uint64 `json:"written" example:"5120"`
+	Dropped    uint64 `json:"dropped" example:"0"`
+	Failed     uint64 `json:"failed" example:"0"`
+}
+
+// AuditSinks is an AuditRepository that also hands every entry written through it to a set
+// of sinks. Each sink has its own queue and goroutine, so a slow or failing sink delays
+// neither the database write nor the other sinks. A sink's failed batches are retried a few
+// times and then dropped, and entries are dropped while its queue is full. Reads go straight
+// to the wrapped repository.
+type AuditSinks struct {
+	repository.AuditRepository
+
+	sinks       []*auditSinkQueue
+	retryPolicy RetryPolicy
+
+	// mu guards closed; writers hold it for reading while enqueueing so Close cannot
+	// close the queues under them
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+
+	// ctx is cancelled when Close gives up waiting, to abandon retries and slow writes
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+type auditSinkQueue struct {
+	sink       AuditSink
+	eventTypes map[domain.AuditEventType]bool
+	queue      chan *domain.AuditLog
+
+	written uint64
+	dropped uint64
+	failed  uint64
+}
+
+func NewAuditSinks(repo repository.AuditRepository) *AuditSinks {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &AuditSinks{
+		AuditRepository: repo,
+		retryPolicy:     auditSinkRetryPolicy,
+		ctx:             ctx,
+		cancel:          cancel,
+	}
+}
+
+// Add registers a sink; call it before Start
+func (s *AuditSinks) Add(sink AuditSink, cfg AuditSinkConfig) {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+
+	var eventTypes map[domain.AuditEventType]bool
+	if len(cfg.EventTypes) > 0 {
+		eventTypes = make(map[domain.AuditEventType]bool, len(cfg.EventTypes))
+		for _, eventType := range cfg.EventTypes {
+			eventTypes[eventType] = true
+		}
+	}
+
+	s.sinks = append(s.sinks, &auditSinkQueue{
+		sink:       sink,
+		eventTypes: eventTypes,
+		queue:      make(chan *domain.AuditLog, cfg.QueueSize),
+	})
+}
+
+// Len returns how many sinks are registered
+func (s *AuditSinks) Len() int {
+	return len(s.sinks)
+}
+
+// Start begins delivering entries to every sink in the background
+func (s *AuditSinks) Start() {
+	for _, q := range s.sinks {
+		s.wg.Add(1)
+		go s.run(q)
+
+		log.Printf("Audit sink %s started with queue size: %d", q.sink.Name(), cap(q.queue))
+	}
+}
+
+// Log writes the entry to the wrapped repository and hands it to the sinks whether or not
+// that write succeeded. The sinks get it afterwards since the repository may still fill in
+// fields such as the chain hash.
+func (s *AuditSinks) Log(ctx context.Context, auditLog *domain.AuditLog) error {
+	err := s.AuditRepository.Log(ctx, auditLog)
+	s.publish(auditLog)
+	return err
+}
+
+// LogBatch writes the entries to the wrapped repository and hands them to the sinks once
+// the batch is stored. A failed batch is left to the caller, which retries it entry by entry
+// through Log.
+func (s *AuditSinks) LogBatch(ctx context.Context, auditLogs []*domain.AuditLog) error {
+	if err := s.AuditRepository.LogBatch(ctx, auditLogs); err != nil {
+		return err
+	}
+	for _, auditLog := range auditLogs {
+		s.publish(auditLog)
+	}
+	return nil
+}
+
+func (s *AuditSinks) publish(auditLog *domain.AuditLog) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return
+	}
+
+	for _, q := range s.sinks {
+		if q.eventTypes != nil && !q.eventTypes[auditLog.EventType] {
+			continue
+		}
+		select {
+		case q.queue <- auditLog:
+		default:
+			atomic.AddUint64(&q.dropped, 1)
+		}
+	}
+}
+
+// Close delivers the queued entries, waiting until ctx is done, and closes every sink.
+// Entries written after Close only go to the wrapped repository.
+func (s *AuditSinks) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	for _, q := range s.sinks {
+		close(q.queue)
+	}
+	s.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+		s.cancel()
+		<-drained
+	}
+	s.cancel()
+
+	for _, q := range s.sinks {
+		if closeErr := q.sink.Close(); closeErr != nil {
+			log.Printf("Error closing audit sink %s: %v", q.sink.Name(), closeErr)
+		}
+		log.Printf("Audit sink %s stopped (written: %d, dropped: %d, failed: %d)", q.sink.Name(),
+			atomic.LoadUint64(&q.written), atomic.LoadUint64(&q.dropped), atomic.LoadUint64(&q.failed))
+	}
+
+	return err
+}
+
+// Stats returns the state of every sink
+func (s *AuditSinks) Stats() []AuditSinkStats {
+	stats := make([]AuditSinkStats, 0, len(s.sinks))
+	for _, q := range s.sinks {
+		stats = append(stats, AuditSinkStats{
+			Name:       q.sink.Name(),
+			QueueDepth: len(q.queue),
+			Written:    atomic.LoadUint64(&q.written),
+			Dropped:    atomic.LoadUint64(&q.dropped),
+			Failed:     atomic.LoadUint64(&q.failed),
+		})
+	}
+	return stats
+}
+
+func (s *AuditSinks) run(q *auditSinkQueue) {
+	defer s.wg.Done()
+
+	for auditLog := range q.queue {
+		batch := []*domain.AuditLog{auditLog}
+	fill:
+		for len(batch) < auditSinkBatchSize {
+			select {
+			case next, ok := <-q.queue:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, next)
+			default:
+				break fill
+			}
+		}
+
+		if err := s.write(q.sink, batch); err != nil {
+			atomic.AddUint64(&q.failed, uint64(len(batch)))
+			log.Printf("AUDIT SINK FAILED: dropped %d entries for %s: %v", len(batch), q.sink.Name(), err)
+			continue
+		}
+		atomic.AddUint64(&q.written, uint64(len(batch)))
+	}
+}
+
+// write hands a batch to the sink, retrying it with backoff
+func (s *AuditSinks) write(sink AuditSink, batch []*domain.AuditLog) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(s.ctx, auditSinkWriteTimeout)
+		err = sink.Write(ctx, batch)
+		cancel()
+		if err == nil || attempt >= s.retryPolicy.MaxAttempts {
+			return err
+		}
+
+		timer := time.NewTimer(s.retryPolicy.Backoff(attempt))
+		select {
+		case <-timer.C:
+		case <-s.ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
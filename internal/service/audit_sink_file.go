@@ -0,0 +1,145 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"ims/internal/domain"
+)
+
+// FileAuditSink appends audit entries to a local file as JSON lines. Once the file would grow
+// past maxBytes it is renamed to path.1, older backups shift up by one, and the oldest beyond
+// maxBackups is removed. The file is opened again on the next write when a rotation fails
+// part way.
+type FileAuditSink struct {
+	path       string
+	maxBytes   int64
+	maxBackups int
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	closed bool
+}
+
+func NewFileAuditSink(path string, maxBytes int64, maxBackups int) (*FileAuditSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create audit sink directory: %w", err)
+	}
+
+	s := &FileAuditSink{
+		path:       path,
+		maxBytes:   maxBytes,
+		maxBackups: maxBackups,
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileAuditSink) Name() string {
+	return "file"
+}
+
+func (s *FileAuditSink) Write(ctx context.Context, auditLogs []*domain.AuditLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("audit sink file %s is closed", s.path)
+	}
+	if s.file == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+
+	var line bytes.Buffer
+	for _, auditLog := range auditLogs {
+		line.Reset()
+		if err := json.NewEncoder(&line).Encode(auditLog); err != nil {
+			return fmt.Errorf("failed to encode audit log: %w", err)
+		}
+
+		if s.maxBytes > 0 && s.size > 0 && s.size+int64(line.Len()) > s.maxBytes {
+			if err := s.rotate(); err != nil {
+				return err
+			}
+		}
+
+		n, err := s.file.Write(line.Bytes())
+		s.size += int64(n)
+		if err != nil {
+			return fmt.Errorf("failed to write audit sink file: %w", err)
+		}
+	}
+
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit sink file: %w", err)
+	}
+	return nil
+}
+
+func (s *FileAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func (s *FileAuditSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit sink file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat audit sink file: %w", err)
+	}
+
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// rotate leaves s.file nil when it fails, so the next Write opens the file again
+func (s *FileAuditSink) rotate() error {
+	err := s.file.Close()
+	s.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to close audit sink file: %w", err)
+	}
+
+	if s.maxBackups > 0 {
+		for i := s.maxBackups - 1; i >= 1; i-- {
+			err := os.Rename(s.backupPath(i), s.backupPath(i+1))
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to rotate audit sink file: %w", err)
+			}
+		}
+		if err := os.Rename(s.path, s.backupPath(1)); err != nil {
+			return fmt.Errorf("failed to rotate audit sink file: %w", err)
+		}
+	} else if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rotate audit sink file: %w", err)
+	}
+
+	return s.open()
+}
+
+func (s *FileAuditSink) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", s.path, n)
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ims/internal/domain"
+)
+
+func readAuditLines(t *testing.T, path string) []*domain.AuditLog {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Expected %s to exist, got %v", path, err)
+	}
+	defer file.Close()
+
+	var logs []*domain.AuditLog
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var auditLog domain.AuditLog
+		if err := json.Unmarshal(scanner.Bytes(), &auditLog); err != nil {
+			t.Fatalf("Expected a JSON line, got %q: %v", scanner.Text(), err)
+		}
+		logs = append(logs, &auditLog)
+	}
+	return logs
+}
+
+func TestFileAuditSink_WritesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	sink, err := NewFileAuditSink(path, 0, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	first := domain.NewAuditLog(domain.EventMessageSent, "Message Sent").Build()
+	second := domain.NewAuditLog(domain.EventMessageFailed, "Message Failed").Build()
+	if err := sink.Write(context.Background(), []*domain.AuditLog{first, second}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	logs := readAuditLines(t, path)
+	if len(logs) != 2 || logs[0].ID != first.ID || logs[1].ID != second.ID {
+		t.Errorf("Expected both entries in order, got %d", len(logs))
+	}
+
+	if err := sink.Write(context.Background(), []*domain.AuditLog{first}); err == nil {
+		t.Error("Expected an error writing to a closed sink")
+	}
+}
+
+func TestFileAuditSink_Rotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	entry := domain.NewAuditLog(domain.EventAPIRequest, "API Request").Build()
+	line, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Room for two lines per file, keeping two backups
+	sink, err := NewFileAuditSink(path, int64(2*(len(line)+1)), 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer sink.Close()
+
+	var written []*domain.AuditLog
+	for i := 0; i < 7; i++ {
+		auditLog := domain.NewAuditLog(domain.EventAPIRequest, "API Request").Build()
+		auditLog.CreatedAt = entry.CreatedAt
+		written = append(written, auditLog)
+		if err := sink.Write(context.Background(), []*domain.AuditLog{auditLog}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	// 7 entries: the oldest pair rotated away, then .2, .1 and the current file
+	for _, file := range []struct {
+		path string
+		ids  []*domain.AuditLog
+	}{
+		{path + ".2", written[2:4]},
+		{path + ".1", written[4:6]},
+		{path, written[6:]},
+	} {
+		logs := readAuditLines(t, file.path)
+		if len(logs) != len(file.ids) {
+			t.Fatalf("Expected %d entries in %s, got %d", len(file.ids), file.path, len(logs))
+		}
+		for i := range logs {
+			if logs[i].ID != file.ids[i].ID {
+				t.Errorf("Expected %s at %d in %s, got %s", file.ids[i].ID, i, file.path, logs[i].ID)
+			}
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected no third backup, got %v", err)
+	}
+}
+
+func TestFileAuditSink_RecoversFromFailedRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileAuditSink(path, 1, 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer sink.Close()
+
+	first := domain.NewAuditLog(domain.EventAPIRequest, "API Request").Build()
+	if err := sink.Write(context.Background(), []*domain.AuditLog{first}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// A non-empty directory in the way of the backup makes the rename fail
+	blocker := filepath.Join(path+".1", "blocker")
+	if err := os.MkdirAll(blocker, 0o755); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	second := domain.NewAuditLog(domain.EventAPIRequest, "API Request").Build()
+	if err := sink.Write(context.Background(), []*domain.AuditLog{second}); err == nil {
+		t.Fatal("Expected the rotation to fail")
+	}
+
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := sink.Write(context.Background(), []*domain.AuditLog{second}); err != nil {
+		t.Fatalf("Expected the sink to recover, got %v", err)
+	}
+
+	if logs := readAuditLines(t, path+".1"); len(logs) != 1 || logs[0].ID != first.ID {
+		t.Errorf("Expected the first entry in the backup, got %d entries", len(logs))
+	}
+	if logs := readAuditLines(t, path); len(logs) != 1 || logs[0].ID != second.ID {
+		t.Errorf("Expected the second entry in the current file, got %d entries", len(logs))
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"ims/internal/domain"
+)
+
+// HTTPAuditSink posts batches of audit entries to a collector as a JSON array. Any 2xx
+// response counts as delivered.
+type HTTPAuditSink struct {
+	url           string
+	authorization string
+	client        *http.Client
+}
+
+// NewHTTPAuditSink returns a sink posting to url; authorization, when set, is sent as the
+// Authorization header
+func NewHTTPAuditSink(url, authorization string, timeout time.Duration) *HTTPAuditSink {
+	return &HTTPAuditSink{
+		url:           url,
+		authorization: authorization,
+		client:        &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *HTTPAuditSink) Name() string {
+	return "http"
+}
+
+func (s *HTTPAuditSink) Write(ctx context.Context, auditLogs []*domain.AuditLog) error {
+	body, err := json.Marshal(auditLogs)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit logs: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.authorization != "" {
+		req.Header.Set("Authorization", s.authorization)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post audit logs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Drain so the connection can be reused
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("audit collector returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *HTTPAuditSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ims/internal/domain"
+)
+
+func TestHTTPAuditSink_PostsBatch(t *testing.T) {
+	var received []*domain.AuditLog
+	var authorization, contentType string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		contentType = r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer collector.Close()
+
+	sink := NewHTTPAuditSink(collector.URL, "Bearer secret", time.Second)
+	defer sink.Close()
+
+	logs := []*domain.AuditLog{
+		domain.NewAuditLog(domain.EventMessageSent, "Message Sent").Build(),
+		domain.NewAuditLog(domain.EventMessageFailed, "Message Failed").Build(),
+	}
+	if err := sink.Write(context.Background(), logs); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(received) != 2 || received[0].ID != logs[0].ID || received[1].ID != logs[1].ID {
+		t.Errorf("Expected both entries in order, got %d", len(received))
+	}
+	if authorization != "Bearer secret" {
+		t.Errorf("Expected the Authorization header, got %q", authorization)
+	}
+	if contentType != "application/json" {
+		t.Errorf("Expected application/json, got %q", contentType)
+	}
+}
+
+func TestHTTPAuditSink_ErrorStatus(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer collector.Close()
+
+	sink := NewHTTPAuditSink(collector.URL, "", time.Second)
+	defer sink.Close()
+
+	err := sink.Write(context.Background(), []*domain.AuditLog{domain.NewAuditLog(domain.EventAPIRequest, "API Request").Build()})
+	if err == nil {
+		t.Error("Expected an error for a 503 response")
+	}
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"ims/internal/domain"
+)
+
+// Syslog priority parts, RFC 5424 section 6.2.1
+const (
+	syslogFacilityLocal0  = 16
+	syslogSeverityWarning = 4
+	syslogSeverityInfo    = 6
+)
+
+// syslogDialTimeout bounds connecting to the collector
+const syslogDialTimeout = 5 * time.Second
+
+// syslogMaxUDPMessage is the largest message sent over UDP; RFC 5426 section 3.2 only
+// guarantees delivery up to 2048 octets
+const syslogMaxUDPMessage = 2048
+
+// SyslogAuditSink sends each audit entry as an RFC 5424 message to a syslog collector over
+// UDP or TCP. The MSGID is the event type and the message is the entry as JSON. Over TCP
+// messages are framed by octet counting (RFC 6587), and the connection is redialed after a
+// failed write. Over UDP messages longer than syslogMaxUDPMessage are truncated.
+type SyslogAuditSink struct {
+	network  string
+	address  string
+	tag      string
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func NewSyslogAuditSink(network, address, tag string) (*SyslogAuditSink, error) {
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("unsupported syslog network %q, expected udp or tcp", network)
+	}
+	if tag == "" {
+		tag = "-"
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	return &SyslogAuditSink{
+		network:  network,
+		address:  address,
+		tag:      tag,
+		hostname: hostname,
+	}, nil
+}
+
+func (s *SyslogAuditSink) Name() string {
+	return "syslog"
+}
+
+func (s *SyslogAuditSink) Write(ctx context.Context, auditLogs []*domain.AuditLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		dialer := net.Dialer{Timeout: syslogDialTimeout}
+		conn, err := dialer.DialContext(ctx, s.network, s.address)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog collector: %w", err)
+		}
+		s.conn = conn
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		s.conn.SetWriteDeadline(deadline)
+	}
+
+	for _, auditLog := range auditLogs {
+		message, err := s.format(auditLog)
+		if err != nil {
+			return err
+		}
+		if s.network == "tcp" {
+			message = fmt.Sprintf("%d %s", len(message), message)
+		} else {
+			message = truncateSyslogMessage(message, syslogMaxUDPMessage)
+		}
+
+		if _, err := s.conn.Write([]byte(message)); err != nil {
+			s.conn.Close()
+			s.conn = nil
+			return fmt.Errorf("failed to write to syslog collector: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *SyslogAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// format renders an entry as
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func (s *SyslogAuditSink) format(auditLog *domain.AuditLog) (string, error) {
+	body, err := json.Marshal(auditLog)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit log: %w", err)
+	}
+
+	severity := syslogSeverityInfo
+	if strings.HasSuffix(string(auditLog.EventType), "_failed") {
+		severity = syslogSeverityWarning
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s - %s",
+		syslogFacilityLocal0*8+severity,
+		auditLog.CreatedAt.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		s.hostname,
+		s.tag,
+		os.Getpid(),
+		auditLog.EventType,
+		body,
+	), nil
+}
+
+// truncateSyslogMessage cuts a message to at most limit bytes without splitting a UTF-8 sequence
+func truncateSyslogMessage(message string, limit int) string {
+	if len(message) <= limit {
+		return message
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+	return message[:cut]
+}
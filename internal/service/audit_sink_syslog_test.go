@@ -0,0 +1,157 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"ims/internal/domain"
+)
+
+func TestSyslogAuditSink_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer conn.Close()
+
+	sink, err := NewSyslogAuditSink("udp", conn.LocalAddr().String(), "ims")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer sink.Close()
+
+	auditLog := domain.NewAuditLog(domain.EventMessageFailed, "Message Failed").Build()
+	if err := sink.Write(context.Background(), []*domain.AuditLog{auditLog}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	buf := make([]byte, 64*1024)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Expected a datagram, got %v", err)
+	}
+	message := string(buf[:n])
+
+	// local0.warning for a failure
+	if !strings.HasPrefix(message, "<132>1 ") {
+		t.Errorf("Expected priority 132 and version 1, got %q", message)
+	}
+	if !strings.Contains(message, " ims ") || !strings.Contains(message, " message_failed - {") {
+		t.Errorf("Expected the tag, event type and JSON body, got %q", message)
+	}
+	if !strings.Contains(message, auditLog.ID.String()) {
+		t.Errorf("Expected the entry ID in the message, got %q", message)
+	}
+}
+
+func TestSyslogAuditSink_UDPTruncatesLongMessages(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer conn.Close()
+
+	sink, err := NewSyslogAuditSink("udp", conn.LocalAddr().String(), "ims")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer sink.Close()
+
+	auditLog := domain.NewAuditLog(domain.EventAPIRequest, "API Request").
+		WithMetadata("body", strings.Repeat("é", 4096)).
+		Build()
+	if err := sink.Write(context.Background(), []*domain.AuditLog{auditLog}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	buf := make([]byte, 64*1024)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Expected a datagram, got %v", err)
+	}
+	if n > syslogMaxUDPMessage || n < syslogMaxUDPMessage-1 {
+		t.Errorf("Expected the message cut to %d bytes, got %d", syslogMaxUDPMessage, n)
+	}
+	if !utf8.Valid(buf[:n]) {
+		t.Error("Expected the cut not to split a UTF-8 sequence")
+	}
+}
+
+func TestSyslogAuditSink_TCPOctetCounting(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var messages []string
+		reader := bufio.NewReader(conn)
+		for len(messages) < 2 {
+			var length int
+			if _, err := fmt.Fscanf(reader, "%d ", &length); err != nil {
+				break
+			}
+			message := make([]byte, length)
+			if _, err := io.ReadFull(reader, message); err != nil {
+				break
+			}
+			messages = append(messages, string(message))
+		}
+		received <- messages
+	}()
+
+	sink, err := NewSyslogAuditSink("tcp", listener.Addr().String(), "ims")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer sink.Close()
+
+	logs := []*domain.AuditLog{
+		domain.NewAuditLog(domain.EventMessageSent, "Message Sent").Build(),
+		domain.NewAuditLog(domain.EventAPIRequest, "API Request").Build(),
+	}
+	if err := sink.Write(context.Background(), logs); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	select {
+	case messages := <-received:
+		if len(messages) != 2 {
+			t.Fatalf("Expected 2 framed messages, got %d", len(messages))
+		}
+		for i, message := range messages {
+			// local0.info otherwise
+			if !strings.HasPrefix(message, "<134>1 ") || !strings.HasSuffix(message, "}") {
+				t.Errorf("Expected a whole message, got %q", message)
+			}
+			if !strings.Contains(message, logs[i].ID.String()) {
+				t.Errorf("Expected %s in message %d, got %q", logs[i].ID, i, message)
+			}
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for messages")
+	}
+}
+
+func TestSyslogAuditSink_UnsupportedNetwork(t *testing.T) {
+	if _, err := NewSyslogAuditSink("unix", "/dev/log", "ims"); err == nil {
+		t.Error("Expected an error for an unsupported network")
+	}
+}
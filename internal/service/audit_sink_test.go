@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+)
+
+// recordingAuditSink keeps what it is given, failing every write while failing is set
+type recordingAuditSink struct {
+	name string
+
+	mu      sync.Mutex
+	logs    []*domain.AuditLog
+	failing bool
+	closed  bool
+}
+
+func (s *recordingAuditSink) Name() string {
+	return s.name
+}
+
+func (s *recordingAuditSink) Write(ctx context.Context, auditLogs []*domain.AuditLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failing {
+		return errors.New("collector unavailable")
+	}
+	s.logs = append(s.logs, auditLogs...)
+	return nil
+}
+
+func (s *recordingAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *recordingAuditSink) received() []*domain.AuditLog {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*domain.AuditLog(nil), s.logs...)
+}
+
+func newTestAuditSinks(repo repository.AuditRepository) *AuditSinks {
+	sinks := NewAuditSinks(repo)
+	sinks.retryPolicy = RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	return sinks
+}
+
+func closeAuditSinks(t *testing.T, sinks *AuditSinks) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sinks.Close(ctx); err != nil {
+		t.Fatalf("Expected no error closing sinks, got %v", err)
+	}
+}
+
+func TestAuditSinks_FansOutByEventType(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	sinks := newTestAuditSinks(auditRepo)
+
+	all := &recordingAuditSink{name: "all"}
+	failures := &recordingAuditSink{name: "failures"}
+	sinks.Add(all, AuditSinkConfig{})
+	sinks.Add(failures, AuditSinkConfig{EventTypes: []domain.AuditEventType{domain.EventMessageFailed}})
+	sinks.Start()
+
+	sent := domain.NewAuditLog(domain.EventMessageSent, "Message Sent").Build()
+	failed := domain.NewAuditLog(domain.EventMessageFailed, "Message Failed").Build()
+	if err := sinks.Log(context.Background(), sent); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := sinks.LogBatch(context.Background(), []*domain.AuditLog{failed}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	closeAuditSinks(t, sinks)
+
+	if got := storedAuditLogs(t, auditRepo); len(got) != 2 {
+		t.Errorf("Expected 2 stored entries, got %d", len(got))
+	}
+	if got := all.received(); len(got) != 2 || got[0].ID != sent.ID || got[1].ID != failed.ID {
+		t.Errorf("Expected both entries in order, got %d", len(got))
+	}
+	if got := failures.received(); len(got) != 1 || got[0].ID != failed.ID {
+		t.Errorf("Expected only the failure, got %d entries", len(got))
+	}
+	if !all.closed || !failures.closed {
+		t.Error("Expected every sink to be closed")
+	}
+}
+
+func TestAuditSinks_IsolatesFailingSink(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	sinks := newTestAuditSinks(auditRepo)
+
+	broken := &recordingAuditSink{name: "broken", failing: true}
+	healthy := &recordingAuditSink{name: "healthy"}
+	sinks.Add(broken, AuditSinkConfig{})
+	sinks.Add(healthy, AuditSinkConfig{})
+	sinks.Start()
+
+	for i := 0; i < 3; i++ {
+		if err := sinks.Log(context.Background(), domain.NewAuditLog(domain.EventAPIRequest, "API Request").Build()); err != nil {
+			t.Fatalf("Expected the database write to succeed, got %v", err)
+		}
+	}
+
+	closeAuditSinks(t, sinks)
+
+	if got := healthy.received(); len(got) != 3 {
+		t.Errorf("Expected the healthy sink to get 3 entries, got %d", len(got))
+	}
+
+	stats := sinks.Stats()
+	if stats[0].Name != "broken" || stats[0].Failed != 3 || stats[0].Written != 0 {
+		t.Errorf("Expected 3 failed entries for the broken sink, got %+v", stats[0])
+	}
+	if stats[1].Name != "healthy" || stats[1].Written != 3 || stats[1].Failed != 0 {
+		t.Errorf("Expected 3 written entries for the healthy sink, got %+v", stats[1])
+	}
+}
+
+func TestAuditSinks_DatabaseFailure(t *testing.T) {
+	auditRepo := newFlakyAuditRepository()
+	auditRepo.setDown(true)
+	sinks := newTestAuditSinks(auditRepo)
+
+	sink := &recordingAuditSink{name: "file"}
+	sinks.Add(sink, AuditSinkConfig{})
+	sinks.Start()
+
+	// A failed batch is retried entry by entry by the caller, so only Log reaches the sink
+	batch := []*domain.AuditLog{domain.NewAuditLog(domain.EventAPIRequest, "API Request").Build()}
+	if err := sinks.LogBatch(context.Background(), batch); err == nil {
+		t.Fatal("Expected the batch write to fail")
+	}
+	if err := sinks.Log(context.Background(), batch[0]); err == nil {
+		t.Fatal("Expected the write to fail")
+	}
+
+	closeAuditSinks(t, sinks)
+
+	if got := sink.received(); len(got) != 1 || got[0].ID != batch[0].ID {
+		t.Errorf("Expected the entry once despite the database failure, got %d", len(got))
+	}
+}
+
+func TestAuditSinks_DropsWhenQueueFull(t *testing.T) {
+	sinks := newTestAuditSinks(repository.NewMockAuditRepository())
+	sink := &recordingAuditSink{name: "http"}
+	sinks.Add(sink, AuditSinkConfig{QueueSize: 2})
+
+	// Not started, so nothing drains the queue
+	for i := 0; i < 5; i++ {
+		if err := sinks.Log(context.Background(), domain.NewAuditLog(domain.EventAPIRequest, "API Request").Build()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	stats := sinks.Stats()
+	if stats[0].QueueDepth != 2 || stats[0].Dropped != 3 {
+		t.Errorf("Expected 2 queued and 3 dropped, got %+v", stats[0])
+	}
+
+	sinks.Start()
+	closeAuditSinks(t, sinks)
+
+	if got := sink.received(); len(got) != 2 {
+		t.Errorf("Expected the 2 queued entries delivered on close, got %d", len(got))
+	}
+
+	// Writes after close still reach the database
+	if err := sinks.Log(context.Background(), domain.NewAuditLog(domain.EventAPIRequest, "API Request").Build()); err != nil {
+		t.Errorf("Expected no error after close, got %v", err)
+	}
+}
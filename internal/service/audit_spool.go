@@ -0,0 +1,429 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+)
+
+// spoolReplayBatchSize is how many spooled entries are written per LogBatch call on replay
+const spoolReplayBatchSize = 500
+
+// AuditSpoolStats reports how many audit entries are waiting in the spool
+type AuditSpoolStats struct {
+	PendingEntries int64 `json:"pending_entries" example:"0"`
+	Bytes          int64 `json:"bytes" example:"0"`
+	Rejected       int64 `json:"rejected" example:"0"`
+}
+
+// AuditSpool is an AuditRepository that writes entries the wrapped repository could not
+// store to an append-only NDJSON file, fsyncing each write, so nothing is lost while the
+// database is down. A background replayer moves spooled entries into the repository once
+// it is reachable again; the repository ignores IDs it already has, so replays are safe.
+//
+// Replay works on a snapshot: the spool file is renamed to <path>.replay and a fresh spool
+// is started, so new failures keep being recorded while the snapshot is replayed. Entries
+// that still fail while others in the same pass succeed are moved to <path>.rejected for
+// manual inspection instead of being retried forever.
+type AuditSpool struct {
+	repository.AuditRepository
+
+	path     string
+	interval time.Duration
+
+	mu       sync.Mutex
+	file     *os.File
+	pending  int64
+	rejected int64
+
+	runMu   sync.Mutex
+	done    chan struct{}
+	running bool
+	wg      sync.WaitGroup
+}
+
+func NewAuditSpool(repo repository.AuditRepository, path string, interval time.Duration) (*AuditSpool, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create audit spool directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit spool: %w", err)
+	}
+
+	s := &AuditSpool{
+		AuditRepository: repo,
+		path:            path,
+		interval:        interval,
+		file:            file,
+	}
+
+	// Entries left over from a previous process count as pending until replayed
+	for _, p := range []string{path, s.replayPath()} {
+		count, err := countLines(p)
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		s.pending += count
+	}
+	rejected, err := countLines(s.rejectedPath())
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	s.rejected = rejected
+
+	return s, nil
+}
+
+// Log writes the entry to the wrapped repository, spooling it if that fails
+func (s *AuditSpool) Log(ctx context.Context, auditLog *domain.AuditLog) error {
+	if err := s.AuditRepository.Log(ctx, auditLog); err != nil {
+		if spoolErr := s.append([]*domain.AuditLog{auditLog}); spoolErr != nil {
+			return errors.Join(err, spoolErr)
+		}
+		log.Printf("Audit entry %s spooled after write failure: %v", auditLog.ID, err)
+	}
+	return nil
+}
+
+// LogBatch writes the entries to the wrapped repository, spooling all of them if that fails
+func (s *AuditSpool) LogBatch(ctx context.Context, auditLogs []*domain.AuditLog) error {
+	if err := s.AuditRepository.LogBatch(ctx, auditLogs); err != nil {
+		if spoolErr := s.append(auditLogs); spoolErr != nil {
+			return errors.Join(err, spoolErr)
+		}
+		log.Printf("%d audit entries spooled after batch write failure: %v", len(auditLogs), err)
+	}
+	return nil
+}
+
+// Stats returns the number of spooled entries and their size on disk
+func (s *AuditSpool) Stats() AuditSpoolStats {
+	stats := AuditSpoolStats{
+		PendingEntries: atomic.LoadInt64(&s.pending),
+		Rejected:       atomic.LoadInt64(&s.rejected),
+	}
+	for _, p := range []string{s.path, s.replayPath()} {
+		if info, err := os.Stat(p); err == nil {
+			stats.Bytes += info.Size()
+		}
+	}
+	return stats
+}
+
+// Start replays the spool in the background until Stop is called
+func (s *AuditSpool) Start() {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	if s.running {
+		return
+	}
+
+	s.done = make(chan struct{})
+	s.running = true
+
+	s.wg.Add(1)
+	go s.run()
+
+	log.Printf("Audit spool replayer started for %s with interval: %v", s.path, s.interval)
+}
+
+// Stop ends replaying and closes the spool file
+func (s *AuditSpool) Stop() {
+	s.runMu.Lock()
+	if s.running {
+		close(s.done)
+		s.running = false
+	}
+	s.runMu.Unlock()
+
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file != nil {
+		if err := s.file.Close(); err != nil {
+			log.Printf("Error closing audit spool: %v", err)
+		}
+		s.file = nil
+	}
+}
+
+func (s *AuditSpool) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if atomic.LoadInt64(&s.pending) == 0 {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+			if err := s.Replay(ctx); err != nil {
+				log.Printf("Audit spool replay incomplete: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// Replay moves spooled entries into the wrapped repository. On error the remaining
+// entries stay spooled for the next attempt.
+func (s *AuditSpool) Replay(ctx context.Context) error {
+	if err := s.snapshot(); err != nil {
+		return err
+	}
+
+	// Pending counts lines, including any torn line that readSpool skips
+	lines, err := countLines(s.replayPath())
+	if err != nil {
+		return err
+	}
+	entries, err := readSpool(s.replayPath())
+	if err != nil {
+		return err
+	}
+
+	var replayed int
+	var rejected []*domain.AuditLog
+	for start := 0; start < len(entries); start += spoolReplayBatchSize {
+		batch := entries[start:min(start+spoolReplayBatchSize, len(entries))]
+
+		if err := s.AuditRepository.LogBatch(ctx, batch); err == nil {
+			replayed += len(batch)
+			continue
+		}
+
+		// Find out whether the database is down or only some entries are bad
+		var failed []*domain.AuditLog
+		var lastErr error
+		for _, entry := range batch {
+			if err := s.AuditRepository.Log(ctx, entry); err != nil {
+				failed = append(failed, entry)
+				lastErr = err
+				continue
+			}
+			replayed++
+		}
+
+		if len(failed) == len(batch) {
+			// Nothing got through: keep the rest for the next attempt
+			remaining := entries[start:]
+			if err := s.reject(rejected); err != nil {
+				return err
+			}
+			if err := rewriteSpool(s.replayPath(), remaining); err != nil {
+				return err
+			}
+			s.markReplayed(lines - int64(len(remaining)))
+			return fmt.Errorf("replayed %d of %d spooled audit entries: %w", replayed, len(entries), lastErr)
+		}
+		rejected = append(rejected, failed...)
+	}
+
+	if err := s.reject(rejected); err != nil {
+		return err
+	}
+	if err := os.Remove(s.replayPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	s.markReplayed(lines)
+
+	if replayed > 0 {
+		log.Printf("Replayed %d spooled audit entries", replayed)
+	}
+	return nil
+}
+
+// reject moves entries the database refused while accepting others to the rejected file
+func (s *AuditSpool) reject(auditLogs []*domain.AuditLog) error {
+	if len(auditLogs) == 0 {
+		return nil
+	}
+	if err := appendSpool(s.rejectedPath(), auditLogs); err != nil {
+		return err
+	}
+	atomic.AddInt64(&s.rejected, int64(len(auditLogs)))
+	log.Printf("Moved %d audit entries the database rejected to %s", len(auditLogs), s.rejectedPath())
+	return nil
+}
+
+func (s *AuditSpool) replayPath() string {
+	return s.path + ".replay"
+}
+
+func (s *AuditSpool) rejectedPath() string {
+	return s.path + ".rejected"
+}
+
+func (s *AuditSpool) markReplayed(count int64) {
+	atomic.AddInt64(&s.pending, -count)
+}
+
+// snapshot moves the current spool aside for replay unless a previous snapshot is still
+// waiting, and starts a fresh spool file
+func (s *AuditSpool) snapshot() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := os.Stat(s.replayPath()); err == nil {
+		return nil
+	}
+
+	info, err := os.Stat(s.path)
+	if err != nil || info.Size() == 0 {
+		return err
+	}
+
+	if s.file != nil {
+		if err := s.file.Close(); err != nil {
+			return fmt.Errorf("failed to close audit spool: %w", err)
+		}
+		s.file = nil
+	}
+
+	if err := os.Rename(s.path, s.replayPath()); err != nil {
+		return fmt.Errorf("failed to snapshot audit spool: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to reopen audit spool: %w", err)
+	}
+	s.file = file
+	return nil
+}
+
+func (s *AuditSpool) append(auditLogs []*domain.AuditLog) error {
+	data, err := encodeSpool(auditLogs)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return errors.New("audit spool is closed")
+	}
+	if _, err := s.file.Write(data); err != nil {
+		return fmt.Errorf("failed to write audit spool: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit spool: %w", err)
+	}
+
+	atomic.AddInt64(&s.pending, int64(len(auditLogs)))
+	return nil
+}
+
+func encodeSpool(auditLogs []*domain.AuditLog) ([]byte, error) {
+	var data []byte
+	for _, auditLog := range auditLogs {
+		line, err := json.Marshal(auditLog)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode audit entry %s: %w", auditLog.ID, err)
+		}
+		data = append(data, line...)
+		data = append(data, '\n')
+	}
+	return data, nil
+}
+
+func appendSpool(path string, auditLogs []*domain.AuditLog) error {
+	data, err := encodeSpool(auditLogs)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// rewriteSpool atomically replaces path with the given entries
+func rewriteSpool(path string, auditLogs []*domain.AuditLog) error {
+	tmp := path + ".tmp"
+	_ = os.Remove(tmp)
+	if err := appendSpool(tmp, auditLogs); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readSpool decodes a spool file, skipping lines that cannot be decoded such as a write
+// torn by a crash
+func readSpool(path string) ([]*domain.AuditLog, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []*domain.AuditLog
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry domain.AuditLog
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			log.Printf("Skipping unreadable audit spool line in %s: %v", path, err)
+			continue
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, scanner.Err()
+}
+
+func countLines(path string) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer file.Close()
+
+	var count int64
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) > 0 {
+			count++
+		}
+	}
+	return count, scanner.Err()
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+)
+
+// flakyAuditRepository fails every write while down is set
+type flakyAuditRepository struct {
+	*repository.MockAuditRepository
+
+	mu   sync.Mutex
+	down bool
+}
+
+func newFlakyAuditRepository() *flakyAuditRepository {
+	return &flakyAuditRepository{MockAuditRepository: repository.NewMockAuditRepository()}
+}
+
+func (r *flakyAuditRepository) setDown(down bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.down = down
+}
+
+func (r *flakyAuditRepository) isDown() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.down
+}
+
+func (r *flakyAuditRepository) Log(ctx context.Context, auditLog *domain.AuditLog) error {
+	if r.isDown() {
+		return errors.New("connection refused")
+	}
+	return r.MockAuditRepository.Log(ctx, auditLog)
+}
+
+func (r *flakyAuditRepository) LogBatch(ctx context.Context, auditLogs []*domain.AuditLog) error {
+	if r.isDown() {
+		return errors.New("connection refused")
+	}
+	return r.MockAuditRepository.LogBatch(ctx, auditLogs)
+}
+
+func storedAuditLogs(t *testing.T, repo repository.AuditRepository) []*domain.AuditLog {
+	t.Helper()
+	logs, err := repo.GetAuditLogs(context.Background(), &domain.AuditLogFilter{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return logs
+}
+
+func TestAuditSpool_SpoolsWhileDownAndReplays(t *testing.T) {
+	repo := newFlakyAuditRepository()
+	path := filepath.Join(t.TempDir(), "audit-spool.ndjson")
+
+	spool, err := NewAuditSpool(repo, path, time.Hour)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer spool.Stop()
+
+	ctx := context.Background()
+	repo.setDown(true)
+
+	entry := domain.NewAuditLog(domain.EventMessageSent, "Message Sent").
+		WithMetadata("phone_number", "+905551111111").
+		Build()
+	if err := spool.Log(ctx, entry); err != nil {
+		t.Fatalf("Expected entry to be spooled, got %v", err)
+	}
+	batch := []*domain.AuditLog{
+		domain.NewAuditLog(domain.EventAPIRequest, "API Request").Build(),
+		domain.NewAuditLog(domain.EventAPIRequest, "API Request").Build(),
+	}
+	if err := spool.LogBatch(ctx, batch); err != nil {
+		t.Fatalf("Expected batch to be spooled, got %v", err)
+	}
+
+	if stats := spool.Stats(); stats.PendingEntries != 3 || stats.Bytes == 0 {
+		t.Errorf("Expected 3 pending entries on disk, got %+v", stats)
+	}
+
+	// Replaying while the database is still down keeps everything spooled
+	if err := spool.Replay(ctx); err == nil {
+		t.Error("Expected replay to fail while the database is down")
+	}
+	if stats := spool.Stats(); stats.PendingEntries != 3 || stats.Rejected != 0 {
+		t.Errorf("Expected 3 pending and no rejected entries, got %+v", stats)
+	}
+
+	repo.setDown(false)
+	if err := spool.Replay(ctx); err != nil {
+		t.Fatalf("Expected replay to succeed, got %v", err)
+	}
+
+	if stats := spool.Stats(); stats.PendingEntries != 0 || stats.Bytes != 0 {
+		t.Errorf("Expected an empty spool after replay, got %+v", stats)
+	}
+
+	logs := storedAuditLogs(t, repo)
+	if len(logs) != 3 {
+		t.Fatalf("Expected 3 replayed entries, got %d", len(logs))
+	}
+	for _, log := range logs {
+		if log.ID == entry.ID {
+			if log.Metadata["phone_number"] != "+905551111111" {
+				t.Errorf("Expected metadata to survive the spool, got %v", log.Metadata)
+			}
+			if !log.CreatedAt.Equal(entry.CreatedAt) {
+				t.Errorf("Expected created_at %v, got %v", entry.CreatedAt, log.CreatedAt)
+			}
+		}
+	}
+}
+
+func TestAuditSpool_ReplaySkipsStoredEntries(t *testing.T) {
+	repo := newFlakyAuditRepository()
+	path := filepath.Join(t.TempDir(), "audit-spool.ndjson")
+
+	spool, err := NewAuditSpool(repo, path, time.Hour)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer spool.Stop()
+
+	ctx := context.Background()
+	entry := domain.NewAuditLog(domain.EventBatchStarted, "Batch Started").Build()
+
+	// The write reached the database but the client saw an error, so it was spooled too
+	if err := repo.MockAuditRepository.Log(ctx, entry); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	repo.setDown(true)
+	if err := spool.Log(ctx, entry); err != nil {
+		t.Fatalf("Expected entry to be spooled, got %v", err)
+	}
+
+	repo.setDown(false)
+	if err := spool.Replay(ctx); err != nil {
+		t.Fatalf("Expected replay to succeed, got %v", err)
+	}
+
+	if logs := storedAuditLogs(t, repo); len(logs) != 1 {
+		t.Errorf("Expected the duplicate to be dropped, got %d entries", len(logs))
+	}
+}
+
+func TestAuditSpool_PendingSurvivesRestart(t *testing.T) {
+	repo := newFlakyAuditRepository()
+	path := filepath.Join(t.TempDir(), "audit-spool.ndjson")
+
+	spool, err := NewAuditSpool(repo, path, time.Hour)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ctx := context.Background()
+	repo.setDown(true)
+	for i := 0; i < 2; i++ {
+		if err := spool.Log(ctx, domain.NewAuditLog(domain.EventAPIRequest, "API Request").Build()); err != nil {
+			t.Fatalf("Expected entry to be spooled, got %v", err)
+		}
+	}
+	spool.Stop()
+
+	reopened, err := NewAuditSpool(repo, path, time.Hour)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer reopened.Stop()
+
+	if stats := reopened.Stats(); stats.PendingEntries != 2 {
+		t.Errorf("Expected 2 pending entries after reopening, got %d", stats.PendingEntries)
+	}
+
+	repo.setDown(false)
+	if err := reopened.Replay(ctx); err != nil {
+		t.Fatalf("Expected replay to succeed, got %v", err)
+	}
+	if logs := storedAuditLogs(t, repo); len(logs) != 2 {
+		t.Errorf("Expected 2 replayed entries, got %d", len(logs))
+	}
+}
+
+func TestAuditSpool_RejectsEntriesTheDatabaseRefuses(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	path := filepath.Join(t.TempDir(), "audit-spool.ndjson")
+
+	good := domain.NewAuditLog(domain.EventAPIRequest, "API Request").Build()
+	bad := domain.NewAuditLog(domain.EventAPIRequest, "API Request").Build()
+
+	auditRepo.LogBatchFunc = func(ctx context.Context, auditLogs []*domain.AuditLog) error {
+		return errors.New("invalid input value for enum")
+	}
+	stored := repository.NewMockAuditRepository()
+	auditRepo.LogFunc = func(ctx context.Context, auditLog *domain.AuditLog) error {
+		if auditLog.ID == bad.ID {
+			return errors.New("invalid input value for enum")
+		}
+		return stored.Log(ctx, auditLog)
+	}
+
+	spool, err := NewAuditSpool(auditRepo, path, time.Hour)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer spool.Stop()
+
+	ctx := context.Background()
+	if err := spool.LogBatch(ctx, []*domain.AuditLog{good, bad}); err != nil {
+		t.Fatalf("Expected batch to be spooled, got %v", err)
+	}
+
+	if err := spool.Replay(ctx); err != nil {
+		t.Fatalf("Expected replay to succeed, got %v", err)
+	}
+
+	stats := spool.Stats()
+	if stats.PendingEntries != 0 || stats.Rejected != 1 {
+		t.Errorf("Expected no pending and 1 rejected entry, got %+v", stats)
+	}
+	if logs := storedAuditLogs(t, stored); len(logs) != 1 || logs[0].ID != good.ID {
+		t.Errorf("Expected only the good entry to be stored, got %v", logs)
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+)
+
+// auditStreamBuffer is how many entries a subscriber may fall behind before it is dropped
+const auditStreamBuffer = 256
+
+// ErrAuditStreamClosed is returned when subscribing to a stopped audit stream
+var ErrAuditStreamClosed = errors.New("audit stream is closed")
+
+// AuditStream pushes the audit logs stored by any replica to live subscribers, each with its
+// own filter. Every entry is read once per replica however many subscribers there are. A
+// subscriber that falls behind is dropped rather than holding up the others; it can resume
+// from the last entry it received.
+type AuditStream struct {
+	auditRepo repository.AuditRepository
+	listener  repository.AuditLogListener
+
+	mu          sync.Mutex
+	subscribers map[*AuditSubscription]struct{}
+	closed      bool
+	wg          sync.WaitGroup
+}
+
+// AuditSubscription receives the live entries matching its filter
+type AuditSubscription struct {
+	filter *domain.AuditLogFilter
+	events chan *domain.AuditLog
+}
+
+// Events delivers the matching entries. It is closed when the subscriber is dropped for
+// falling behind or the stream stops.
+func (s *AuditSubscription) Events() <-chan *domain.AuditLog {
+	return s.events
+}
+
+func NewAuditStream(auditRepo repository.AuditRepository, listener repository.AuditLogListener) *AuditStream {
+	return &AuditStream{
+		auditRepo:   auditRepo,
+		listener:    listener,
+		subscribers: make(map[*AuditSubscription]struct{}),
+	}
+}
+
+// Start begins pushing stored entries to subscribers in the background
+func (s *AuditStream) Start() {
+	s.wg.Add(1)
+	go s.run()
+
+	log.Println("Audit stream started")
+}
+
+// Stop closes the listener and ends every subscription
+func (s *AuditStream) Stop() {
+	if err := s.listener.Close(); err != nil {
+		log.Printf("Error closing audit log listener: %v", err)
+	}
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for subscription := range s.subscribers {
+		delete(s.subscribers, subscription)
+		close(subscription.events)
+	}
+
+	log.Println("Audit stream stopped")
+}
+
+// Subscribe starts receiving the entries matching filter that are stored from now on
+func (s *AuditStream) Subscribe(filter *domain.AuditLogFilter) (*AuditSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, ErrAuditStreamClosed
+	}
+
+	if filter == nil {
+		filter = &domain.AuditLogFilter{}
+	}
+	subscription := &AuditSubscription{
+		filter: filter,
+		events: make(chan *domain.AuditLog, auditStreamBuffer),
+	}
+	s.subscribers[subscription] = struct{}{}
+
+	return subscription, nil
+}
+
+// Unsubscribe stops a subscription; it is safe to call after the subscription was dropped
+func (s *AuditStream) Unsubscribe(subscription *AuditSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscribers[subscription]; ok {
+		delete(s.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+// Subscribers returns how many subscriptions are open
+func (s *AuditStream) Subscribers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers)
+}
+
+func (s *AuditStream) run() {
+	defer s.wg.Done()
+
+	for ref := range s.listener.AuditLogRefs() {
+		s.dispatch(ref)
+	}
+}
+
+func (s *AuditStream) dispatch(ref repository.AuditLogRef) {
+	// Nobody to read it for
+	if s.Subscribers() == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	auditLog, err := s.auditRepo.GetAuditLogByRef(ctx, ref)
+	if err != nil {
+		log.Printf("Error reading streamed audit log %s: %v", ref.ID, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for subscription := range s.subscribers {
+		if !subscription.filter.Matches(auditLog) {
+			continue
+		}
+
+		select {
+		case subscription.events <- auditLog:
+		default:
+			log.Printf("Dropping audit stream subscriber that fell %d entries behind", auditStreamBuffer)
+			delete(s.subscribers, subscription)
+			close(subscription.events)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+)
+
+// storeAndNotify stores an entry and announces it the way the database trigger would
+func storeAndNotify(auditRepo *repository.MockAuditRepository, listener *repository.MockAuditLogListener, auditLog *domain.AuditLog) {
+	auditRepo.AddLog(auditLog)
+	listener.Notify(auditLog)
+}
+
+func receiveAuditLog(t *testing.T, subscription *AuditSubscription) *domain.AuditLog {
+	t.Helper()
+	select {
+	case auditLog, ok := <-subscription.Events():
+		if !ok {
+			t.Fatal("Expected an entry, subscription was closed")
+		}
+		return auditLog
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for an entry")
+		return nil
+	}
+}
+
+func TestAuditStream_DeliversMatchingEntries(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	listener := repository.NewMockAuditLogListener()
+	stream := NewAuditStream(auditRepo, listener)
+	stream.Start()
+	defer stream.Stop()
+
+	all, err := stream.Subscribe(nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	failures, err := stream.Subscribe(&domain.AuditLogFilter{EventTypes: []domain.AuditEventType{domain.EventMessageFailed}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sent := domain.NewAuditLog(domain.EventMessageSent, "Message Sent").Build()
+	failed := domain.NewAuditLog(domain.EventMessageFailed, "Message Failed").Build()
+	storeAndNotify(auditRepo, listener, sent)
+	storeAndNotify(auditRepo, listener, failed)
+
+	if got := receiveAuditLog(t, all); got.ID != sent.ID {
+		t.Errorf("Expected %s first, got %s", sent.ID, got.ID)
+	}
+	if got := receiveAuditLog(t, all); got.ID != failed.ID {
+		t.Errorf("Expected %s second, got %s", failed.ID, got.ID)
+	}
+	if got := receiveAuditLog(t, failures); got.ID != failed.ID {
+		t.Errorf("Expected only the failure, got %s", got.EventType)
+	}
+}
+
+func TestAuditStream_DropsSlowSubscriber(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	listener := repository.NewMockAuditLogListener()
+	stream := NewAuditStream(auditRepo, listener)
+
+	subscription, err := stream.Subscribe(nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Dispatch directly so the listener's buffer does not limit the test
+	for i := 0; i <= auditStreamBuffer; i++ {
+		auditLog := domain.NewAuditLog(domain.EventAPIRequest, "API Request").Build()
+		auditRepo.AddLog(auditLog)
+		stream.dispatch(repository.AuditLogRef{ID: auditLog.ID, CreatedAt: auditLog.CreatedAt})
+	}
+
+	if stream.Subscribers() != 0 {
+		t.Errorf("Expected the slow subscriber to be dropped, %d remain", stream.Subscribers())
+	}
+
+	received := 0
+	for range subscription.Events() {
+		received++
+	}
+	if received != auditStreamBuffer {
+		t.Errorf("Expected %d buffered entries before the close, got %d", auditStreamBuffer, received)
+	}
+
+	// Unsubscribing a dropped subscription is harmless
+	stream.Unsubscribe(subscription)
+}
+
+func TestAuditStream_SkipsUnreadableEntries(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	listener := repository.NewMockAuditLogListener()
+	stream := NewAuditStream(auditRepo, listener)
+	stream.Start()
+	defer stream.Stop()
+
+	subscription, err := stream.Subscribe(nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Announced but not readable, e.g. pruned already
+	listener.Notify(domain.NewAuditLog(domain.EventAPIRequest, "Gone").Build())
+
+	stored := domain.NewAuditLog(domain.EventAPIRequest, "API Request").Build()
+	storeAndNotify(auditRepo, listener, stored)
+
+	if got := receiveAuditLog(t, subscription); got.ID != stored.ID {
+		t.Errorf("Expected %s, got %s", stored.ID, got.ID)
+	}
+}
+
+func TestAuditStream_Stop(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	listener := repository.NewMockAuditLogListener()
+	stream := NewAuditStream(auditRepo, listener)
+	stream.Start()
+
+	subscription, err := stream.Subscribe(nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	stream.Stop()
+
+	if _, ok := <-subscription.Events(); ok {
+		t.Error("Expected the subscription to be closed")
+	}
+	if _, err := stream.Subscribe(nil); !errors.Is(err, ErrAuditStreamClosed) {
+		t.Errorf("Expected ErrAuditStreamClosed, got %v", err)
+	}
+	stream.Unsubscribe(subscription)
+}
+
+func TestAuditStream_SkipsReadWithoutSubscribers(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	reads := 0
+	auditRepo.GetAuditLogByRefFunc = func(ctx context.Context, ref repository.AuditLogRef) (*domain.AuditLog, error) {
+		reads++
+		return nil, domain.ErrMessageNotFound
+	}
+	stream := NewAuditStream(auditRepo, repository.NewMockAuditLogListener())
+
+	auditLog := domain.NewAuditLog(domain.EventAPIRequest, "API Request").Build()
+	stream.dispatch(repository.AuditLogRef{ID: auditLog.ID, CreatedAt: auditLog.CreatedAt})
+
+	if reads != 0 {
+		t.Errorf("Expected no read without subscribers, got %d", reads)
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+)
+
+// Overflow policies for a full audit queue
+const (
+	// AuditOverflowBlock makes writers wait for room, up to the enqueue timeout, before the
+	// entry is dropped
+	AuditOverflowBlock = "block"
+	// AuditOverflowDrop drops the entry immediately
+	AuditOverflowDrop = "drop"
+)
+
+// ErrAuditQueueFull is returned for entries dropped because the audit queue was full
+var ErrAuditQueueFull = errors.New("audit queue is full")
+
+// AuditWriterConfig configures the buffered audit pipeline
+type AuditWriterConfig struct {
+	QueueSize      int
+	FlushSize      int
+	FlushInterval  time.Duration
+	OverflowPolicy string
+	EnqueueTimeout time.Duration
+}
+
+// AuditWriterStats reports the state of the audit queue
+type AuditWriterStats struct {
+	QueueDepth int    `json:"queue_depth" example:"12"`
+	Capacity   int    `json:"capacity" example:"1000"`
+	Written    uint64 `json:"written" example:"5120"`
+	Dropped    uint64 `json:"dropped" example:"0"`
+	Failed     uint64 `json:"failed" example:"0"`
+}
+
+// AuditWriter is an AuditRepository that queues entries in memory and writes them in
+// batches through LogBatch, flushing when a batch is full or the flush interval elapses.
+// Reads go straight to the wrapped repository. Close drains the queue; entries written
+// after Close go straight to the wrapped repository so none are lost on shutdown.
+type AuditWriter struct {
+	repository.AuditRepository
+
+	queue          chan *domain.AuditLog
+	flushSize      int
+	flushInterval  time.Duration
+	overflowPolicy string
+	enqueueTimeout time.Duration
+
+	written uint64
+	dropped uint64
+	failed  uint64
+
+	// mu guards closed; writers hold it for reading while enqueueing so Close cannot
+	// close the queue under them
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func NewAuditWriter(repo repository.AuditRepository, cfg AuditWriterConfig) *AuditWriter {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+	if cfg.FlushSize <= 0 {
+		cfg.FlushSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.OverflowPolicy != AuditOverflowDrop {
+		cfg.OverflowPolicy = AuditOverflowBlock
+	}
+
+	return &AuditWriter{
+		AuditRepository: repo,
+		queue:           make(chan *domain.AuditLog, cfg.QueueSize),
+		flushSize:       cfg.FlushSize,
+		flushInterval:   cfg.FlushInterval,
+		overflowPolicy:  cfg.OverflowPolicy,
+		enqueueTimeout:  cfg.EnqueueTimeout,
+	}
+}
+
+// Start begins flushing queued entries in the background
+func (w *AuditWriter) Start() {
+	w.wg.Add(1)
+	go w.run()
+
+	log.Printf("Audit writer started with queue size: %d, flush size: %d, flush interval: %v, overflow policy: %s",
+		cap(w.queue), w.flushSize, w.flushInterval, w.overflowPolicy)
+}
+
+// Log queues an entry. It returns ErrAuditQueueFull if the entry had to be dropped.
+func (w *AuditWriter) Log(ctx context.Context, auditLog *domain.AuditLog) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return w.AuditRepository.Log(ctx, auditLog)
+	}
+
+	select {
+	case w.queue <- auditLog:
+		return nil
+	default:
+	}
+
+	if w.overflowPolicy == AuditOverflowBlock {
+		timer := time.NewTimer(w.enqueueTimeout)
+		defer timer.Stop()
+
+		select {
+		case w.queue <- auditLog:
+			return nil
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+
+	atomic.AddUint64(&w.dropped, 1)
+	return ErrAuditQueueFull
+}
+
+// LogBatch queues every entry, returning ErrAuditQueueFull if any had to be dropped
+func (w *AuditWriter) LogBatch(ctx context.Context, auditLogs []*domain.AuditLog) error {
+	var dropped int
+	for _, auditLog := range auditLogs {
+		if err := w.Log(ctx, auditLog); err != nil {
+			dropped++
+		}
+	}
+	if dropped > 0 {
+		return fmt.Errorf("%w: dropped %d of %d entries", ErrAuditQueueFull, dropped, len(auditLogs))
+	}
+	return nil
+}
+
+// Close stops accepting queued entries and waits until the queue has been written out or
+// ctx is done
+func (w *AuditWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("Audit writer drained")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("audit writer did not drain: %d entries left: %w", len(w.queue), ctx.Err())
+	}
+}
+
+// Stats returns the current queue depth and lifetime counters
+func (w *AuditWriter) Stats() AuditWriterStats {
+	return AuditWriterStats{
+		QueueDepth: len(w.queue),
+		Capacity:   cap(w.queue),
+		Written:    atomic.LoadUint64(&w.written),
+		Dropped:    atomic.LoadUint64(&w.dropped),
+		Failed:     atomic.LoadUint64(&w.failed),
+	}
+}
+
+func (w *AuditWriter) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*domain.AuditLog, 0, w.flushSize)
+	for {
+		select {
+		case auditLog, ok := <-w.queue:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, auditLog)
+			if len(batch) >= w.flushSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// flush writes a batch in one transaction. If that fails the entries are retried one by
+// one so a single bad entry does not take the rest of the batch with it.
+func (w *AuditWriter) flush(batch []*domain.AuditLog) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err := w.AuditRepository.LogBatch(ctx, batch)
+	if err == nil {
+		atomic.AddUint64(&w.written, uint64(len(batch)))
+		return
+	}
+
+	log.Printf("Failed to write audit batch of %d entries, retrying individually: %v", len(batch), err)
+	for _, auditLog := range batch {
+		if err := w.AuditRepository.Log(ctx, auditLog); err != nil {
+			atomic.AddUint64(&w.failed, 1)
+			log.Printf("AUDIT LOG FAILED (dropped after batch retry): %s - %s (%s): %v",
+				auditLog.EventType, auditLog.EventName, auditLog.ID, err)
+			continue
+		}
+		atomic.AddUint64(&w.written, 1)
+	}
+}
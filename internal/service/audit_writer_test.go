@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"ims/internal/domain"
+	"ims/internal/repository"
+)
+
+func TestAuditWriter_FlushesBySize(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+
+	var mu sync.Mutex
+	var batchSizes []int
+	auditRepo.LogBatchFunc = func(ctx context.Context, auditLogs []*domain.AuditLog) error {
+		mu.Lock()
+		defer mu.Unlock()
+		batchSizes = append(batchSizes, len(auditLogs))
+		return nil
+	}
+
+	writer := NewAuditWriter(auditRepo, AuditWriterConfig{QueueSize: 10, FlushSize: 3, FlushInterval: time.Hour})
+	writer.Start()
+
+	ctx := context.Background()
+	for i := 0; i < 6; i++ {
+		if err := writer.Log(ctx, domain.NewAuditLog(domain.EventAPIRequest, "Test").Build()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if err := writer.Close(ctx); err != nil {
+		t.Fatalf("Expected no error on close, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batchSizes) != 2 || batchSizes[0] != 3 || batchSizes[1] != 3 {
+		t.Errorf("Expected two batches of 3, got %v", batchSizes)
+	}
+
+	if stats := writer.Stats(); stats.Written != 6 {
+		t.Errorf("Expected 6 written entries, got %d", stats.Written)
+	}
+}
+
+func TestAuditWriter_FlushesByInterval(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	writer := NewAuditWriter(auditRepo, AuditWriterConfig{QueueSize: 10, FlushSize: 100, FlushInterval: 10 * time.Millisecond})
+	writer.Start()
+	defer func() { _ = writer.Close(context.Background()) }()
+
+	ctx := context.Background()
+	if err := writer.Log(ctx, domain.NewAuditLog(domain.EventAPIRequest, "Test").Build()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for writer.Stats().Written == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	logs, _ := auditRepo.GetAuditLogs(ctx, nil)
+	if len(logs) != 1 {
+		t.Errorf("Expected 1 entry written after the flush interval, got %d", len(logs))
+	}
+}
+
+func TestAuditWriter_DropsWhenFull(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	// Not started, so nothing drains the queue
+	writer := NewAuditWriter(auditRepo, AuditWriterConfig{QueueSize: 2, OverflowPolicy: AuditOverflowDrop})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := writer.Log(ctx, domain.NewAuditLog(domain.EventAPIRequest, "Test").Build()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	err := writer.Log(ctx, domain.NewAuditLog(domain.EventAPIRequest, "Test").Build())
+	if !errors.Is(err, ErrAuditQueueFull) {
+		t.Errorf("Expected ErrAuditQueueFull, got %v", err)
+	}
+
+	stats := writer.Stats()
+	if stats.Dropped != 1 || stats.QueueDepth != 2 || stats.Capacity != 2 {
+		t.Errorf("Expected 1 dropped and a full queue of 2, got %+v", stats)
+	}
+}
+
+func TestAuditWriter_BlocksUntilTimeout(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	writer := NewAuditWriter(auditRepo, AuditWriterConfig{QueueSize: 1, EnqueueTimeout: 20 * time.Millisecond})
+
+	ctx := context.Background()
+	if err := writer.Log(ctx, domain.NewAuditLog(domain.EventAPIRequest, "Test").Build()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	start := time.Now()
+	err := writer.Log(ctx, domain.NewAuditLog(domain.EventAPIRequest, "Test").Build())
+	if !errors.Is(err, ErrAuditQueueFull) {
+		t.Errorf("Expected ErrAuditQueueFull, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Expected the writer to wait for room, returned after %v", elapsed)
+	}
+}
+
+func TestAuditWriter_RetriesFailedBatchIndividually(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	auditRepo.LogBatchFunc = func(ctx context.Context, auditLogs []*domain.AuditLog) error {
+		return errors.New("batch insert failed")
+	}
+
+	writer := NewAuditWriter(auditRepo, AuditWriterConfig{QueueSize: 10, FlushSize: 10, FlushInterval: time.Hour})
+	writer.Start()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if err := writer.Log(ctx, domain.NewAuditLog(domain.EventAPIRequest, "Test").Build()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if err := writer.Close(ctx); err != nil {
+		t.Fatalf("Expected no error on close, got %v", err)
+	}
+
+	logs, _ := auditRepo.GetAuditLogs(ctx, nil)
+	if len(logs) != 3 {
+		t.Errorf("Expected 3 entries written one by one, got %d", len(logs))
+	}
+}
+
+func TestAuditWriter_WritesDirectlyAfterClose(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	writer := NewAuditWriter(auditRepo, AuditWriterConfig{})
+	writer.Start()
+
+	ctx := context.Background()
+	if err := writer.Close(ctx); err != nil {
+		t.Fatalf("Expected no error on close, got %v", err)
+	}
+
+	if err := writer.Log(ctx, domain.NewAuditLog(domain.EventAPIRequest, "Late").Build()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	logs, _ := auditRepo.GetAuditLogs(ctx, nil)
+	if len(logs) != 1 {
+		t.Errorf("Expected the late entry to be written directly, got %d entries", len(logs))
+	}
+}
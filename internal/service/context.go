@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type contextKey string
+
+const (
+	batchIDContextKey   contextKey = "batch_id"
+	requestIDContextKey contextKey = "request_id"
+)
+
+// WithBatchID returns a context whose audit entries are tagged with batchID
+func WithBatchID(ctx context.Context, batchID uuid.UUID) context.Context {
+	return context.WithValue(ctx, batchIDContextKey, batchID)
+}
+
+// BatchIDFromContext returns the batch ID set by WithBatchID, if any
+func BatchIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	batchID, ok := ctx.Value(batchIDContextKey).(uuid.UUID)
+	return batchID, ok
+}
+
+// WithRequestID returns a context whose audit entries are tagged with the API request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+// RequestIDFromContext returns the request ID set by WithRequestID, if any
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDContextKey).(string)
+	return requestID, ok && requestID != ""
+}
@@ -2,8 +2,14 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"ims/internal/domain"
@@ -12,11 +18,26 @@ import (
 	"github.com/google/uuid"
 )
 
+// phoneNumberPattern accepts E.164-style numbers with an optional leading '+'
+var phoneNumberPattern = regexp.MustCompile(`^\+?[1-9][0-9]{6,14}$`)
+
+// DefaultIdempotencyWindow is how long an idempotency key maps to its original message
+const DefaultIdempotencyWindow = 24 * time.Hour
+
+// DefaultConcurrency is how many messages of a batch are sent in parallel
+const DefaultConcurrency = 4
+
 type MessageService struct {
 	repo      repository.MessageRepository
 	cache     repository.CacheRepository
 	webhook   *WebhookClient
 	maxLength int
+
+	idempotencyWindow time.Duration
+	retryPolicy       RetryPolicy
+	instanceID        string
+	concurrency       int
+	auditService      AuditService
 }
 
 func NewMessageService(
@@ -30,67 +51,213 @@ func NewMessageService(
 		cache:     cache,
 		webhook:   webhook,
 		maxLength: maxLength,
+
+		idempotencyWindow: DefaultIdempotencyWindow,
+		retryPolicy:       DefaultRetryPolicy(),
+		instanceID:        "ims",
+		concurrency:       DefaultConcurrency,
 	}
 }
 
-func (s *MessageService) ProcessMessages(ctx context.Context, batchSize int) error {
-	// Fetch unsent messages
-	messages, err := s.repo.GetUnsentMessages(ctx, batchSize)
+// SetInstanceID sets the identity recorded as the claim owner of messages this
+// instance sends
+func (s *MessageService) SetInstanceID(instanceID string) {
+	if instanceID != "" {
+		s.instanceID = instanceID
+	}
+}
+
+// SetRetryPolicy configures how failed sends are re-queued. A policy with fewer than
+// one attempt keeps the default.
+func (s *MessageService) SetRetryPolicy(policy RetryPolicy) {
+	if policy.MaxAttempts > 0 {
+		s.retryPolicy = policy
+	}
+}
+
+// SetIdempotencyWindow configures how long an idempotency key is honored after the
+// original message was created. Non-positive values keep the default.
+func (s *MessageService) SetIdempotencyWindow(window time.Duration) {
+	if window > 0 {
+		s.idempotencyWindow = window
+	}
+}
+
+// SetConcurrency sets how many messages of a batch are sent in parallel
+func (s *MessageService) SetConcurrency(concurrency int) {
+	if concurrency > 0 {
+		s.concurrency = concurrency
+	}
+}
+
+// SetAuditService records the outcome of every send attempt in the audit log
+func (s *MessageService) SetAuditService(auditService AuditService) {
+	s.auditService = auditService
+}
+
+// ProcessMessages claims up to batchSize due messages, sends them and reports the
+// outcome of each. The returned result has no batch ID; the caller assigns it.
+func (s *MessageService) ProcessMessages(ctx context.Context, batchSize int) (*domain.BatchResult, error) {
+	startedAt := time.Now()
+
+	// Claim due pending messages; they are marked as sending so no other instance picks them up
+	messages, err := s.repo.ClaimMessages(ctx, s.instanceID, batchSize)
 	if err != nil {
-		return fmt.Errorf("failed to get unsent messages: %w", err)
+		return nil, fmt.Errorf("failed to claim messages: %w", err)
 	}
 
 	if len(messages) == 0 {
 		log.Println("No pending messages to process")
-		return nil
+		return domain.NewBatchResult(startedAt, nil), nil
 	}
 
 	log.Printf("Processing %d messages", len(messages))
 
-	// Process each message
-	for _, msg := range messages {
-		if err := s.sendMessage(ctx, msg); err != nil {
-			log.Printf("Failed to send message %s: %v", msg.ID, err)
-			// Continue with other messages even if one fails
-			continue
+	if s.auditService != nil {
+		messageIDs := make([]uuid.UUID, len(messages))
+		for i, msg := range messages {
+			messageIDs[i] = msg.ID
+		}
+		if err := s.auditService.LogMessagesClaimed(context.WithoutCancel(ctx), messageIDs, s.instanceID); err != nil {
+			log.Printf("Failed to log message claimed events: %v", err)
 		}
 	}
 
-	return nil
+	result := domain.NewBatchResult(startedAt, s.sendBatch(ctx, messages))
+	result.DurationMs = time.Since(startedAt).Milliseconds()
+
+	log.Printf("Processed %d messages: %d sent, %d failed, %d skipped as too long, %d released",
+		len(messages), len(result.Succeeded), len(result.Failed), len(result.SkippedTooLong), len(result.Released))
+
+	return result, nil
+}
+
+// sendBatch sends claimed messages with a bounded pool of workers so that one slow
+// recipient does not hold up the rest. Once ctx is done no further messages are handed
+// out; those, and any send cut off by ctx, are released back to pending without using up
+// an attempt and reported as released.
+func (s *MessageService) sendBatch(ctx context.Context, messages []*domain.Message) []domain.MessageSendResult {
+	results := make([]domain.MessageSendResult, len(messages))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(s.concurrency, len(messages)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				msg := messages[i]
+				start := time.Now()
+				err := s.sendMessage(ctx, msg)
+				results[i] = domain.MessageSendResult{
+					MessageID:  msg.ID,
+					Outcome:    domain.OutcomeSent,
+					DurationMs: time.Since(start).Milliseconds(),
+				}
+				switch {
+				case s.isTooLong(msg):
+					results[i].Outcome = domain.OutcomeSkippedTooLong
+					results[i].Error = domain.ErrMessageTooLong.Error()
+				case isCancellation(ctx, err):
+					results[i].Outcome = domain.OutcomeReleased
+					results[i].Error = err.Error()
+				case err != nil:
+					log.Printf("Failed to send message %s: %v", msg.ID, err)
+					results[i].Outcome = domain.OutcomeFailed
+					results[i].Error = err.Error()
+				}
+			}
+		}()
+	}
+
+	for i := range messages {
+		// select picks at random when both cases are ready, so check ctx first
+		if ctx.Err() == nil {
+			select {
+			case jobs <- i:
+				continue
+			case <-ctx.Done():
+			}
+		}
+		for j, rest := range messages[i:] {
+			results[i+j] = domain.MessageSendResult{
+				MessageID: rest.ID,
+				Outcome:   domain.OutcomeReleased,
+				Error:     ctx.Err().Error(),
+			}
+		}
+		break
+	}
+	close(jobs)
+	wg.Wait()
+
+	var unsent []uuid.UUID
+	for _, result := range results {
+		if result.Outcome == domain.OutcomeReleased {
+			unsent = append(unsent, result.MessageID)
+		}
+	}
+
+	if len(unsent) > 0 {
+		// ctx is already done, so release with a fresh deadline rather than leaving the
+		// messages in sending until the reaper's lease expires
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		if err := s.repo.ReleaseClaims(releaseCtx, s.instanceID, unsent); err != nil {
+			log.Printf("Failed to release %d unsent messages: %v", len(unsent), err)
+		} else {
+			log.Printf("Released %d unsent messages after batch deadline", len(unsent))
+		}
+	}
+
+	return results
 }
 
 func (s *MessageService) sendMessage(ctx context.Context, msg *domain.Message) error {
 	// Validate message content length
-	if len(msg.Content) > s.maxLength {
+	if s.isTooLong(msg) {
 		log.Printf("Message %s exceeds maximum length (%d > %d)", msg.ID, len(msg.Content), s.maxLength)
+		s.logMessageFailed(ctx, msg.ID, 0, domain.ErrMessageTooLong)
 		return s.repo.UpdateMessageStatus(ctx, msg.ID, domain.StatusFailed, nil)
 	}
 
-	// Update status to sending
-	if err := s.repo.UpdateMessageStatus(ctx, msg.ID, domain.StatusSending, nil); err != nil {
-		return fmt.Errorf("failed to update message status to sending: %w", err)
-	}
-
 	log.Printf("Sending message %s to %s", msg.ID, msg.PhoneNumber)
 
 	// Send via webhook
-	resp, err := s.webhook.Send(ctx, msg.PhoneNumber, msg.Content)
+	start := time.Now()
+	resp, err := s.webhook.SendForMessage(ctx, msg.ID, msg.PhoneNumber, msg.Content)
+	duration := time.Since(start)
 	if err != nil {
 		log.Printf("Failed to send webhook for message %s: %v", msg.ID, err)
-		// Update status to failed
-		if updateErr := s.repo.UpdateMessageStatus(ctx, msg.ID, domain.StatusFailed, nil); updateErr != nil {
-			log.Printf("Failed to update message status to failed: %v", updateErr)
+		// The batch deadline cut the send off; the caller releases the message
+		if isCancellation(ctx, err) {
+			return err
+		}
+		err = s.handleSendFailure(ctx, msg, err)
+		// A re-queued attempt is already recorded by its webhook entries
+		if errors.Is(err, domain.ErrMaxRetriesExceeded) {
+			s.logMessageFailed(ctx, msg.ID, duration, err)
 		}
 		return err
 	}
 
 	log.Printf("Message %s sent successfully, webhook response ID: %s", msg.ID, resp.MessageID)
 
+	// The provider has accepted the message, so record it even if the batch deadline passes
+	// now; releasing it back to pending would send it a second time
+	ctx = context.WithoutCancel(ctx)
+
 	// Update status to sent
 	if err := s.repo.UpdateMessageStatus(ctx, msg.ID, domain.StatusSent, &resp.MessageID); err != nil {
 		return fmt.Errorf("failed to update message status to sent: %w", err)
 	}
 
+	if s.auditService != nil {
+		if err := s.auditService.LogMessageSent(ctx, msg.ID, duration, s.webhook.URL()); err != nil {
+			log.Printf("Failed to log message sent event: %v", err)
+		}
+	}
+
 	// Cache message data (bonus)
 	if s.cache != nil {
 		cacheData := map[string]interface{}{
@@ -109,6 +276,58 @@ func (s *MessageService) sendMessage(ctx context.Context, msg *domain.Message) e
 	return nil
 }
 
+// logMessageFailed records that a message has failed for good; the entry is written even
+// if ctx has expired
+func (s *MessageService) logMessageFailed(ctx context.Context, messageID uuid.UUID, duration time.Duration, sendErr error) {
+	if s.auditService == nil {
+		return
+	}
+	if err := s.auditService.LogMessageFailed(context.WithoutCancel(ctx), messageID, duration, s.webhook.URL(), sendErr); err != nil {
+		log.Printf("Failed to log message failed event: %v", err)
+	}
+}
+
+func (s *MessageService) isTooLong(msg *domain.Message) bool {
+	return len(msg.Content) > s.maxLength
+}
+
+// isCancellation reports whether err came from ctx being done rather than from the send
+func isCancellation(ctx context.Context, err error) bool {
+	return err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err())
+}
+
+// handleSendFailure re-queues the message with backoff, or marks it failed for good once
+// the retry policy's attempt limit is reached
+func (s *MessageService) handleSendFailure(ctx context.Context, msg *domain.Message, sendErr error) error {
+	attempts := msg.RetryCount + 1
+
+	if attempts >= s.retryPolicy.MaxAttempts {
+		if updateErr := s.repo.RecordFailedAttempt(ctx, msg.ID, sendErr.Error(), nil); updateErr != nil {
+			log.Printf("Failed to update message status to failed: %v", updateErr)
+		}
+		return fmt.Errorf("%w after %d attempts: %v", domain.ErrMaxRetriesExceeded, attempts, sendErr)
+	}
+
+	nextAttemptAt := time.Now().Add(s.retryPolicy.Backoff(attempts))
+	if updateErr := s.repo.RecordFailedAttempt(ctx, msg.ID, sendErr.Error(), &nextAttemptAt); updateErr != nil {
+		log.Printf("Failed to re-queue message %s: %v", msg.ID, updateErr)
+	} else {
+		log.Printf("Message %s re-queued after attempt %d/%d, next attempt at %s",
+			msg.ID, attempts, s.retryPolicy.MaxAttempts, nextAttemptAt.Format(time.RFC3339))
+	}
+	return sendErr
+}
+
+// RecoverStuckMessages returns messages that have been in the sending state for longer
+// than lease back to pending so a later batch can pick them up again
+func (s *MessageService) RecoverStuckMessages(ctx context.Context, lease time.Duration) ([]*domain.Message, error) {
+	messages, err := s.repo.RecoverStuckMessages(ctx, time.Now().Add(-lease))
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover stuck messages: %w", err)
+	}
+	return messages, nil
+}
+
 func (s *MessageService) GetSentMessages(ctx context.Context, page, pageSize int) ([]*domain.Message, error) {
 	if page < 1 {
 		page = 1
@@ -121,9 +340,100 @@ func (s *MessageService) GetSentMessages(ctx context.Context, page, pageSize int
 	return s.repo.GetSentMessages(ctx, offset, pageSize)
 }
 
+// GetSentMessagesPage returns a page of sent messages, newest first, and a cursor for the
+// next page, or nil when this page is the last one. A cursor continues after the message it
+// marks and takes precedence over page.
+func (s *MessageService) GetSentMessagesPage(ctx context.Context, cursor *domain.PageCursor, page, pageSize int) ([]*domain.Message, *domain.PageCursor, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	// Ask for one extra message to learn whether another page follows
+	var messages []*domain.Message
+	var err error
+	if cursor != nil {
+		messages, err = s.repo.GetSentMessagesAfter(ctx, cursor, pageSize+1)
+	} else {
+		messages, err = s.repo.GetSentMessages(ctx, (page-1)*pageSize, pageSize+1)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(messages) <= pageSize {
+		return messages, nil, nil
+	}
+
+	messages = messages[:pageSize]
+	last := messages[len(messages)-1]
+	if last.SentAt == nil {
+		return messages, nil, nil
+	}
+	return messages, domain.NewPageCursor(*last.SentAt, last.ID), nil
+}
+
+// GetMessageDetails returns a message with its lifecycle timeline and, once it was sent and
+// while it is cached, the provider's webhook response. A failing audit or cache lookup is
+// logged and leaves that part out rather than failing the call.
+func (s *MessageService) GetMessageDetails(ctx context.Context, id uuid.UUID) (*domain.MessageDetails, error) {
+	msg, err := s.repo.GetMessage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var auditLogs []*domain.AuditLog
+	if s.auditService != nil {
+		auditLogs, err = s.auditService.GetMessageAuditLogs(ctx, id.String())
+		if err != nil {
+			log.Printf("Failed to get audit logs for message %s: %v", id, err)
+		}
+	}
+
+	details := &domain.MessageDetails{
+		Message:  msg,
+		Timeline: domain.BuildMessageTimeline(msg, auditLogs),
+	}
+
+	// Sent messages are cached under the provider's message ID
+	if s.cache != nil && msg.MessageID != nil {
+		providerResponse, err := s.cache.GetMessageCache(ctx, *msg.MessageID)
+		if err != nil && !errors.Is(err, domain.ErrMessageNotFound) {
+			log.Printf("Failed to get cached provider response for message %s: %v", id, err)
+		} else {
+			details.ProviderResponse = providerResponse
+		}
+	}
+
+	return details, nil
+}
+
 func (s *MessageService) CreateMessage(ctx context.Context, phoneNumber, content string) (*domain.Message, error) {
-	if len(content) > s.maxLength {
-		return nil, domain.ErrMessageTooLong
+	msg, _, err := s.CreateMessageWithKey(ctx, "", phoneNumber, content)
+	return msg, err
+}
+
+// CreateMessageWithKey creates a message guarded by an optional idempotency key. A repeated
+// request with the same key and body inside the idempotency window returns the original
+// message and true instead of inserting again; the same key with a different body yields
+// ErrIdempotencyKeyConflict.
+func (s *MessageService) CreateMessageWithKey(ctx context.Context, idempotencyKey, phoneNumber, content string) (*domain.Message, bool, error) {
+	phoneNumber = strings.TrimSpace(phoneNumber)
+	if err := s.validateMessage(phoneNumber, content); err != nil {
+		return nil, false, err
+	}
+
+	idempotencyKey = strings.TrimSpace(idempotencyKey)
+	requestHash := ""
+	if idempotencyKey != "" {
+		requestHash = hashMessageRequest(phoneNumber, content)
+
+		existing, err := s.findIdempotentMessage(ctx, idempotencyKey, requestHash)
+		if err != nil || existing != nil {
+			return existing, existing != nil, err
+		}
 	}
 
 	msg := &domain.Message{
@@ -135,10 +445,132 @@ func (s *MessageService) CreateMessage(ctx context.Context, phoneNumber, content
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
+	if idempotencyKey != "" {
+		msg.IdempotencyKey = &idempotencyKey
+		msg.IdempotencyHash = requestHash
+	}
 
 	if err := s.repo.CreateMessage(ctx, msg); err != nil {
-		return nil, fmt.Errorf("failed to create message: %w", err)
+		if errors.Is(err, domain.ErrDuplicateIdempotencyKey) {
+			// A concurrent request with the same key won the insert
+			existing, findErr := s.findIdempotentMessage(ctx, idempotencyKey, requestHash)
+			if findErr != nil || existing != nil {
+				return existing, existing != nil, findErr
+			}
+		}
+		return nil, false, fmt.Errorf("failed to create message: %w", err)
+	}
+	s.logMessagesCreated(ctx, []*domain.Message{msg})
+
+	return msg, false, nil
+}
+
+// findIdempotentMessage returns the message already created with the given key, or nil if
+// the key is unused. Keys older than the idempotency window are released for reuse.
+func (s *MessageService) findIdempotentMessage(ctx context.Context, key, requestHash string) (*domain.Message, error) {
+	existing, err := s.repo.GetMessageByIdempotencyKey(ctx, key)
+	if err != nil {
+		if errors.Is(err, domain.ErrMessageNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to look up idempotency key: %w", err)
+	}
+
+	windowStart := time.Now().Add(-s.idempotencyWindow)
+	if existing.CreatedAt.Before(windowStart) {
+		if err := s.repo.ReleaseIdempotencyKey(ctx, key, windowStart); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	if existing.IdempotencyHash != requestHash {
+		return nil, domain.ErrIdempotencyKeyConflict
+	}
+
+	return existing, nil
+}
+
+func hashMessageRequest(phoneNumber, content string) string {
+	sum := sha256.Sum256([]byte(phoneNumber + "\x00" + content))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateMessages validates and enqueues a batch of messages, returning one result per input.
+// Unless partial is set, a single invalid item rejects the whole batch with ErrInvalidBatch
+// and nothing is persisted, the valid items being reported as skipped; in partial mode the
+// valid items are committed and the invalid ones are reported back.
+func (s *MessageService) CreateMessages(ctx context.Context, inputs []domain.MessageInput, partial bool) ([]domain.BulkMessageResult, error) {
+	results := make([]domain.BulkMessageResult, len(inputs))
+	messages := make([]*domain.Message, 0, len(inputs))
+	// indexes maps each entry in messages back to its position in inputs
+	indexes := make([]int, 0, len(inputs))
+	invalid := 0
+	now := time.Now()
+
+	for i, input := range inputs {
+		results[i].Index = i
+		phoneNumber := strings.TrimSpace(input.PhoneNumber)
+		if err := s.validateMessage(phoneNumber, input.Content); err != nil {
+			results[i].Status = domain.BulkItemInvalid
+			results[i].Error = err.Error()
+			invalid++
+			continue
+		}
+
+		messages = append(messages, &domain.Message{
+			ID:          uuid.New(),
+			PhoneNumber: phoneNumber,
+			Content:     input.Content,
+			Status:      domain.StatusPending,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		indexes = append(indexes, i)
 	}
 
-	return msg, nil
+	if invalid > 0 && !partial {
+		for _, i := range indexes {
+			results[i].Status = domain.BulkItemSkipped
+		}
+		return results, domain.ErrInvalidBatch
+	}
+
+	if err := s.repo.CreateMessages(ctx, messages); err != nil {
+		return nil, fmt.Errorf("failed to create messages: %w", err)
+	}
+
+	for j, msg := range messages {
+		id := msg.ID
+		results[indexes[j]].Status = domain.BulkItemCreated
+		results[indexes[j]].ID = &id
+	}
+	s.logMessagesCreated(ctx, messages)
+
+	return results, nil
+}
+
+// logMessagesCreated records that the messages were enqueued; the entries are written even
+// if ctx has expired
+func (s *MessageService) logMessagesCreated(ctx context.Context, messages []*domain.Message) {
+	if s.auditService == nil {
+		return
+	}
+	if err := s.auditService.LogMessagesCreated(context.WithoutCancel(ctx), messages); err != nil {
+		log.Printf("Failed to log message created events: %v", err)
+	}
+}
+
+// validateMessage checks the phone number format and content length of a new message
+func (s *MessageService) validateMessage(phoneNumber, content string) error {
+	if !phoneNumberPattern.MatchString(phoneNumber) {
+		return domain.ErrInvalidPhoneNumber
+	}
+	if strings.TrimSpace(content) == "" {
+		return domain.ErrEmptyContent
+	}
+	if len(content) > s.maxLength {
+		return domain.ErrMessageTooLong
+	}
+	return nil
 }
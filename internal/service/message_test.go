@@ -2,9 +2,15 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"ims/internal/domain"
 	"ims/internal/repository"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -100,6 +106,42 @@ func TestMessageService_CreateMessage_TooLong(t *testing.T) {
 	}
 }
 
+func TestMessageService_CreateMessage_ValidationErrors(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient("http://example.com", "test-key", 30*time.Second, 3)
+	service := NewMessageService(repo, cache, webhook, 1000)
+
+	tests := []struct {
+		name        string
+		phoneNumber string
+		content     string
+		expectedErr error
+	}{
+		{"Empty phone number", "", "Test message", domain.ErrInvalidPhoneNumber},
+		{"Letters in phone number", "+12345abc90", "Test message", domain.ErrInvalidPhoneNumber},
+		{"Phone number too short", "+12345", "Test message", domain.ErrInvalidPhoneNumber},
+		{"Phone number too long", "+1234567890123456", "Test message", domain.ErrInvalidPhoneNumber},
+		{"Empty content", "+1234567890", "", domain.ErrEmptyContent},
+		{"Whitespace content", "+1234567890", "   ", domain.ErrEmptyContent},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.CreateMessage(ctx, tt.phoneNumber, tt.content)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+
+	// Verify no message was created in repository
+	if repo.Count() != 0 {
+		t.Errorf("Expected 0 messages in repository, got %d", repo.Count())
+	}
+}
+
 func TestMessageService_CreateMessage_RepositoryError(t *testing.T) {
 	repo := repository.NewMockMessageRepository()
 	cache := repository.NewMockCacheRepository()
@@ -124,6 +166,291 @@ func TestMessageService_CreateMessage_RepositoryError(t *testing.T) {
 	}
 }
 
+func TestMessageService_CreateMessageWithKey_Replay(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient("http://example.com", "test-key", 30*time.Second, 3)
+	service := NewMessageService(repo, cache, webhook, 1000)
+
+	ctx := context.Background()
+	first, replayed, err := service.CreateMessageWithKey(ctx, "key-1", "+1234567890", "Test message")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if replayed {
+		t.Error("Expected first request not to be a replay")
+	}
+
+	second, replayed, err := service.CreateMessageWithKey(ctx, "key-1", "+1234567890", "Test message")
+	if err != nil {
+		t.Fatalf("Expected no error on retry, got %v", err)
+	}
+	if !replayed {
+		t.Error("Expected retry to be a replay")
+	}
+	if second.ID != first.ID {
+		t.Errorf("Expected original message %s, got %s", first.ID, second.ID)
+	}
+
+	if repo.Count() != 1 {
+		t.Errorf("Expected 1 message in repository, got %d", repo.Count())
+	}
+}
+
+func TestMessageService_CreateMessageWithKey_Conflict(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient("http://example.com", "test-key", 30*time.Second, 3)
+	service := NewMessageService(repo, cache, webhook, 1000)
+
+	ctx := context.Background()
+	if _, _, err := service.CreateMessageWithKey(ctx, "key-1", "+1234567890", "Test message"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	_, _, err := service.CreateMessageWithKey(ctx, "key-1", "+1234567890", "Different message")
+	if !errors.Is(err, domain.ErrIdempotencyKeyConflict) {
+		t.Errorf("Expected ErrIdempotencyKeyConflict, got %v", err)
+	}
+
+	if repo.Count() != 1 {
+		t.Errorf("Expected 1 message in repository, got %d", repo.Count())
+	}
+}
+
+func TestMessageService_CreateMessageWithKey_ExpiredWindow(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient("http://example.com", "test-key", 30*time.Second, 3)
+	service := NewMessageService(repo, cache, webhook, 1000)
+	service.SetIdempotencyWindow(time.Hour)
+
+	key := "key-1"
+	old := &domain.Message{
+		ID:             uuid.New(),
+		PhoneNumber:    "+1234567890",
+		Content:        "Old message",
+		Status:         domain.StatusSent,
+		CreatedAt:      time.Now().Add(-2 * time.Hour),
+		UpdatedAt:      time.Now().Add(-2 * time.Hour),
+		IdempotencyKey: &key,
+	}
+	repo.AddMessage(old)
+
+	msg, replayed, err := service.CreateMessageWithKey(context.Background(), key, "+1234567890", "New message")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if replayed || msg.ID == old.ID {
+		t.Error("Expected a new message once the idempotency window expired")
+	}
+
+	if old.IdempotencyKey != nil {
+		t.Error("Expected expired key to be released from the old message")
+	}
+
+	if repo.Count() != 2 {
+		t.Errorf("Expected 2 messages in repository, got %d", repo.Count())
+	}
+}
+
+func TestMessageService_CreateMessages_AllValid(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient("http://example.com", "test-key", 30*time.Second, 3)
+	service := NewMessageService(repo, cache, webhook, 1000)
+
+	inputs := []domain.MessageInput{
+		{PhoneNumber: "+1234567890", Content: "First"},
+		{PhoneNumber: "+1234567891", Content: "Second"},
+	}
+
+	results, err := service.CreateMessages(context.Background(), inputs, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(results) != len(inputs) {
+		t.Fatalf("Expected %d results, got %d", len(inputs), len(results))
+	}
+
+	for i, result := range results {
+		if result.Index != i {
+			t.Errorf("Expected index %d, got %d", i, result.Index)
+		}
+		if result.ID == nil || result.Error != "" {
+			t.Errorf("Expected item %d to be created, got %+v", i, result)
+		}
+	}
+
+	if repo.Count() != 2 {
+		t.Errorf("Expected 2 messages in repository, got %d", repo.Count())
+	}
+}
+
+func TestMessageService_CreateMessages_InvalidRejectsBatch(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient("http://example.com", "test-key", 30*time.Second, 3)
+	service := NewMessageService(repo, cache, webhook, 10)
+
+	inputs := []domain.MessageInput{
+		{PhoneNumber: "+1234567890", Content: "Valid"},
+		{PhoneNumber: "not-a-number", Content: "Valid"},
+		{PhoneNumber: "+1234567890", Content: "This message is way too long"},
+	}
+
+	results, err := service.CreateMessages(context.Background(), inputs, false)
+	if !errors.Is(err, domain.ErrInvalidBatch) {
+		t.Fatalf("Expected ErrInvalidBatch, got %v", err)
+	}
+
+	if results[1].Error != domain.ErrInvalidPhoneNumber.Error() {
+		t.Errorf("Expected phone number error for item 1, got %q", results[1].Error)
+	}
+
+	if results[2].Error != domain.ErrMessageTooLong.Error() {
+		t.Errorf("Expected too long error for item 2, got %q", results[2].Error)
+	}
+
+	if results[0].ID != nil || results[0].Status != domain.BulkItemSkipped {
+		t.Errorf("Expected valid item to be skipped when the batch is rejected, got %+v", results[0])
+	}
+
+	if results[1].Status != domain.BulkItemInvalid || results[2].Status != domain.BulkItemInvalid {
+		t.Errorf("Expected items 1 and 2 to be invalid, got %s and %s", results[1].Status, results[2].Status)
+	}
+
+	if repo.Count() != 0 {
+		t.Errorf("Expected 0 messages in repository, got %d", repo.Count())
+	}
+}
+
+func TestMessageService_CreateMessages_Partial(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient("http://example.com", "test-key", 30*time.Second, 3)
+	service := NewMessageService(repo, cache, webhook, 1000)
+
+	inputs := []domain.MessageInput{
+		{PhoneNumber: "bad", Content: "Invalid"},
+		{PhoneNumber: "+1234567890", Content: "Valid"},
+	}
+
+	results, err := service.CreateMessages(context.Background(), inputs, true)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if results[0].ID != nil || results[0].Error == "" || results[0].Status != domain.BulkItemInvalid {
+		t.Errorf("Expected item 0 to fail, got %+v", results[0])
+	}
+
+	if results[1].ID == nil || results[1].Status != domain.BulkItemCreated {
+		t.Fatalf("Expected item 1 to be created, got %+v", results[1])
+	}
+
+	if _, err := repo.GetMessage(context.Background(), *results[1].ID); err != nil {
+		t.Errorf("Expected created message to be stored, got %v", err)
+	}
+}
+
+func TestMessageService_CreateMessages_RepositoryError(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient("http://example.com", "test-key", 30*time.Second, 3)
+	service := NewMessageService(repo, cache, webhook, 1000)
+
+	expectedError := errors.New("database error")
+	repo.CreateMessagesFunc = func(ctx context.Context, messages []*domain.Message) error {
+		return expectedError
+	}
+
+	inputs := []domain.MessageInput{{PhoneNumber: "+1234567890", Content: "Valid"}}
+	_, err := service.CreateMessages(context.Background(), inputs, false)
+
+	if !errors.Is(err, expectedError) {
+		t.Errorf("Expected wrapped error containing database error, got %v", err)
+	}
+}
+
+// recordAuditBatches makes repo keep the size of every LogBatch call per event type
+func recordAuditBatches(repo *repository.MockAuditRepository) func() map[domain.AuditEventType][]int {
+	var mu sync.Mutex
+	batches := make(map[domain.AuditEventType][]int)
+	repo.LogBatchFunc = func(ctx context.Context, auditLogs []*domain.AuditLog) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(auditLogs) > 0 {
+			batches[auditLogs[0].EventType] = append(batches[auditLogs[0].EventType], len(auditLogs))
+		}
+		return nil
+	}
+	return func() map[domain.AuditEventType][]int {
+		mu.Lock()
+		defer mu.Unlock()
+		return batches
+	}
+}
+
+func TestMessageService_CreateMessages_AuditsInOneBatch(t *testing.T) {
+	auditRepo := repository.NewMockAuditRepository()
+	batches := recordAuditBatches(auditRepo)
+
+	repo := repository.NewMockMessageRepository()
+	webhook := NewWebhookClient("http://example.com", "test-key", 30*time.Second, 3)
+	service := NewMessageService(repo, repository.NewMockCacheRepository(), webhook, 1000)
+	service.SetAuditService(NewAuditService(auditRepo))
+
+	inputs := []domain.MessageInput{
+		{PhoneNumber: "+1234567890", Content: "First"},
+		{PhoneNumber: "+1234567891", Content: "Second"},
+		{PhoneNumber: "+1234567892", Content: "Third"},
+	}
+	if _, err := service.CreateMessages(context.Background(), inputs, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got := batches()[domain.EventMessageCreated]; len(got) != 1 || got[0] != 3 {
+		t.Errorf("Expected one batch of 3 message created entries, got %v", got)
+	}
+}
+
+func TestMessageService_ProcessMessages_AuditsClaimsInOneBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"message": "Accepted", "messageId": "msg-1"}`))
+	}))
+	defer server.Close()
+
+	auditRepo := repository.NewMockAuditRepository()
+	batches := recordAuditBatches(auditRepo)
+
+	repo := repository.NewMockMessageRepository()
+	webhook := NewWebhookClient(server.URL, "test-key", 5*time.Second, 0)
+	service := NewMessageService(repo, repository.NewMockCacheRepository(), webhook, 1000)
+	service.SetAuditService(NewAuditService(auditRepo))
+
+	for i := 0; i < 3; i++ {
+		repo.AddMessage(&domain.Message{
+			ID:          uuid.New(),
+			PhoneNumber: "+1234567890",
+			Content:     "Test message",
+			Status:      domain.StatusPending,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		})
+	}
+
+	if _, err := service.ProcessMessages(context.Background(), 10); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got := batches()[domain.EventMessageClaimed]; len(got) != 1 || got[0] != 3 {
+		t.Errorf("Expected one batch of 3 message claimed entries, got %v", got)
+	}
+}
+
 func TestMessageService_ProcessMessages_NoMessages(t *testing.T) {
 	repo := repository.NewMockMessageRepository()
 	cache := repository.NewMockCacheRepository()
@@ -131,11 +458,15 @@ func TestMessageService_ProcessMessages_NoMessages(t *testing.T) {
 	service := NewMessageService(repo, cache, webhook, 1000)
 
 	ctx := context.Background()
-	err := service.ProcessMessages(ctx, 10)
+	result, err := service.ProcessMessages(ctx, 10)
 
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
+
+	if result == nil || len(result.Attempted) != 0 {
+		t.Errorf("Expected an empty batch result, got %+v", result)
+	}
 }
 
 func TestMessageService_ProcessMessages_RepositoryError(t *testing.T) {
@@ -146,12 +477,12 @@ func TestMessageService_ProcessMessages_RepositoryError(t *testing.T) {
 
 	// Configure repository to return error
 	expectedError := errors.New("database error")
-	repo.GetUnsentMessagesFunc = func(ctx context.Context, limit int) ([]*domain.Message, error) {
+	repo.ClaimMessagesFunc = func(ctx context.Context, owner string, limit int) ([]*domain.Message, error) {
 		return nil, expectedError
 	}
 
 	ctx := context.Background()
-	err := service.ProcessMessages(ctx, 10)
+	_, err := service.ProcessMessages(ctx, 10)
 
 	if err == nil {
 		t.Fatal("Expected an error, got nil")
@@ -162,6 +493,478 @@ func TestMessageService_ProcessMessages_RepositoryError(t *testing.T) {
 	}
 }
 
+func TestMessageService_ProcessMessages_ClaimsWithInstanceID(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient("http://example.com", "test-key", 30*time.Second, 3)
+	service := NewMessageService(repo, cache, webhook, 1000)
+	service.SetInstanceID("replica-1")
+
+	var claimOwner string
+	var claimLimit int
+	repo.ClaimMessagesFunc = func(ctx context.Context, owner string, limit int) ([]*domain.Message, error) {
+		claimOwner = owner
+		claimLimit = limit
+		return nil, nil
+	}
+
+	if _, err := service.ProcessMessages(context.Background(), 7); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if claimOwner != "replica-1" {
+		t.Errorf("Expected claim owner replica-1, got %s", claimOwner)
+	}
+
+	if claimLimit != 7 {
+		t.Errorf("Expected claim limit 7, got %d", claimLimit)
+	}
+}
+
+func TestMessageService_ProcessMessages_SendsConcurrently(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"message": "Accepted", "messageId": "msg-1"}`))
+	}))
+	defer server.Close()
+
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient(server.URL, "test-key", 5*time.Second, 0)
+	service := NewMessageService(repo, cache, webhook, 1000)
+	service.SetConcurrency(3)
+
+	for i := 0; i < 6; i++ {
+		repo.AddMessage(&domain.Message{
+			ID:          uuid.New(),
+			PhoneNumber: "+1234567890",
+			Content:     "Test message",
+			Status:      domain.StatusPending,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		})
+	}
+
+	result, err := service.ProcessMessages(context.Background(), 6)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(result.Succeeded) != 6 {
+		t.Errorf("Expected 6 succeeded messages in result, got %d", len(result.Succeeded))
+	}
+
+	if got := atomic.LoadInt32(&maxInFlight); got != 3 {
+		t.Errorf("Expected 3 concurrent sends, got %d", got)
+	}
+
+	sent, _ := repo.GetSentMessages(context.Background(), 0, 10)
+	if len(sent) != 6 {
+		t.Errorf("Expected 6 sent messages, got %d", len(sent))
+	}
+}
+
+func TestMessageService_ProcessMessages_ReportsOutcomes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req domain.WebhookRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.To == "+1999999999" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"message": "Accepted", "messageId": "msg-1"}`))
+	}))
+	defer server.Close()
+
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient(server.URL, "test-key", 5*time.Second, 0)
+	service := NewMessageService(repo, cache, webhook, 20)
+
+	newMessage := func(phoneNumber, content string) *domain.Message {
+		msg := &domain.Message{
+			ID:          uuid.New(),
+			PhoneNumber: phoneNumber,
+			Content:     content,
+			Status:      domain.StatusPending,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+		repo.AddMessage(msg)
+		return msg
+	}
+	sent := newMessage("+1234567890", "Hello")
+	failed := newMessage("+1999999999", "Hello")
+	tooLong := newMessage("+1234567890", "This message is way too long for the limit")
+
+	result, err := service.ProcessMessages(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(result.Attempted) != 2 {
+		t.Errorf("Expected 2 attempted messages, got %d", len(result.Attempted))
+	}
+	if len(result.Succeeded) != 1 || result.Succeeded[0] != sent.ID {
+		t.Errorf("Expected succeeded to be [%s], got %v", sent.ID, result.Succeeded)
+	}
+	if len(result.Failed) != 1 || result.Failed[0] != failed.ID {
+		t.Errorf("Expected failed to be [%s], got %v", failed.ID, result.Failed)
+	}
+	if len(result.SkippedTooLong) != 1 || result.SkippedTooLong[0] != tooLong.ID {
+		t.Errorf("Expected skipped_too_long to be [%s], got %v", tooLong.ID, result.SkippedTooLong)
+	}
+	if result.SuccessCount() != 1 || result.FailureCount() != 2 {
+		t.Errorf("Expected 1 success and 2 failures, got %d and %d", result.SuccessCount(), result.FailureCount())
+	}
+	if len(result.Messages) != 3 {
+		t.Errorf("Expected 3 per-message results, got %d", len(result.Messages))
+	}
+}
+
+func TestMessageService_SendBatch_ReleasesUnsentOnCancel(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient("http://example.com", "test-key", 5*time.Second, 0)
+	service := NewMessageService(repo, cache, webhook, 1000)
+	service.SetInstanceID("replica-1")
+
+	for i := 0; i < 3; i++ {
+		repo.AddMessage(&domain.Message{
+			ID:          uuid.New(),
+			PhoneNumber: "+1234567890",
+			Content:     "Test message",
+			Status:      domain.StatusPending,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	messages, _ := repo.ClaimMessages(ctx, "replica-1", 3)
+	cancel()
+
+	results := service.sendBatch(ctx, messages)
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	for _, result := range results {
+		if result.Outcome != domain.OutcomeReleased {
+			t.Errorf("Expected message %s to be released after cancellation, got %s", result.MessageID, result.Outcome)
+		}
+		msg, _ := repo.GetMessage(context.Background(), result.MessageID)
+		if msg.Status != domain.StatusPending || msg.RetryCount != 0 {
+			t.Errorf("Expected message %s back in pending without an attempt, got %s with %d", msg.ID, msg.Status, msg.RetryCount)
+		}
+	}
+}
+
+func TestMessageService_SendBatch_ReleasesInFlightOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The batch deadline passes while the provider is still answering
+		cancel()
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient(server.URL, "test-key", 5*time.Second, 0)
+	service := NewMessageService(repo, cache, webhook, 1000)
+	service.SetInstanceID("replica-1")
+
+	msg := &domain.Message{
+		ID:          uuid.New(),
+		PhoneNumber: "+1234567890",
+		Content:     "Test message",
+		Status:      domain.StatusPending,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	repo.AddMessage(msg)
+	messages, _ := repo.ClaimMessages(context.Background(), "replica-1", 1)
+
+	results := service.sendBatch(ctx, messages)
+
+	if len(results) != 1 || results[0].Outcome != domain.OutcomeReleased {
+		t.Fatalf("Expected the message to be released, got %+v", results)
+	}
+	updated, _ := repo.GetMessage(context.Background(), msg.ID)
+	if updated.Status != domain.StatusPending || updated.RetryCount != 0 || updated.LastError != nil {
+		t.Errorf("Expected message back in pending without an attempt, got %s with %d", updated.Status, updated.RetryCount)
+	}
+}
+
+// expiringUpdateRepository lets the batch deadline pass after the webhook has accepted a
+// message but before its status is written, failing the write as a driver would
+type expiringUpdateRepository struct {
+	*repository.MockMessageRepository
+	cancel context.CancelFunc
+}
+
+func (r *expiringUpdateRepository) UpdateMessageStatus(ctx context.Context, id uuid.UUID, status domain.MessageStatus, messageID *string) error {
+	r.cancel()
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("update message status: %w", err)
+	}
+	return r.MockMessageRepository.UpdateMessageStatus(ctx, id, status, messageID)
+}
+
+func TestMessageService_SendBatch_RecordsSendAcceptedBeforeDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"message": "Accepted", "messageId": "msg-1"}`))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := &expiringUpdateRepository{MockMessageRepository: repository.NewMockMessageRepository(), cancel: cancel}
+	cache := repository.NewMockCacheRepository()
+	cache.SetMessageCacheFunc = func(ctx context.Context, messageID string, data interface{}, ttl time.Duration) error {
+		if err := ctx.Err(); err != nil {
+			t.Errorf("Expected the cache write to outlive the batch deadline, got %v", err)
+		}
+		return nil
+	}
+	webhook := NewWebhookClient(server.URL, "test-key", 5*time.Second, 0)
+	service := NewMessageService(repo, cache, webhook, 1000)
+	service.SetInstanceID("replica-1")
+
+	msg := &domain.Message{
+		ID:          uuid.New(),
+		PhoneNumber: "+1234567890",
+		Content:     "Test message",
+		Status:      domain.StatusPending,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	repo.AddMessage(msg)
+	messages, _ := repo.ClaimMessages(context.Background(), "replica-1", 1)
+
+	results := service.sendBatch(ctx, messages)
+
+	if len(results) != 1 || results[0].Outcome != domain.OutcomeSent {
+		t.Fatalf("Expected the message to be reported sent, got %+v", results)
+	}
+	updated, _ := repo.GetMessage(context.Background(), msg.ID)
+	if updated.Status != domain.StatusSent {
+		t.Errorf("Expected message status sent, got %s", updated.Status)
+	}
+}
+
+func TestMessageService_ProcessMessages_AuditTrail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"message": "Accepted", "messageId": "msg-1"}`))
+	}))
+	defer server.Close()
+
+	auditRepo := repository.NewMockAuditRepository()
+	auditService := NewAuditService(auditRepo)
+
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient(server.URL, "test-key", 5*time.Second, 0)
+	webhook.SetAuditService(auditService)
+	service := NewMessageService(repo, cache, webhook, 1000)
+	service.SetAuditService(auditService)
+
+	msg := &domain.Message{
+		ID:          uuid.New(),
+		PhoneNumber: "+1234567890",
+		Content:     "Test message",
+		Status:      domain.StatusPending,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	repo.AddMessage(msg)
+
+	batchID := uuid.New()
+	ctx := WithBatchID(context.Background(), batchID)
+	if _, err := service.ProcessMessages(ctx, 10); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	logs, err := auditService.GetMessageAuditLogs(context.Background(), msg.ID.String())
+	if err != nil {
+		t.Fatalf("Failed to get message audit logs: %v", err)
+	}
+
+	seen := make(map[domain.AuditEventType]*domain.AuditLog)
+	for _, entry := range logs {
+		seen[entry.EventType] = entry
+		if entry.BatchID == nil || *entry.BatchID != batchID {
+			t.Errorf("Expected %s entry to carry batch ID %s, got %v", entry.EventType, batchID, entry.BatchID)
+		}
+	}
+
+	for _, eventType := range []domain.AuditEventType{domain.EventWebhookRequest, domain.EventWebhookResponse, domain.EventMessageSent} {
+		if seen[eventType] == nil {
+			t.Errorf("Expected a %s entry for message %s", eventType, msg.ID)
+		}
+	}
+
+	if response := seen[domain.EventWebhookResponse]; response != nil {
+		if response.StatusCode == nil || *response.StatusCode != http.StatusAccepted {
+			t.Errorf("Expected webhook response status %d, got %v", http.StatusAccepted, response.StatusCode)
+		}
+		if response.DurationMs == nil {
+			t.Error("Expected webhook response duration to be recorded")
+		}
+	}
+}
+
+func TestMessageService_GetMessageDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"message": "Accepted", "messageId": "msg-1"}`))
+	}))
+	defer server.Close()
+
+	auditRepo := repository.NewMockAuditRepository()
+	auditService := NewAuditService(auditRepo)
+
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient(server.URL, "test-key", 5*time.Second, 0)
+	webhook.SetAuditService(auditService)
+	service := NewMessageService(repo, cache, webhook, 1000)
+	service.SetAuditService(auditService)
+
+	ctx := context.Background()
+	msg, err := service.CreateMessage(ctx, "+1234567890", "Test message")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.ProcessMessages(ctx, 10); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	details, err := service.GetMessageDetails(ctx, msg.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if details.Message.Status != domain.StatusSent {
+		t.Errorf("Expected status sent, got %s", details.Message.Status)
+	}
+
+	expected := []domain.MessageTimelineStage{
+		domain.StageCreated,
+		domain.StageClaimed,
+		domain.StageWebhookRequest,
+		domain.StageWebhookResponse,
+		domain.StageSent,
+	}
+	if len(details.Timeline) != len(expected) {
+		t.Fatalf("Expected %d timeline events, got %d", len(expected), len(details.Timeline))
+	}
+	for i, event := range details.Timeline {
+		if event.Stage != expected[i] {
+			t.Errorf("Event %d: expected %s, got %s", i, expected[i], event.Stage)
+		}
+		if event.AuditLogID == nil {
+			t.Errorf("Event %d: expected it to come from an audit entry", i)
+		}
+	}
+	if details.Timeline[3].Attempt != 1 || details.Timeline[3].StatusCode == nil || *details.Timeline[3].StatusCode != http.StatusAccepted {
+		t.Errorf("Expected the first attempt's response with status 202, got %+v", details.Timeline[3])
+	}
+
+	if details.ProviderResponse == nil {
+		t.Error("Expected the cached provider response")
+	}
+}
+
+func TestMessageService_GetMessageDetails_NotFound(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	webhook := NewWebhookClient("http://example.com", "test-key", 30*time.Second, 3)
+	service := NewMessageService(repo, repository.NewMockCacheRepository(), webhook, 1000)
+
+	_, err := service.GetMessageDetails(context.Background(), uuid.New())
+	if !errors.Is(err, domain.ErrMessageNotFound) {
+		t.Errorf("Expected ErrMessageNotFound, got %v", err)
+	}
+}
+
+func TestMessageService_SendMessage_AuditsFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error": "unavailable"}`))
+	}))
+	defer server.Close()
+
+	auditRepo := repository.NewMockAuditRepository()
+	auditService := NewAuditService(auditRepo)
+
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient(server.URL, "test-key", 5*time.Second, 1)
+	webhook.SetAuditService(auditService)
+	service := NewMessageService(repo, cache, webhook, 1000)
+	service.SetAuditService(auditService)
+	service.SetRetryPolicy(RetryPolicy{MaxAttempts: 1, BaseDelay: time.Minute, MaxDelay: time.Hour})
+
+	msg := &domain.Message{
+		ID:          uuid.New(),
+		PhoneNumber: "+1234567890",
+		Content:     "Test message",
+		Status:      domain.StatusPending,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	repo.AddMessage(msg)
+
+	if err := service.sendMessage(context.Background(), msg); !errors.Is(err, domain.ErrMaxRetriesExceeded) {
+		t.Fatalf("Expected ErrMaxRetriesExceeded, got %v", err)
+	}
+
+	logs, err := auditService.GetMessageAuditLogs(context.Background(), msg.ID.String())
+	if err != nil {
+		t.Fatalf("Failed to get message audit logs: %v", err)
+	}
+
+	counts := make(map[domain.AuditEventType]int)
+	for _, entry := range logs {
+		counts[entry.EventType]++
+		if entry.EventType == domain.EventWebhookResponse && (entry.StatusCode == nil || *entry.StatusCode != http.StatusServiceUnavailable) {
+			t.Errorf("Expected webhook response status %d, got %v", http.StatusServiceUnavailable, entry.StatusCode)
+		}
+	}
+
+	// One request/response pair per webhook attempt, then the failed outcome
+	if counts[domain.EventWebhookRequest] != 2 || counts[domain.EventWebhookResponse] != 2 {
+		t.Errorf("Expected 2 webhook requests and responses, got %d and %d",
+			counts[domain.EventWebhookRequest], counts[domain.EventWebhookResponse])
+	}
+	if counts[domain.EventMessageFailed] != 1 {
+		t.Errorf("Expected 1 message failed entry, got %d", counts[domain.EventMessageFailed])
+	}
+	if counts[domain.EventMessageSent] != 0 {
+		t.Errorf("Expected no message sent entry, got %d", counts[domain.EventMessageSent])
+	}
+}
+
 func TestMessageService_GetSentMessages_Success(t *testing.T) {
 	repo := repository.NewMockMessageRepository()
 	cache := repository.NewMockCacheRepository()
@@ -229,6 +1032,64 @@ func TestMessageService_GetSentMessages_Pagination(t *testing.T) {
 	}
 }
 
+func TestMessageService_GetSentMessagesPage_Cursor(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient("http://example.com", "test-key", 30*time.Second, 3)
+	service := NewMessageService(repo, cache, webhook, 1000)
+
+	addSent := func(sentAt time.Time) *domain.Message {
+		msg := &domain.Message{
+			ID:          uuid.New(),
+			PhoneNumber: "+1234567890",
+			Content:     "Test message",
+			Status:      domain.StatusSent,
+			CreatedAt:   sentAt,
+			SentAt:      &sentAt,
+			UpdatedAt:   sentAt,
+		}
+		repo.AddMessage(msg)
+		return msg
+	}
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var expected []uuid.UUID
+	for i := 4; i >= 0; i-- {
+		expected = append(expected, addSent(start.Add(time.Duration(i)*time.Minute)).ID)
+	}
+
+	ctx := context.Background()
+	first, next, err := service.GetSentMessagesPage(ctx, nil, 1, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if next == nil {
+		t.Fatal("Expected a next cursor after the first page")
+	}
+
+	// A message sent between requests must not shift the pages that follow
+	addSent(start.Add(time.Hour))
+
+	seen := append([]*domain.Message{}, first...)
+	for next != nil {
+		var page []*domain.Message
+		page, next, err = service.GetSentMessagesPage(ctx, next, 1, 2)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		seen = append(seen, page...)
+	}
+
+	if len(seen) != len(expected) {
+		t.Fatalf("Expected %d messages across pages, got %d", len(expected), len(seen))
+	}
+	for i, msg := range seen {
+		if msg.ID != expected[i] {
+			t.Errorf("Message %d: expected %s, got %s", i, expected[i], msg.ID)
+		}
+	}
+}
+
 func TestMessageService_SendMessage_TooLong(t *testing.T) {
 	repo := repository.NewMockMessageRepository()
 	cache := repository.NewMockCacheRepository()
@@ -265,9 +1126,15 @@ func TestMessageService_SendMessage_TooLong(t *testing.T) {
 }
 
 func TestMessageService_SendMessage_UpdateStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"message": "Accepted", "messageId": "msg-1"}`))
+	}))
+	defer server.Close()
+
 	repo := repository.NewMockMessageRepository()
 	cache := repository.NewMockCacheRepository()
-	webhook := NewWebhookClient("http://example.com", "test-key", 30*time.Second, 3)
+	webhook := NewWebhookClient(server.URL, "test-key", 5*time.Second, 0)
 	service := NewMessageService(repo, cache, webhook, 1000)
 
 	// Configure repository to return error on status update
@@ -297,6 +1164,147 @@ func TestMessageService_SendMessage_UpdateStatusError(t *testing.T) {
 	}
 }
 
+func TestMessageService_SendMessage_WebhookFailureRequeues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient(server.URL, "test-key", 5*time.Second, 0)
+	auditService := NewAuditService(repository.NewMockAuditRepository())
+	service := NewMessageService(repo, cache, webhook, 1000)
+	service.SetAuditService(auditService)
+	service.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour})
+
+	msg := &domain.Message{
+		ID:          uuid.New(),
+		PhoneNumber: "+1234567890",
+		Content:     "Test message",
+		Status:      domain.StatusPending,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	repo.AddMessage(msg)
+
+	ctx := context.Background()
+	err := service.sendMessage(ctx, msg)
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+	if errors.Is(err, domain.ErrMaxRetriesExceeded) {
+		t.Error("Expected first failure not to exceed max retries")
+	}
+
+	updatedMsg, _ := repo.GetMessage(ctx, msg.ID)
+	if updatedMsg.Status != domain.StatusPending {
+		t.Errorf("Expected status %s, got %s", domain.StatusPending, updatedMsg.Status)
+	}
+	if updatedMsg.RetryCount != 1 {
+		t.Errorf("Expected retry count 1, got %d", updatedMsg.RetryCount)
+	}
+	if updatedMsg.NextAttemptAt == nil || !updatedMsg.NextAttemptAt.After(time.Now()) {
+		t.Errorf("Expected next attempt in the future, got %v", updatedMsg.NextAttemptAt)
+	}
+	if updatedMsg.LastError == nil {
+		t.Error("Expected last error to be recorded")
+	}
+
+	logs, _ := auditService.GetMessageAuditLogs(ctx, msg.ID.String())
+	for _, entry := range logs {
+		if entry.EventType == domain.EventMessageFailed {
+			t.Error("Expected no message failed entry while attempts remain")
+		}
+	}
+
+	// The message must not be picked up again before its backoff elapses
+	claimed, _ := repo.ClaimMessages(ctx, "test", 10)
+	if len(claimed) != 0 {
+		t.Errorf("Expected no messages ready for sending, got %d", len(claimed))
+	}
+}
+
+func TestMessageService_SendMessage_MaxRetriesExceeded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient(server.URL, "test-key", 5*time.Second, 0)
+	service := NewMessageService(repo, cache, webhook, 1000)
+	service.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour})
+
+	msg := &domain.Message{
+		ID:          uuid.New(),
+		PhoneNumber: "+1234567890",
+		Content:     "Test message",
+		Status:      domain.StatusPending,
+		RetryCount:  2,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	repo.AddMessage(msg)
+
+	ctx := context.Background()
+	err := service.sendMessage(ctx, msg)
+	if !errors.Is(err, domain.ErrMaxRetriesExceeded) {
+		t.Fatalf("Expected ErrMaxRetriesExceeded, got %v", err)
+	}
+
+	updatedMsg, _ := repo.GetMessage(ctx, msg.ID)
+	if updatedMsg.Status != domain.StatusFailed {
+		t.Errorf("Expected status %s, got %s", domain.StatusFailed, updatedMsg.Status)
+	}
+	if updatedMsg.RetryCount != 3 {
+		t.Errorf("Expected retry count 3, got %d", updatedMsg.RetryCount)
+	}
+	if updatedMsg.NextAttemptAt != nil {
+		t.Errorf("Expected no next attempt, got %v", updatedMsg.NextAttemptAt)
+	}
+}
+
+func TestMessageService_RecoverStuckMessages(t *testing.T) {
+	repo := repository.NewMockMessageRepository()
+	cache := repository.NewMockCacheRepository()
+	webhook := NewWebhookClient("http://example.com", "test-key", 30*time.Second, 3)
+	service := NewMessageService(repo, cache, webhook, 1000)
+
+	stuck := &domain.Message{
+		ID:        uuid.New(),
+		Status:    domain.StatusSending,
+		CreatedAt: time.Now().Add(-time.Hour),
+		UpdatedAt: time.Now().Add(-10 * time.Minute),
+	}
+	inFlight := &domain.Message{
+		ID:        uuid.New(),
+		Status:    domain.StatusSending,
+		CreatedAt: time.Now().Add(-time.Hour),
+		UpdatedAt: time.Now(),
+	}
+	repo.AddMessage(stuck)
+	repo.AddMessage(inFlight)
+
+	recovered, err := service.RecoverStuckMessages(context.Background(), 5*time.Minute)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(recovered) != 1 || recovered[0].ID != stuck.ID {
+		t.Fatalf("Expected only the stuck message to be recovered, got %v", recovered)
+	}
+
+	if stuck.Status != domain.StatusPending {
+		t.Errorf("Expected status %s, got %s", domain.StatusPending, stuck.Status)
+	}
+
+	if inFlight.Status != domain.StatusSending {
+		t.Errorf("Expected in-flight message to stay %s, got %s", domain.StatusSending, inFlight.Status)
+	}
+}
+
 // WebhookSender interface for dependency injection
 type WebhookSender interface {
 	Send(ctx context.Context, phoneNumber, content string) (*domain.WebhookResponse, error)
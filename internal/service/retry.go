@@ -0,0 +1,48 @@
+package service
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy controls how messages whose webhook send failed are re-queued
+type RetryPolicy struct {
+	// MaxAttempts is the total number of send attempts before a message is marked failed
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry; it doubles with every further attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   30 * time.Second,
+		MaxDelay:    30 * time.Minute,
+	}
+}
+
+// Backoff returns the delay before the next attempt after the given number of failed
+// attempts. The delay grows exponentially and is jittered between half and the full
+// value so that messages failing together do not retry in lockstep.
+func (p RetryPolicy) Backoff(failedAttempts int) time.Duration {
+	if failedAttempts < 1 {
+		failedAttempts = 1
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < failedAttempts && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
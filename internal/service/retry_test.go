@@ -0,0 +1,53 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	policy := DefaultRetryPolicy()
+
+	if policy.MaxAttempts != 5 {
+		t.Errorf("Expected 5 max attempts, got %d", policy.MaxAttempts)
+	}
+
+	if policy.BaseDelay != 30*time.Second {
+		t.Errorf("Expected base delay 30s, got %v", policy.BaseDelay)
+	}
+
+	if policy.MaxDelay != 30*time.Minute {
+		t.Errorf("Expected max delay 30m, got %v", policy.MaxDelay)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts: 10,
+		BaseDelay:   time.Second,
+		MaxDelay:    10 * time.Second,
+	}
+
+	tests := []struct {
+		name           string
+		failedAttempts int
+		expectedMax    time.Duration
+	}{
+		{"Zero attempts uses base delay", 0, time.Second},
+		{"First retry", 1, time.Second},
+		{"Second retry doubles", 2, 2 * time.Second},
+		{"Third retry doubles again", 3, 4 * time.Second},
+		{"Capped at max delay", 8, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				delay := policy.Backoff(tt.failedAttempts)
+				if delay < tt.expectedMax/2 || delay > tt.expectedMax {
+					t.Fatalf("Expected delay between %v and %v, got %v", tt.expectedMax/2, tt.expectedMax, delay)
+				}
+			}
+		})
+	}
+}
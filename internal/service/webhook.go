@@ -7,18 +7,26 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"time"
 
 	"ims/internal/domain"
+
+	"github.com/google/uuid"
 )
 
+// maxAuditedResponseBody caps how much of a webhook response is kept in the audit log
+const maxAuditedResponseBody = 64 << 10
+
 type WebhookClient struct {
 	client     *http.Client
 	url        string
 	authKey    string
 	maxRetries int
+
+	auditService AuditService
 }
 
 func NewWebhookClient(url, authKey string, timeout time.Duration, maxRetries int) *WebhookClient {
@@ -32,7 +40,27 @@ func NewWebhookClient(url, authKey string, timeout time.Duration, maxRetries int
 	}
 }
 
+// SetAuditService records every request and response made by SendForMessage
+func (w *WebhookClient) SetAuditService(auditService AuditService) {
+	w.auditService = auditService
+}
+
+// URL returns the webhook endpoint messages are sent to
+func (w *WebhookClient) URL() string {
+	return w.url
+}
+
 func (w *WebhookClient) Send(ctx context.Context, phoneNumber, content string) (*domain.WebhookResponse, error) {
+	return w.send(ctx, nil, phoneNumber, content)
+}
+
+// SendForMessage sends like Send and records each attempt's request and response in the
+// audit log against messageID
+func (w *WebhookClient) SendForMessage(ctx context.Context, messageID uuid.UUID, phoneNumber, content string) (*domain.WebhookResponse, error) {
+	return w.send(ctx, &messageID, phoneNumber, content)
+}
+
+func (w *WebhookClient) send(ctx context.Context, messageID *uuid.UUID, phoneNumber, content string) (*domain.WebhookResponse, error) {
 	req := domain.WebhookRequest{
 		To:      phoneNumber,
 		Content: content,
@@ -52,7 +80,12 @@ func (w *WebhookClient) Send(ctx context.Context, phoneNumber, content string) (
 			}
 		}
 
-		err := w.doRequest(ctx, req, &resp)
+		var err error
+		if messageID != nil && w.auditService != nil {
+			err = w.auditedRequest(ctx, *messageID, req, &resp)
+		} else {
+			err = w.doRequest(ctx, req, &resp)
+		}
 		if err == nil {
 			return &resp, nil
 		}
@@ -62,15 +95,50 @@ func (w *WebhookClient) Send(ctx context.Context, phoneNumber, content string) (
 	return nil, fmt.Errorf("failed after %d attempts: %w", w.maxRetries+1, lastErr)
 }
 
+// auditedRequest performs one attempt and records its request and response. Audit entries
+// are written even if ctx has expired so that timed-out attempts still show up.
+func (w *WebhookClient) auditedRequest(ctx context.Context, messageID uuid.UUID, req domain.WebhookRequest, resp *domain.WebhookResponse) error {
+	auditCtx := context.WithoutCancel(ctx)
+
+	if err := w.auditService.LogWebhookRequest(auditCtx, messageID, w.url, http.MethodPost, req); err != nil {
+		log.Printf("Failed to log webhook request event: %v", err)
+	}
+
+	start := time.Now()
+	statusCode, body, err := w.exchange(ctx, req, resp)
+	duration := time.Since(start)
+
+	var responseBody interface{} = string(body)
+	if json.Valid(body) {
+		responseBody = json.RawMessage(body)
+	}
+	if err != nil && statusCode == 0 {
+		responseBody = map[string]string{"error": err.Error()}
+	}
+
+	if logErr := w.auditService.LogWebhookResponse(auditCtx, messageID, w.url, statusCode, duration, responseBody); logErr != nil {
+		log.Printf("Failed to log webhook response event: %v", logErr)
+	}
+
+	return err
+}
+
 func (w *WebhookClient) doRequest(ctx context.Context, req domain.WebhookRequest, resp *domain.WebhookResponse) error {
+	_, _, err := w.exchange(ctx, req, resp)
+	return err
+}
+
+// exchange performs one request and returns the response status code and body (status 0
+// if no response was received) along with any error
+func (w *WebhookClient) exchange(ctx context.Context, req domain.WebhookRequest, resp *domain.WebhookResponse) (int, []byte, error) {
 	jsonData, err := json.Marshal(req)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return 0, nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", w.url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return 0, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
@@ -78,16 +146,21 @@ func (w *WebhookClient) doRequest(ctx context.Context, req domain.WebhookRequest
 
 	httpResp, err := w.client.Do(httpReq)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return 0, nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer httpResp.Body.Close()
 
+	body, err := io.ReadAll(io.LimitReader(httpResp.Body, maxAuditedResponseBody))
+	if err != nil {
+		return httpResp.StatusCode, body, fmt.Errorf("failed to read response: %w", err)
+	}
+
 	if httpResp.StatusCode != http.StatusOK && httpResp.StatusCode != http.StatusAccepted {
-		return fmt.Errorf("unexpected status code: %d", httpResp.StatusCode)
+		return httpResp.StatusCode, body, fmt.Errorf("unexpected status code: %d", httpResp.StatusCode)
 	}
 
 	// Try to decode JSON response, but handle cases where the webhook doesn't return JSON
-	if err := json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+	if err := json.Unmarshal(body, resp); err != nil {
 		// If JSON decoding fails, create a mock response for webhook.site
 		// Generate a unique message ID for tracking
 		resp.Message = "Accepted"
@@ -97,5 +170,5 @@ func (w *WebhookClient) doRequest(ctx context.Context, req domain.WebhookRequest
 		log.Printf("Webhook returned non-JSON response, using mock response: %s", resp.MessageID)
 	}
 
-	return nil
+	return httpResp.StatusCode, body, nil
 }
@@ -0,0 +1,9 @@
+-- migrations/003_add_message_idempotency.sql
+-- Idempotency keys let producers retry message creation without creating duplicates
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255);
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS idempotency_hash VARCHAR(64);
+
+-- Only one message may hold a given key; keys are released once their window expires
+CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_idempotency_key
+    ON messages(idempotency_key) WHERE idempotency_key IS NOT NULL;
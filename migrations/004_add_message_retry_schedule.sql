@@ -0,0 +1,8 @@
+-- migrations/004_add_message_retry_schedule.sql
+-- Failed sends are re-queued as pending with a backoff delay until the attempt limit is reached
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS last_error TEXT;
+
+-- Supports picking pending messages whose backoff has elapsed
+CREATE INDEX IF NOT EXISTS idx_messages_status_next_attempt ON messages(status, next_attempt_at);
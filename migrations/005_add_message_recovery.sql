@@ -0,0 +1,7 @@
+-- migrations/005_add_message_recovery.sql
+-- Messages left in 'sending' past their lease are returned to 'pending' by the reaper
+
+ALTER TYPE audit_event_type ADD VALUE IF NOT EXISTS 'message_recovered';
+
+-- Supports finding messages that have been in a status for too long
+CREATE INDEX IF NOT EXISTS idx_messages_status_updated ON messages(status, updated_at);
@@ -0,0 +1,5 @@
+-- migrations/006_add_message_claims.sql
+-- Pending messages are claimed atomically by one instance before they are sent
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS claimed_by VARCHAR(255);
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,9 @@
+-- migrations/008_create_scheduler_state.sql
+-- Desired scheduler run state, restored on boot and converged on by every replica
+
+CREATE TABLE IF NOT EXISTS scheduler_state (
+    id SMALLINT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    desired_running BOOLEAN NOT NULL,
+    changed_by VARCHAR(255) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
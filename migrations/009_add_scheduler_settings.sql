@@ -0,0 +1,7 @@
+-- migrations/009_add_scheduler_settings.sql
+-- Interval and batch size changed at runtime through the control API; NULL keeps the configured default
+
+ALTER TABLE scheduler_state ADD COLUMN IF NOT EXISTS interval_ms BIGINT;
+ALTER TABLE scheduler_state ADD COLUMN IF NOT EXISTS batch_size INTEGER;
+
+ALTER TYPE audit_event_type ADD VALUE IF NOT EXISTS 'scheduler_reconfigured';
@@ -0,0 +1,32 @@
+-- migrations/010_add_audit_hash_chain.sql
+-- Tamper-evident hash chain over audit_logs; entries written with AUDIT_HASH_CHAIN enabled are
+-- linked in chain_seq order, and retention cleanup records a signed checkpoint for what it deletes
+
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS chain_seq BIGINT;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS hash VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_logs_chain_seq ON audit_logs(chain_seq) WHERE chain_seq IS NOT NULL;
+
+-- Last chained entry; writers lock this row so entries are linked one at a time
+CREATE TABLE IF NOT EXISTS audit_chain_head (
+    id SMALLINT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    chain_seq BIGINT NOT NULL,
+    hash VARCHAR(64) NOT NULL
+);
+
+INSERT INTO audit_chain_head (id, chain_seq, hash)
+VALUES (1, 0, repeat('0', 64))
+ON CONFLICT (id) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS audit_chain_checkpoints (
+    id BIGSERIAL PRIMARY KEY,
+    chain_seq BIGINT NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    pruned_before TIMESTAMP WITH TIME ZONE NOT NULL,
+    deleted_count BIGINT NOT NULL,
+    signature VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_chain_checkpoints_chain_seq ON audit_chain_checkpoints(chain_seq);
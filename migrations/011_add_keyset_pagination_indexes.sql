@@ -0,0 +1,6 @@
+-- migrations/011_add_keyset_pagination_indexes.sql
+-- Keyset pagination walks lists newest first by (timestamp, id)
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at_id ON audit_logs(created_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_messages_sent_at_id ON messages(sent_at, id) WHERE status = 'sent';
@@ -0,0 +1,8 @@
+-- migrations/012_add_audit_search_indexes.sql
+-- Audit log filters on metadata (JSONB containment) and on words in event_name/description
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_metadata ON audit_logs USING GIN (metadata jsonb_path_ops);
+
+-- The expression must match auditSearchVector in the repository for the planner to use it
+CREATE INDEX IF NOT EXISTS idx_audit_logs_search ON audit_logs
+    USING GIN (to_tsvector('simple', event_name || ' ' || coalesce(description, '')));
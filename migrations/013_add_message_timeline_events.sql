@@ -0,0 +1,5 @@
+-- migrations/013_add_message_timeline_events.sql
+-- Enqueueing and claiming a message are audited so its timeline covers the whole lifecycle
+
+ALTER TYPE audit_event_type ADD VALUE IF NOT EXISTS 'message_created';
+ALTER TYPE audit_event_type ADD VALUE IF NOT EXISTS 'message_claimed';
@@ -0,0 +1,4 @@
+-- migrations/014_add_audit_retention.sql
+-- Runs of the retention pruner are audited with how many entries they deleted
+
+ALTER TYPE audit_event_type ADD VALUE IF NOT EXISTS 'audit_pruned';
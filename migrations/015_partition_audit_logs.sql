@@ -0,0 +1,101 @@
+-- migrations/015_partition_audit_logs.sql
+-- Range-partitions audit_logs by UTC month so retention can drop whole partitions instead of
+-- deleting rows. Existing rows are copied into monthly partitions in a single transaction, so
+-- the table is either fully migrated or left as it was; writers wait on the lock meanwhile.
+-- Partitions for later months are created ahead of time by the partition manager.
+
+\set ON_ERROR_STOP on
+
+BEGIN;
+
+LOCK TABLE audit_logs IN ACCESS EXCLUSIVE MODE;
+
+CREATE TABLE audit_logs_partitioned (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    event_type audit_event_type NOT NULL,
+    event_name VARCHAR(100) NOT NULL,
+    description TEXT,
+
+    -- Context information
+    batch_id UUID,
+    message_id UUID,
+    request_id VARCHAR(100),
+
+    -- Request/Response details
+    http_method VARCHAR(10),
+    endpoint VARCHAR(255),
+    status_code INTEGER,
+
+    -- Metrics
+    duration_ms INTEGER,
+    message_count INTEGER,
+    success_count INTEGER,
+    failure_count INTEGER,
+
+    -- Additional data (JSON)
+    metadata JSONB,
+
+    -- Timing; the partition key, so it can no longer be null
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Hash chain
+    chain_seq BIGINT,
+    prev_hash VARCHAR(64),
+    hash VARCHAR(64)
+) PARTITION BY RANGE (created_at);
+
+-- One partition per month from the oldest entry through three months ahead, named
+-- audit_logs_pYYYY_MM as the partition manager expects
+DO $$
+DECLARE
+    month_start TIMESTAMP := date_trunc('month', COALESCE(
+        (SELECT MIN(created_at) FROM audit_logs), CURRENT_TIMESTAMP) AT TIME ZONE 'UTC');
+    last_month TIMESTAMP := date_trunc('month', CURRENT_TIMESTAMP AT TIME ZONE 'UTC') + INTERVAL '3 months';
+BEGIN
+    WHILE month_start <= last_month LOOP
+        EXECUTE format(
+            'CREATE TABLE %I PARTITION OF audit_logs_partitioned FOR VALUES FROM (%L) TO (%L)',
+            'audit_logs_p' || to_char(month_start, 'YYYY_MM'),
+            month_start AT TIME ZONE 'UTC',
+            (month_start + INTERVAL '1 month') AT TIME ZONE 'UTC');
+        month_start := month_start + INTERVAL '1 month';
+    END LOOP;
+END $$;
+
+-- Catches entries outside every monthly partition, e.g. if the partition manager fell behind
+CREATE TABLE audit_logs_default PARTITION OF audit_logs_partitioned DEFAULT;
+
+INSERT INTO audit_logs_partitioned (
+    id, event_type, event_name, description, batch_id, message_id, request_id,
+    http_method, endpoint, status_code, duration_ms, message_count,
+    success_count, failure_count, metadata, created_at, chain_seq, prev_hash, hash
+)
+SELECT
+    id, event_type, event_name, description, batch_id, message_id, request_id,
+    http_method, endpoint, status_code, duration_ms, message_count,
+    success_count, failure_count, metadata, COALESCE(created_at, CURRENT_TIMESTAMP), chain_seq, prev_hash, hash
+FROM audit_logs;
+
+DROP TABLE audit_logs;
+ALTER TABLE audit_logs_partitioned RENAME TO audit_logs;
+
+-- Unique constraints on a partitioned table must include the partition key. chain_seq stays
+-- unique in practice because it is only assigned under the audit_chain_head lock.
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_pkey PRIMARY KEY (id, created_at);
+CREATE UNIQUE INDEX idx_audit_logs_chain_seq ON audit_logs(chain_seq, created_at) WHERE chain_seq IS NOT NULL;
+
+CREATE INDEX idx_audit_logs_event_type ON audit_logs(event_type);
+CREATE INDEX idx_audit_logs_created_at ON audit_logs(created_at);
+CREATE INDEX idx_audit_logs_batch_id ON audit_logs(batch_id);
+CREATE INDEX idx_audit_logs_message_id ON audit_logs(message_id);
+CREATE INDEX idx_audit_logs_request_id ON audit_logs(request_id);
+CREATE INDEX idx_audit_logs_endpoint ON audit_logs(endpoint);
+CREATE INDEX idx_audit_logs_type_created ON audit_logs(event_type, created_at);
+CREATE INDEX idx_audit_logs_batch_type ON audit_logs(batch_id, event_type) WHERE batch_id IS NOT NULL;
+CREATE INDEX idx_audit_logs_created_at_id ON audit_logs(created_at, id);
+CREATE INDEX idx_audit_logs_metadata ON audit_logs USING GIN (metadata jsonb_path_ops);
+-- The expression must match auditSearchVector in the repository for the planner to use it
+CREATE INDEX idx_audit_logs_search ON audit_logs
+    USING GIN (to_tsvector('simple', event_name || ' ' || coalesce(description, '')));
+
+COMMIT;
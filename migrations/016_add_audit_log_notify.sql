@@ -0,0 +1,16 @@
+-- migrations/016_add_audit_log_notify.sql
+-- Notifies the audit_logs channel with the ID of every stored audit log, so each replica can
+-- push entries written by any replica to its live audit stream subscribers
+
+CREATE OR REPLACE FUNCTION notify_audit_log() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('audit_logs', NEW.id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_logs_notify ON audit_logs;
+
+CREATE TRIGGER audit_logs_notify
+    AFTER INSERT ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION notify_audit_log();
@@ -0,0 +1,7 @@
+-- migrations/017_sign_audit_chain_head.sql
+-- Signs the audit chain head so deleting the newest chained entries is detected, and records
+-- the first chain sequence whose link hash is keyed. Both are set by the next chained write;
+-- entries chained before it keep their plain SHA-256 link hashes.
+
+ALTER TABLE audit_chain_head ADD COLUMN IF NOT EXISTS keyed_from BIGINT;
+ALTER TABLE audit_chain_head ADD COLUMN IF NOT EXISTS signature VARCHAR(64);
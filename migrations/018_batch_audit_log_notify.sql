@@ -0,0 +1,37 @@
+-- migrations/018_batch_audit_log_notify.sql
+-- Notifies the audit_logs channel once per insert statement rather than once per row. The
+-- payload lists the stored entries as id:created_at, with created_at in microseconds since
+-- the Unix epoch, so replicas can read each entry from its own partition. Payloads are split
+-- well below the 8000 byte NOTIFY limit.
+
+DROP TRIGGER IF EXISTS audit_logs_notify ON audit_logs;
+DROP FUNCTION IF EXISTS notify_audit_log();
+
+CREATE OR REPLACE FUNCTION notify_audit_logs() RETURNS trigger AS $$
+DECLARE
+    entry RECORD;
+    payload TEXT := '';
+BEGIN
+    FOR entry IN SELECT id, created_at FROM new_audit_logs ORDER BY created_at, id LOOP
+        IF length(payload) > 7000 THEN
+            PERFORM pg_notify('audit_logs', payload);
+            payload := '';
+        END IF;
+        IF payload <> '' THEN
+            payload := payload || ',';
+        END IF;
+        payload := payload || entry.id::text || ':' ||
+            (extract(epoch FROM entry.created_at) * 1000000)::bigint::text;
+    END LOOP;
+
+    IF payload <> '' THEN
+        PERFORM pg_notify('audit_logs', payload);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_logs_notify
+    AFTER INSERT ON audit_logs
+    REFERENCING NEW TABLE AS new_audit_logs
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_audit_logs();
@@ -0,0 +1,12 @@
+-- migrations/019_create_batch_results.sql
+-- Latest batch results with their per-message outcomes, so any replica can report the
+-- batches run by the leader and the history survives restarts and leadership changes
+
+CREATE TABLE IF NOT EXISTS batch_results (
+    batch_id UUID PRIMARY KEY,
+    instance_id VARCHAR(255) NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    result JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_batch_results_started_at ON batch_results (started_at DESC);
@@ -86,6 +86,23 @@ print_success "Migration history table ready"
 migrations=(
     "001_create_messages.sql"
     "002_create_audit_logs.sql"
+    "003_add_message_idempotency.sql"
+    "004_add_message_retry_schedule.sql"
+    "005_add_message_recovery.sql"
+    "006_add_message_claims.sql"
+    "007_create_scheduler_leases.sql"
+    "008_create_scheduler_state.sql"
+    "009_add_scheduler_settings.sql"
+    "010_add_audit_hash_chain.sql"
+    "011_add_keyset_pagination_indexes.sql"
+    "012_add_audit_search_indexes.sql"
+    "013_add_message_timeline_events.sql"
+    "014_add_audit_retention.sql"
+    "015_partition_audit_logs.sql"
+    "016_add_audit_log_notify.sql"
+    "017_sign_audit_chain_head.sql"
+    "018_batch_audit_log_notify.sql"
+    "019_create_batch_results.sql"
 )
 
 for migration in "${migrations[@]}"; do